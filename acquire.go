@@ -0,0 +1,62 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPoolTimeout is returned when the WithAcquireTimeout deadline ends the
+// wait for a pooled connection, telling a saturated pool apart from a slow query
+var ErrPoolTimeout = errors.New("csql: timed out acquiring a connection")
+
+// WithAcquireTimeout bounds the wait for a free pooled connection by d,
+// separately from the operation's own deadline, failing with ErrPoolTimeout
+// once it passes. Each query, Exec, and database transaction takes its
+// connection from the pool first, and returns it once its rows are read or
+// it ends. Managers from WithConn and Bind already hold their connection
+func WithAcquireTimeout(d time.Duration) Option {
+	return func(o *options) error {
+		if d <= 0 {
+			return fmt.Errorf("csql: acquire timeout must be positive, got %v", d)
+		}
+		o.acquireTimeout = d
+		return nil
+	}
+}
+
+// acquire takes a connection from the pool within the WithAcquireTimeout,
+// or returns nil when statements take theirs as they run
+func (m *SQLTableManager[_, _]) acquire(ctx context.Context) (*sql.Conn, error) {
+	if m.opts.acquireTimeout <= 0 || m.pinned {
+		return nil, nil
+	}
+	wait, cancel := context.WithTimeout(ctx, m.opts.acquireTimeout)
+	defer cancel()
+	c, err := m.pool.Conn(wait)
+	if err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return nil, ErrPoolTimeout
+	}
+	return c, err
+}
+
+// connTx is a database transaction on an acquired connection, returned to
+// the pool once the transaction ends
+type connTx struct {
+	*sql.Tx
+	conn *sql.Conn
+}
+
+func (t *connTx) Commit() error {
+	err := t.Tx.Commit()
+	t.conn.Close()
+	return err
+}
+
+func (t *connTx) Rollback() error {
+	err := t.Tx.Rollback()
+	t.conn.Close()
+	return err
+}
@@ -0,0 +1,36 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+func TestAcquireTimeout(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	seedItems(t, db, 1)
+	m := csql.NewSQLTableManager[Item](db, csql.WithAcquireTimeout(50*time.Millisecond))
+	held, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Query(selectItems); !errors.Is(err, csql.ErrPoolTimeout) {
+		t.Fatalf("Query on a saturated pool = %v, want ErrPoolTimeout", err)
+	}
+	if err := m.Exec("DELETE FROM items"); !errors.Is(err, csql.ErrPoolTimeout) {
+		t.Fatalf("Exec on a saturated pool = %v, want ErrPoolTimeout", err)
+	}
+	canceled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.QueryContext(canceled, selectItems); errors.Is(err, csql.ErrPoolTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Query past its own deadline = %v, want the deadline, not ErrPoolTimeout", err)
+	}
+	held.Close()
+	if rows, err := m.Query(selectItems); err != nil || len(rows) != 1 {
+		t.Fatalf("Query once the connection is free = %v, %v", rows, err)
+	}
+}
@@ -0,0 +1,44 @@
+package csql
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// errorQueryLimit bounds the query text WithErrorIncludeQuery adds to errors
+const errorQueryLimit = 256
+
+// WithErrorIncludeQuery adds the failing query, truncated, to returned
+// errors, and the failing row's arguments to a RowError, redacted as
+// WithRedactor shows them. It is off by default since queries may embed
+// sensitive literals
+func WithErrorIncludeQuery(include bool) Option {
+	return func(o *options) error {
+		o.errorQuery = include
+		return nil
+	}
+}
+
+// annotate wraps a failed err with the method that failed and, under
+// WithErrorIncludeQuery, its query. The cause stays reachable by errors.Is
+func (o *options) annotate(err *error, method, query string) {
+	if *err == nil {
+		return
+	}
+	if o.errorQuery {
+		*err = fmt.Errorf("csql: %s failed (query %q): %w", method, truncate(query, errorQueryLimit), *err)
+		return
+	}
+	*err = fmt.Errorf("csql: %s failed: %w", method, *err)
+}
+
+// truncate cuts s to at most n bytes, without splitting a rune, and marks the cut
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
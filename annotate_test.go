@@ -0,0 +1,51 @@
+package csql_test
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/vtereso/csql"
+	"modernc.org/sqlite"
+)
+
+func TestErrorAnnotation(t *testing.T) {
+	db := openDB(t)
+	m := csql.NewSQLTableManager[Item](db)
+	_, err := m.QueryRow("SELECT id, name FROM items WHERE id = ?", 1)
+	if want := "csql: QueryRow failed: " + sql.ErrNoRows.Error(); err == nil || err.Error() != want {
+		t.Fatalf("QueryRow = %v, want %q", err, want)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("QueryRow = %v, want it to match sql.ErrNoRows", err)
+	}
+	err = m.Exec("INSERT INTO missing VALUES (1)")
+	var driverErr *sqlite.Error
+	if !errors.As(err, &driverErr) || !strings.HasPrefix(err.Error(), "csql: Exec failed: ") {
+		t.Fatalf("Exec = %v, want the annotated driver error", err)
+	}
+	if strings.Contains(err.Error(), "missing VALUES") {
+		t.Fatalf("Exec = %v, want the query left out by default", err)
+	}
+}
+
+func TestErrorIncludeQuery(t *testing.T) {
+	db := openDB(t)
+	m := csql.NewSQLTableManager[Item](db, csql.WithErrorIncludeQuery(true))
+	err := m.Exec("INSERT INTO missing VALUES (1)")
+	if want := `csql: Exec failed (query "INSERT INTO missing VALUES (1)"): `; err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Fatalf("Exec = %v, want it to start %q", err, want)
+	}
+	// the cut at 256 bytes falls within the first é
+	prefix := "SELECT id, name FROM missing -- "
+	long := prefix + strings.Repeat("x", 255-len(prefix)) + "éé"
+	_, err = m.Query(long)
+	if want := strings.TrimSuffix(long, "éé") + "...\")"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("Query = %v, want the query truncated before the rune", err)
+	}
+	if !utf8.ValidString(err.Error()) {
+		t.Fatalf("Query = %q, want valid UTF-8", err)
+	}
+}
@@ -0,0 +1,94 @@
+package csql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// WithArgConverter converts the arguments of Exec, Query, QueryRow, and
+// their variants, and the Fields of the rows of Transaction and its
+// variants, before they reach the driver, for domain types the driver does
+// not take. convert is given each argument not implementing driver.Valuer
+// and returns its replacement and true, or false to leave it. Arguments it
+// leaves whose type has a string, integer, float, or bool underlying type,
+// such as type UserID string, are converted to that basic type. Values
+// implementing driver.Valuer are passed as they are
+func WithArgConverter(convert func(any) (any, bool)) Option {
+	return func(o *options) error {
+		if convert == nil {
+			return fmt.Errorf("csql: arg converter must not be nil")
+		}
+		o.argConverter = convert
+		return nil
+	}
+}
+
+// convertArgs returns args converted per WithTypeCodec and
+// WithArgConverter. args is returned as it is when neither is configured
+func (o *options) convertArgs(args []any) []any {
+	if o.argConverter == nil && o.codecs == nil || len(args) == 0 {
+		return args
+	}
+	converted := make([]any, len(args))
+	for i, arg := range args {
+		if named, ok := arg.(sql.NamedArg); ok {
+			named.Value = o.convertArg(named.Value)
+			converted[i] = named
+			continue
+		}
+		converted[i] = o.convertArg(arg)
+	}
+	return converted
+}
+
+// convertArg converts a single argument per WithTypeCodec and WithArgConverter
+func (o *options) convertArg(arg any) any {
+	if arg == nil {
+		return arg
+	}
+	if c, ok := o.codecs[reflect.TypeOf(arg)]; ok {
+		return codecValue{arg, c.value}
+	}
+	if _, ok := arg.(driver.Valuer); ok || o.argConverter == nil {
+		return arg
+	}
+	if v, ok := o.argConverter(arg); ok {
+		return v
+	}
+	rv := reflect.ValueOf(arg)
+	switch k := rv.Kind(); {
+	case k == reflect.String:
+		return rv.String()
+	case k == reflect.Bool:
+		return rv.Bool()
+	case k >= reflect.Int && k <= reflect.Int64:
+		return rv.Int()
+	case k >= reflect.Uint && k <= reflect.Uint64:
+		if n := rv.Uint(); n < 1<<63 {
+			return int64(n)
+		}
+	case k == reflect.Float32 || k == reflect.Float64:
+		return rv.Float()
+	}
+	return arg
+}
+
+// convertingArgs returns the argsFn of a Transaction, binding Fields when
+// argsFn is nil, with the arguments converted per WithTypeCodec and
+// WithArgConverter and Fields enciphered per WithColumnCipher
+func convertingArgs[T any, R Schema[T]](o *options, argsFn func(*T) []any) func(*T) []any {
+	if argsFn == nil && o.cipher != nil {
+		columns := columnsOf[T, R]()
+		return func(row *T) []any {
+			return o.sealFields(columns, R(row).Fields())
+		}
+	}
+	if o.argConverter == nil && o.codecs == nil {
+		return argsFn
+	}
+	return func(row *T) []any {
+		return o.convertArgs(bindArgs[T, R](argsFn, row))
+	}
+}
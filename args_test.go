@@ -0,0 +1,116 @@
+package csql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestNamedArgs(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	// Postgres would rebind ? placeholders, which named args must not see
+	m := csql.NewSQLTableManager[Item](db, csql.WithDialect(csql.Postgres))
+	ctx := context.Background()
+	const insert = "INSERT INTO items (id, name) VALUES (@id, @name) -- ?"
+	if err := m.ExecNamedArgs(ctx, insert, sql.Named("id", 1), sql.Named("name", "item1")); err != nil {
+		t.Fatal(err)
+	}
+	const query = "SELECT id, name FROM items WHERE id = :id -- ?"
+	row, err := m.QueryRowContext(ctx, query, sql.Named("id", 1))
+	if err != nil || row != (Item{1, "item1"}) {
+		t.Fatalf("QueryRow = %v, %v", row, err)
+	}
+	got := statementsAfterSetup(rec)
+	want := []Statement{
+		{SQL: insert, Named: []driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(1)}, {Name: "name", Ordinal: 2, Value: "item1"}}},
+		{SQL: query, Named: []driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(1)}}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("statements %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].SQL != want[i].SQL || !slices.Equal(got[i].Named, want[i].Named) {
+			t.Fatalf("statement %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+// UserID is a named string type the driver does not know
+type UserID string
+
+// point is a struct stored as its "x,y" text
+type point struct{ X, Y int }
+
+// Owned is a Schema whose Fields hold domain types
+type Owned struct {
+	Owner UserID
+	At    point
+}
+
+func (o *Owned) ScanRow(s csql.RowScanner) error {
+	var at string
+	if err := s.Scan(&o.Owner, &at); err != nil {
+		return err
+	}
+	_, err := fmt.Sscanf(at, "%d,%d", &o.At.X, &o.At.Y)
+	return err
+}
+
+func (o *Owned) Fields() []any { return []any{o.Owner, o.At} }
+
+func TestArgConverter(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	mustExec(t, db, "CREATE TABLE owned (owner TEXT, at TEXT, n INTEGER)")
+	points := func(v any) (any, bool) {
+		if p, ok := v.(point); ok {
+			return fmt.Sprintf("%d,%d", p.X, p.Y), true
+		}
+		return nil, false
+	}
+	const insert = "INSERT INTO owned (owner, at) VALUES (?, ?)"
+	if err := csql.NewSQLTableManager[Owned](db).Exec(insert, UserID("u1"), point{1, 2}); err == nil {
+		t.Fatal("Exec of a struct arg succeeded without a converter")
+	}
+	m := csql.NewSQLTableManager[Owned](db, csql.WithArgConverter(points))
+	if err := m.Exec(insert, UserID("u1"), point{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Transaction(insert, []Owned{{"u2", point{3, 4}}}); err != nil {
+		t.Fatal(err)
+	}
+	const byOwner = "SELECT owner, at FROM owned WHERE owner = ? AND at = ?"
+	if got, err := m.QueryRow(byOwner, UserID("u2"), point{3, 4}); err != nil || got != (Owned{"u2", point{3, 4}}) {
+		t.Fatalf("QueryRow = %v, %v", got, err)
+	}
+	// Valuers reach the driver through their own Value
+	if err := m.Exec("UPDATE owned SET n = ? WHERE owner = ?", csql.Null[int]{V: 7, Valid: true}, UserID("u1")); err != nil {
+		t.Fatal(err)
+	}
+	var exec, query []driver.NamedValue
+	for _, s := range rec.Stmts() {
+		switch s.SQL {
+		case insert:
+			if exec == nil {
+				exec = s.Named
+			}
+		case byOwner:
+			query = s.Named
+		}
+	}
+	if fmt.Sprint(exec) != "[{ 1 u1} { 2 1,2}]" || fmt.Sprint(query) != "[{ 1 u2} { 2 3,4}]" {
+		t.Fatalf("driver got %v and %v, want the converted args", exec, query)
+	}
+	var n int
+	if err := db.QueryRow("SELECT n FROM owned WHERE owner = 'u1'").Scan(&n); err != nil || n != 7 {
+		t.Fatalf("n = %d, %v, want the Valuer's value", n, err)
+	}
+	if got := constructPanic(func() { csql.NewSQLTableManager[Owned](db, csql.WithArgConverter(nil)) }); !strings.Contains(got, "must not be nil") {
+		t.Fatalf("NewSQLTableManager panicked with %q, want the nil converter rejected", got)
+	}
+}
@@ -0,0 +1,50 @@
+package csql
+
+import (
+	"context"
+	"time"
+)
+
+// AuditEvent describes a mutation made through the manager
+type AuditEvent struct {
+	// Method is the manager method, such as Exec, Update, or Transaction
+	Method string
+	// Table is the WithTable table, if any
+	Table string
+	// Rows is the number of rows affected, or -1 if the driver cannot tell
+	Rows int64
+	Time time.Time
+	// Actor is the context value under the WithAuditActor key
+	Actor any
+	Err   error
+}
+
+// WithAuditHook calls fn after every mutation: Exec, Insert, Update, Delete,
+// and the Transaction family. Reads and dry runs are not audited
+func WithAuditHook(fn func(AuditEvent)) Option {
+	return func(o *options) error {
+		o.auditHook = fn
+		return nil
+	}
+}
+
+// WithAuditActor sets the context key whose value is reported as the
+// AuditEvent Actor
+func WithAuditActor(key any) Option {
+	return func(o *options) error {
+		o.auditActor = key
+		return nil
+	}
+}
+
+// audit reports a mutation to the audit hook
+func (o *options) audit(ctx context.Context, method string, rows int64, err error) {
+	if o.auditHook == nil || o.dryRun != nil {
+		return
+	}
+	e := AuditEvent{Method: method, Table: o.table, Rows: rows, Time: time.Now(), Err: err}
+	if o.auditActor != nil {
+		e.Actor = ctx.Value(o.auditActor)
+	}
+	o.auditHook(e)
+}
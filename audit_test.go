@@ -0,0 +1,52 @@
+package csql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+// actorKey is the context key of the audited actor
+type actorKey struct{}
+
+func TestAuditHook(t *testing.T) {
+	db := openPeople(t)
+	var events []csql.AuditEvent
+	m := csql.NewSQLTableManager[Person](db, csql.WithTable("people"), csql.WithAuditActor(actorKey{}),
+		csql.WithAuditHook(func(e csql.AuditEvent) { events = append(events, e) }))
+	ctx := context.WithValue(context.Background(), actorKey{}, "alice")
+	if _, err := m.Transaction("INSERT INTO people (id, ssn) VALUES (?, ?)", []Person{{1, "a"}, {2, "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.QueryContext(ctx, "SELECT id, ssn FROM people"); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := m.Update(ctx, Person{ID: 2, SSN: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	failed := m.ExecContext(ctx, "INSERT INTO missing VALUES (1)")
+	if failed == nil {
+		t.Fatal("Exec into a missing table succeeded")
+	}
+
+	want := []csql.AuditEvent{
+		{Method: "Transaction", Table: "people", Rows: 2},
+		{Method: "Update", Table: "people", Rows: 1, Actor: "alice"},
+		{Method: "Exec", Table: "people", Rows: 0, Actor: "alice", Err: failed},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("audited %d events, want %d for the mutations only: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Method != w.Method || e.Table != w.Table || e.Rows != w.Rows || e.Actor != w.Actor || (e.Err == nil) != (w.Err == nil) {
+			t.Errorf("event %d = %+v, want %+v", i, e, w)
+		}
+	}
+	if e := events[1]; e.Time.Before(start) || e.Time.After(time.Now()) {
+		t.Errorf("Update audited at %v, want the time it ran", e.Time)
+	}
+}
@@ -0,0 +1,78 @@
+package csql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Stmt is one statement of ExecBatch
+type Stmt struct {
+	SQL  string
+	Args []any
+}
+
+// ExecBatch executes stmts in order within a single database transaction,
+// rolling back on the first failure, which is wrapped with the index of the
+// failing statement. An empty batch does nothing
+func (m *SQLTableManager[_, _]) ExecBatch(ctx context.Context, stmts []Stmt) error {
+	if len(stmts) == 0 {
+		return nil
+	}
+	queries := make([]string, len(stmts))
+	for i, s := range stmts {
+		queries[i] = m.opts.finalize(ctx, s.SQL, s.Args)
+	}
+	return m.execBatch(ctx, "ExecBatch", stmts, queries)
+}
+
+// execBatch runs ExecBatch for method over stmts already finalized into queries
+func (m *SQLTableManager[_, _]) execBatch(ctx context.Context, method string, stmts []Stmt, queries []string) (err error) {
+	summary := strings.Join(queries, "; ")
+	defer m.opts.annotate(&err, method, summary)
+	var affected int64
+	defer func() { m.opts.audit(ctx, method, affected, err) }()
+	if err := m.opts.writable(); err != nil {
+		return err
+	}
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	if m.opts.dryRun != nil {
+		for i, s := range stmts {
+			m.opts.dryRun(queries[i], append([]any(nil), s.Args...))
+		}
+		return nil
+	}
+	var after func(*error)
+	if ctx, after, err = m.opts.beforeExec(ctx, method, summary, nil, len(stmts)); err != nil {
+		return err
+	}
+	defer after(&err)
+	defer m.opts.invalidate()
+	var execed int
+	if m.opts.observed() {
+		var start time.Time
+		ctx, start = m.opts.begin(ctx, OpExec, summary, nil)
+		defer func() {
+			m.opts.observe(ctx, OpExec, summary, nil, start, int64(execed), err)
+		}()
+	}
+	tx, err := m.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	for i, s := range stmts {
+		res, err := tx.ExecContext(ctx, queries[i], m.opts.convertArgs(s.Args)...)
+		if err != nil {
+			affected = 0
+			return rollback(tx, fmt.Errorf("csql: statement %d: %w", i, err))
+		}
+		execed++
+		affected = addAffected(affected, rowsAffected(res, nil))
+	}
+	if err = tx.Commit(); err != nil {
+		affected = 0
+	}
+	return err
+}
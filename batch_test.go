@@ -0,0 +1,52 @@
+package csql_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestExecBatch(t *testing.T) {
+	ctx := context.Background()
+	m := csql.NewSQLTableManager[Item](openDB(t))
+	err := m.ExecBatch(ctx, []csql.Stmt{
+		{SQL: insertItem, Args: []any{1, "a"}},
+		{SQL: "UPDATE items SET name = ? WHERE id = ?", Args: []any{"b", 1}},
+		{SQL: insertItem, Args: []any{2, "c"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row, err := m.QueryRow("SELECT id, name FROM items WHERE id = 1"); err != nil || row.Name != "b" {
+		t.Fatalf("QueryRow = %v, %v, want item 1 renamed b", row, err)
+	}
+	if n := countItems(t, m); n != 2 {
+		t.Fatalf("stored %d rows, want 2", n)
+	}
+}
+
+func TestExecBatchRollsBack(t *testing.T) {
+	ctx := context.Background()
+	m := csql.NewSQLTableManager[Item](openDB(t))
+	err := m.ExecBatch(ctx, []csql.Stmt{
+		{SQL: insertItem, Args: []any{1, "a"}},
+		{SQL: insertItem, Args: []any{2, "b"}},
+		{SQL: insertItem, Args: []any{1, "duplicate"}},
+		{SQL: insertItem, Args: []any{3, "c"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "statement 2:") {
+		t.Fatalf("ExecBatch = %v, want the third statement, index 2, reported", err)
+	}
+	if n := countItems(t, m); n != 0 {
+		t.Fatalf("stored %d rows after the failed batch, want 0", n)
+	}
+}
+
+func TestExecBatchEmpty(t *testing.T) {
+	m := csql.NewSQLTableManager[Item](openDB(t), csql.WithReadOnly())
+	if err := m.ExecBatch(context.Background(), nil); err != nil {
+		t.Fatalf("empty ExecBatch = %v, want no error", err)
+	}
+}
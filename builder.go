@@ -0,0 +1,113 @@
+package csql
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// Builder composes a SELECT of the manager's Schema columns from its table.
+// Methods may be called in any order and the SQL is always assembled in
+// clause order. Start one with SQLTableManager.Builder
+type Builder[T any, R Schema[T]] struct {
+	m       *SQLTableManager[T, R]
+	where   []string
+	args    []any
+	orderBy []string
+	limit   int
+	offset  int
+	suffix  []string
+	sufArgs []any
+}
+
+// Builder returns an empty Builder selecting every table row.
+// Soft-deleted rows are skipped unless the manager came from WithTrashed
+func (m *SQLTableManager[T, R]) Builder() *Builder[T, R] {
+	return &Builder[T, R]{m: m, limit: -1, offset: -1}
+}
+
+// Where adds the condition cond, ANDed with the others
+func (b *Builder[T, R]) Where(cond string, args ...any) *Builder[T, R] {
+	b.where = append(b.where, cond)
+	b.args = append(b.args, args...)
+	return b
+}
+
+// OrderBy appends ordering terms, such as "created_at DESC"
+func (b *Builder[T, R]) OrderBy(terms ...string) *Builder[T, R] {
+	b.orderBy = append(b.orderBy, terms...)
+	return b
+}
+
+// Limit caps the number of rows. A negative n removes the cap
+func (b *Builder[T, R]) Limit(n int) *Builder[T, R] {
+	b.limit = n
+	return b
+}
+
+// Offset skips the first n rows. A negative n removes the offset
+func (b *Builder[T, R]) Offset(n int) *Builder[T, R] {
+	b.offset = n
+	return b
+}
+
+// Suffix appends raw SQL after every other clause, such as FOR UPDATE,
+// following the SQL of earlier calls
+func (b *Builder[T, R]) Suffix(sql string, args ...any) *Builder[T, R] {
+	b.suffix = append(b.suffix, sql)
+	b.sufArgs = append(b.sufArgs, args...)
+	return b
+}
+
+// SQL returns the statement as All sends it to the database, with
+// placeholders in the manager's dialect and rewritten by WithQueryRewriter
+// under a background context, and its arguments
+func (b *Builder[T, R]) SQL() (string, []any) {
+	query, args := b.build()
+	return b.m.opts.finalize(context.Background(), query, args), args
+}
+
+// All runs the statement, returning the matching rows
+func (b *Builder[T, R]) All(ctx context.Context) ([]T, error) {
+	if b.m.opts.table == "" {
+		return nil, ErrNoTable
+	}
+	query, args := b.build()
+	return b.m.QueryContext(ctx, query, args...)
+}
+
+func (b *Builder[T, R]) build() (string, []any) {
+	m := b.m
+	var q strings.Builder
+	q.WriteString("SELECT " + m.SelectColumns() + " FROM " + m.opts.table)
+	where := strings.Join(b.where, " AND ")
+	if len(b.where) > 1 {
+		where = "(" + strings.Join(b.where, ") AND (") + ")"
+	}
+	q.WriteString(m.scope(where, !m.withTrashed))
+	if len(b.orderBy) > 0 {
+		q.WriteString(" ORDER BY " + strings.Join(b.orderBy, ", "))
+	}
+	// MySQL and SQLite reject OFFSET without LIMIT, so they get an unbounded one
+	limit := ""
+	switch {
+	case b.limit >= 0:
+		limit = strconv.Itoa(b.limit)
+	case b.offset >= 0 && m.opts.dialect == MySQL:
+		limit = "18446744073709551615"
+	case b.offset >= 0 && m.opts.dialect == SQLite:
+		limit = "-1"
+	}
+	if limit != "" {
+		q.WriteString(" LIMIT " + limit)
+	}
+	if b.offset >= 0 {
+		q.WriteString(" OFFSET " + strconv.Itoa(b.offset))
+	}
+	args := b.args
+	if len(b.suffix) > 0 {
+		q.WriteString(" " + strings.Join(b.suffix, " "))
+		args = append(append([]any(nil), args...), b.sufArgs...)
+	}
+	return q.String(), args
+}
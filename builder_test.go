@@ -0,0 +1,42 @@
+package csql_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// tagQueries marks every statement with a comment
+func tagQueries(_ context.Context, query string) string { return query + " /* tagged */" }
+
+func TestBuilderSQL(t *testing.T) {
+	m := csql.NewSQLTableManager[Item](openDB(t), csql.WithTable("items"), csql.WithDialect(csql.Postgres), csql.WithQueryRewriter(tagQueries))
+	query, args := m.Builder().
+		Where("id > ?", 1).
+		OrderBy("id").
+		Limit(10).
+		Suffix("FOR UPDATE").
+		Suffix("OF items SKIP LOCKED").
+		SQL()
+	want := "SELECT ID, Name FROM items WHERE (id > $1) ORDER BY id LIMIT 10 FOR UPDATE OF items SKIP LOCKED /* tagged */"
+	if query != want || !reflect.DeepEqual(args, []any{1}) {
+		t.Fatalf("SQL() = %q, %v, want %q, [1]", query, args, want)
+	}
+}
+
+func TestBuilderAll(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	seedItems(t, db, 5)
+	m := csql.NewSQLTableManager[Item](db, csql.WithTable("items"), csql.WithQueryRewriter(tagQueries))
+	b := m.Builder().Where("id > ?", 1).Where("id < ?", 5).OrderBy("id DESC").Limit(2)
+	rows, err := b.All(context.Background())
+	if err != nil || len(rows) != 2 || rows[0].ID != 4 || rows[1].ID != 3 {
+		t.Fatalf("All = %v, %v, want items 4 and 3", rows, err)
+	}
+	query, _ := b.SQL()
+	if rec.Count(query) != 1 {
+		t.Fatalf("database did not see the statement SQL returns, %q, among %v", query, rec.Stmts())
+	}
+}
@@ -0,0 +1,255 @@
+package csql
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithQueryCache memoizes the rows Query and QueryRow return for ttl, keyed
+// by the query text, with whitespace outside literals collapsed, and its
+// arguments. At most maxEntries results are kept, evicting the least
+// recently used. Rows are copied in and out through the Schema, so callers
+// may modify them. Every write through the manager empties the cache, as
+// does InvalidateAll, and InvalidateCache drops a single query; writes made
+// elsewhere are only seen once ttl expires
+func WithQueryCache(ttl time.Duration, maxEntries int) Option {
+	return func(o *options) error {
+		if ttl <= 0 {
+			return fmt.Errorf("csql: query cache ttl must be positive, got %s", ttl)
+		}
+		if maxEntries <= 0 {
+			return fmt.Errorf("csql: query cache entries must be positive, got %d", maxEntries)
+		}
+		o.cache = &queryCache{
+			ttl:     ttl,
+			max:     maxEntries,
+			entries: make(map[string]*list.Element),
+			lru:     list.New(),
+		}
+		return nil
+	}
+}
+
+// InvalidateAll empties the WithQueryCache cache
+func (m *SQLTableManager[T, R]) InvalidateAll() {
+	m.opts.invalidate()
+}
+
+// InvalidateCache drops the WithQueryCache results of query with args read
+// by Query and QueryRow, leaving the others cached. query is matched with
+// whitespace outside literals collapsed, as it is cached
+func (m *SQLTableManager[T, R]) InvalidateCache(query string, args ...any) {
+	c := m.opts.cache
+	if c == nil {
+		return
+	}
+	for _, method := range []string{"Query", "QueryRow"} {
+		if key, ok := m.resultKey(context.Background(), method, query, args); ok {
+			c.remove(key)
+		}
+	}
+}
+
+// invalidate empties the cache, if any, after a write
+func (o *options) invalidate() {
+	if o.cache != nil {
+		o.cache.invalidate()
+	}
+}
+
+// resultKey returns the key identifying the result of query read by method,
+// reporting false when the result is neither cached nor shared or its
+// arguments cannot be keyed
+func (m *SQLTableManager[T, R]) resultKey(ctx context.Context, method, query string, args []any) (string, bool) {
+	if m.opts.cache == nil && m.opts.flights == nil || m.pinned {
+		return "", false
+	}
+	return cacheKey(method, reflect.TypeOf((*T)(nil)).Elem(), m.opts.finalize(ctx, query, args), args)
+}
+
+// fromCache returns the rows cached under key appended to dst when hit is
+// set. Otherwise store, when not nil, caches the rows of a fresh query
+func (m *SQLTableManager[T, R]) fromCache(key string, keyed bool, dst []T) (rows []T, hit bool, store func([]T)) {
+	c := m.opts.cache
+	if c == nil || !keyed {
+		return nil, false, nil
+	}
+	values, gen, ok := c.get(key)
+	if ok {
+		rows = dst
+		for _, v := range values {
+			var zero T
+			rows = append(rows, zero)
+			// the values were scanned through the manager already
+			if err := R(&rows[len(rows)-1]).ScanRow(valueScanner(v)); err != nil {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return rows, true, nil
+		}
+	}
+	return nil, false, func(rows []T) {
+		values := make([][]any, len(rows))
+		for i := range rows {
+			v, ok := driverValues(R(&rows[i]).Fields())
+			if !ok {
+				return
+			}
+			values[i] = v
+		}
+		c.put(key, gen, values)
+	}
+}
+
+// cacheKey identifies a query by the method and row type reading it, its
+// normalized text and its arguments as driver values. It reports false for
+// arguments without a driver value
+func cacheKey(method string, t reflect.Type, query string, args []any) (string, bool) {
+	var b strings.Builder
+	b.WriteString(method)
+	b.WriteByte(' ')
+	b.WriteString(t.String())
+	b.WriteByte(' ')
+	normalize(&b, query)
+	for _, arg := range args {
+		if named, ok := arg.(sql.NamedArg); ok {
+			b.WriteString(" @" + named.Name)
+			arg = named.Value
+		}
+		v, err := driver.DefaultParameterConverter.ConvertValue(arg)
+		if err != nil {
+			return "", false
+		}
+		fmt.Fprintf(&b, " %T:%q", v, fmt.Sprint(v))
+	}
+	return b.String(), true
+}
+
+// normalize writes query with each run of whitespace outside quotes as a
+// single space
+func normalize(b *strings.Builder, query string) {
+	var quote rune
+	space := false
+	for _, r := range strings.TrimSpace(query) {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+}
+
+// driverValues converts Fields to driver values, copying byte slices so the
+// cache owns them. It reports false when a field has no driver value
+func driverValues(fields []any) ([]any, bool) {
+	values := make([]any, len(fields))
+	for i, f := range fields {
+		v, err := driver.DefaultParameterConverter.ConvertValue(f)
+		if err != nil {
+			return nil, false
+		}
+		if b, ok := v.([]byte); ok {
+			v = append([]byte(nil), b...)
+		}
+		values[i] = v
+	}
+	return values, true
+}
+
+// queryCache is a TTL and LRU bound cache of query results as driver values
+type queryCache struct {
+	ttl time.Duration
+	max int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	// gen counts invalidations, so results read before one are not stored
+	gen uint64
+}
+
+type cacheEntry struct {
+	key     string
+	values  [][]any
+	expires time.Time
+}
+
+// get returns the unexpired values cached under key, and the current
+// generation to store a fresh result under
+func (c *queryCache) get(key string) ([][]any, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, c.gen, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+		return nil, c.gen, false
+	}
+	c.lru.MoveToFront(el)
+	return entry.values, c.gen, true
+}
+
+// put caches values under key unless the cache was invalidated since gen
+func (c *queryCache) put(key string, gen uint64, values [][]any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return
+	}
+	entry := &cacheEntry{key: key, values: values, expires: time.Now().Add(c.ttl)}
+	if el, ok := c.entries[key]; ok {
+		el.Value = entry
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.max {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// remove drops the result cached under key. It counts as an invalidation,
+// so a read racing it cannot store a result older than the call
+func (c *queryCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	if el, ok := c.entries[key]; ok {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+	}
+}
+
+func (c *queryCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	clear(c.entries)
+	c.lru.Init()
+}
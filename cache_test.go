@@ -0,0 +1,125 @@
+package csql_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+const selectItems = "SELECT id, name FROM items ORDER BY id"
+
+func TestQueryCacheHit(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	seedItems(t, db, 2)
+	m := csql.NewSQLTableManager[Item](db, csql.WithQueryCache(time.Minute, 10))
+	for i := 0; i < 3; i++ {
+		rows, err := m.Query(selectItems)
+		if err != nil || len(rows) != 2 {
+			t.Fatalf("Query = %v, %v", rows, err)
+		}
+		rows[0].Name = "modified"
+	}
+	if n := rec.Count(selectItems); n != 1 {
+		t.Fatalf("database saw the query %d times, want 1", n)
+	}
+	if row, err := m.QueryRow(selectItems); err != nil || row.Name != "item1" {
+		t.Fatalf("QueryRow = %v, %v, want the stored row unaffected by callers", row, err)
+	}
+}
+
+func TestQueryCacheExpires(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	seedItems(t, db, 1)
+	m := csql.NewSQLTableManager[Item](db, csql.WithQueryCache(20*time.Millisecond, 10))
+	if _, err := m.Query(selectItems); err != nil {
+		t.Fatal(err)
+	}
+	mustExec(t, db, "UPDATE items SET name = 'changed'")
+	if rows, _ := m.Query(selectItems); rows[0].Name != "item1" {
+		t.Fatalf("Query within the ttl = %v, want the cached row", rows)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if rows, _ := m.Query(selectItems); rows[0].Name != "changed" {
+		t.Fatalf("Query after the ttl = %v, want the changed row", rows)
+	}
+	if n := rec.Count(selectItems); n != 2 {
+		t.Fatalf("database saw the query %d times, want 2", n)
+	}
+}
+
+func TestQueryCacheInvalidatedByWrites(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	seedItems(t, db, 1)
+	m := csql.NewSQLTableManager[Item](db, csql.WithQueryCache(time.Minute, 10))
+	writes := []func() error{
+		func() error { return m.Exec("UPDATE items SET name = 'exec'") },
+		func() error {
+			_, err := m.Transaction(insertItem, []Item{{ID: 2, Name: "tx"}})
+			return err
+		},
+		func() error { m.InvalidateAll(); return nil },
+	}
+	for i, write := range writes {
+		if _, err := m.Query(selectItems); err != nil {
+			t.Fatal(err)
+		}
+		if err := write(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Query(selectItems); err != nil {
+			t.Fatal(err)
+		}
+		// each round but the first reads the entry the previous one cached
+		if n := rec.Count(selectItems); n != i+2 {
+			t.Fatalf("write %d: database saw the query %d times, want %d", i, n, i+2)
+		}
+	}
+}
+
+func TestQueryCacheEvicts(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	seedItems(t, db, 2)
+	m := csql.NewSQLTableManager[Item](db, csql.WithQueryCache(time.Minute, 1))
+	const byID = "SELECT id, name FROM items WHERE id = ?"
+	for _, id := range []int{1, 2, 1} {
+		if _, err := m.QueryRow(byID, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := rec.Count(byID); n != 3 {
+		t.Fatalf("database saw the query %d times, want 3 with one entry cached", n)
+	}
+}
+
+func TestInvalidateCache(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	seedItems(t, db, 2)
+	m := csql.NewSQLTableManager[Item](db, csql.WithQueryCache(time.Minute, 10))
+	const byID = "SELECT id, name FROM items WHERE id = ?"
+	read := func() {
+		t.Helper()
+		for _, id := range []int{1, 2} {
+			if _, err := m.QueryRow(byID, id); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := m.Query(byID, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read()
+	// whitespace is collapsed as in the cached query
+	m.InvalidateCache("SELECT id, name\n\tFROM items WHERE id = ?", 1)
+	read()
+	// the QueryRow and Query of id 1 are read again, id 2 stays cached
+	if n := rec.Count(byID); n != 5 {
+		t.Fatalf("database saw the query %d times, want 5", n)
+	}
+	m.InvalidateCache("SELECT id FROM items")
+	csql.NewSQLTableManager[Item](db).InvalidateCache(byID, 2)
+	read()
+	if n := rec.Count(byID); n != 5 {
+		t.Fatalf("database saw the query %d times, want other invalidations to leave the cache alone", n)
+	}
+}
@@ -0,0 +1,221 @@
+package csql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// WithColumnCipher stores the values of columns enciphered by enc and
+// deciphers them with dec once scanned, for PII encrypted at rest. Values
+// are written by the Fields of Transaction and its variants, Update, the
+// Insert and Upsert helpers, and CopyFrom, each field matched to its column
+// by position, and read by the ScanRow destinations of those columns in the
+// Schema's column order. Strings and []byte are enciphered as bytes,
+// driver.Valuer values by their value, and NULL is left untouched either
+// way. Deciphered bytes scan into string, []byte, any, and sql.Scanner
+// destinations, or pointers to them, and through WithTypeCodec codecs. The
+// arguments of Exec and Query are not enciphered, so a WHERE on an
+// enciphered column needs its argument enciphered by the caller, which
+// only matches when enc is deterministic
+func WithColumnCipher(columns []string, enc, dec func([]byte) ([]byte, error)) Option {
+	return func(o *options) error {
+		if len(columns) == 0 {
+			return fmt.Errorf("csql: column cipher requires at least one column")
+		}
+		if enc == nil || dec == nil {
+			return fmt.Errorf("csql: column cipher funcs must not be nil")
+		}
+		c := &columnCipher{columns: make(map[string]bool, len(columns)), enc: enc, dec: dec}
+		for _, column := range columns {
+			c.columns[strings.ToLower(column)] = true
+		}
+		o.cipher = c
+		return nil
+	}
+}
+
+// columnCipher is the configuration of WithColumnCipher
+type columnCipher struct {
+	// columns holds the lowercased columns enciphered
+	columns  map[string]bool
+	enc, dec func([]byte) ([]byte, error)
+}
+
+// sealFields returns fields, bound to columns in order, converted per
+// convertArgs and with the fields of enciphered columns enciphered once bound
+func (o *options) sealFields(columns []string, fields []any) []any {
+	fields = o.convertArgs(fields)
+	if o.cipher == nil {
+		return fields
+	}
+	sealed := make([]any, len(fields))
+	for i, f := range fields {
+		sealed[i] = f
+		if i < len(columns) && o.cipher.columns[strings.ToLower(columns[i])] {
+			sealed[i] = sealedValue{f, o.cipher.enc}
+		}
+	}
+	return sealed
+}
+
+// sealedValue binds v enciphered by enc
+type sealedValue struct {
+	v   any
+	enc func([]byte) ([]byte, error)
+}
+
+func (s sealedValue) Value() (driver.Value, error) {
+	v, err := driver.DefaultParameterConverter.ConvertValue(s.v)
+	if err != nil || v == nil {
+		return v, err
+	}
+	var plain []byte
+	switch v := v.(type) {
+	case []byte:
+		plain = v
+	case string:
+		plain = []byte(v)
+	default:
+		return nil, fmt.Errorf("csql: cannot encipher a %T", v)
+	}
+	sealed, err := s.enc(plain)
+	if err != nil {
+		return nil, err
+	}
+	return sealed, nil
+}
+
+// cipherScanner is a RowScanner deciphering the destinations at the
+// positions set in opened, see WithColumnCipher
+type cipherScanner struct {
+	RowScanner
+	opened []bool
+	dec    func([]byte) ([]byte, error)
+}
+
+func (s cipherScanner) Scan(dest ...any) error {
+	return s.RowScanner.Scan(openDest(s.opened, s.dec, dest)...)
+}
+
+func (s cipherScanner) hasCodec(t reflect.Type) bool { return scansWithCodec(s.RowScanner, t) }
+
+// cipherColumnScanner is cipherScanner for a ColumnScanner
+type cipherColumnScanner struct {
+	ColumnScanner
+	opened []bool
+	dec    func([]byte) ([]byte, error)
+}
+
+func (s cipherColumnScanner) Scan(dest ...any) error {
+	return s.ColumnScanner.Scan(openDest(s.opened, s.dec, dest)...)
+}
+
+func (s cipherColumnScanner) hasCodec(t reflect.Type) bool { return scansWithCodec(s.ColumnScanner, t) }
+
+// scanning wraps r to decipher the Schema's enciphered columns, or
+// returns r when it has none
+func (c *columnCipher) scanning(r RowScanner, columns []string) RowScanner {
+	var opened []bool
+	for i, column := range columns {
+		if c.columns[strings.ToLower(column)] {
+			if opened == nil {
+				opened = make([]bool, len(columns))
+			}
+			opened[i] = true
+		}
+	}
+	if opened == nil {
+		return r
+	}
+	if cs, ok := r.(ColumnScanner); ok {
+		return cipherColumnScanner{cs, opened, c.dec}
+	}
+	return cipherScanner{r, opened, c.dec}
+}
+
+// openDest returns dest with the destinations at the positions set in
+// opened wrapped to decipher
+func openDest(opened []bool, dec func([]byte) ([]byte, error), dest []any) []any {
+	wrapped := make([]any, len(dest))
+	for i, d := range dest {
+		wrapped[i] = d
+		if i < len(opened) && opened[i] {
+			wrapped[i] = openedTarget{d, dec}
+		}
+	}
+	return wrapped
+}
+
+// openedTarget scans into dest the bytes dec deciphers
+type openedTarget struct {
+	dest any
+	dec  func([]byte) ([]byte, error)
+}
+
+func (t openedTarget) Scan(src any) error {
+	var sealed []byte
+	switch v := src.(type) {
+	case nil:
+		return assignPlain(t.dest, nil)
+	case []byte:
+		sealed = v
+	case string:
+		sealed = []byte(v)
+	default:
+		return fmt.Errorf("csql: cannot decipher a %T", src)
+	}
+	plain, err := t.dec(sealed)
+	if err != nil {
+		return err
+	}
+	return assignPlain(t.dest, plain)
+}
+
+// assignPlain stores deciphered bytes in dest, nil for NULL, which leaves
+// destinations that cannot hold NULL untouched
+func assignPlain(dest any, plain []byte) error {
+	if s, ok := dest.(sql.Scanner); ok {
+		if plain == nil {
+			return s.Scan(nil)
+		}
+		return s.Scan(plain)
+	}
+	if d, ok := dest.(*any); ok {
+		if plain == nil {
+			*d = nil
+		} else {
+			*d = plain
+		}
+		return nil
+	}
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("csql: cannot scan a deciphered column into %T", dest)
+	}
+	v := rv.Elem()
+	if v.Kind() == reflect.Pointer {
+		if plain == nil {
+			v.SetZero()
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if plain == nil {
+		return nil
+	}
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(string(plain))
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		v.SetBytes(append([]byte{}, plain...))
+	default:
+		return fmt.Errorf("csql: cannot scan a deciphered column into %T", dest)
+	}
+	return nil
+}
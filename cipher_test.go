@@ -0,0 +1,59 @@
+package csql_test
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// Code is a named string scanned and bound through a codec
+type Code string
+
+// Secret is a reflected Schema with an enciphered column and codec columns
+type Secret struct {
+	ID    int64  `csql:"id"`
+	Token Code   `csql:"token"`
+	Label Code   `csql:"label"`
+	Note  string `csql:"note"`
+}
+
+func (s *Secret) ScanRow(r csql.RowScanner) error { return csql.ScanInto(r, s) }
+
+func (s *Secret) Fields() []any { return csql.ReflectFields(s) }
+
+// reverse is a stand-in cipher, its own inverse
+func reverse(b []byte) ([]byte, error) {
+	out := slices.Clone(b)
+	slices.Reverse(out)
+	return out, nil
+}
+
+func TestColumnCipherWithCodec(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE secrets (id INTEGER PRIMARY KEY, token BLOB, label TEXT, note BLOB)")
+	m := csql.NewSQLTableManager[Secret](db, csql.WithTable("secrets"),
+		csql.WithColumnCipher([]string{"token", "note"}, reverse, reverse),
+		csql.WithTypeCodec(
+			func(b []byte) (Code, error) { return Code(strings.ToUpper(string(b))), nil },
+			func(c Code) (driver.Value, error) { return strings.ToLower(string(c)), nil },
+		))
+	row := Secret{ID: 1, Token: "TOKEN", Label: "LABEL", Note: "plain"}
+	if _, err := m.Transaction("INSERT INTO secrets (id, token, label, note) VALUES (?, ?, ?, ?)", []Secret{row}); err != nil {
+		t.Fatal(err)
+	}
+	var token, label, note []byte
+	if err := db.QueryRow("SELECT token, label, note FROM secrets").Scan(&token, &label, &note); err != nil {
+		t.Fatal(err)
+	}
+	if string(token) != "nekot" || string(label) != "label" || bytes.Equal(note, []byte("plain")) {
+		t.Fatalf("stored %q, %q, %q, want the codec's values, enciphered where marked", token, label, note)
+	}
+	got, err := m.QueryRow("SELECT id, token, label, note FROM secrets")
+	if err != nil || got != row {
+		t.Fatalf("Get = %+v, %v, want %+v", got, err, row)
+	}
+}
@@ -0,0 +1,139 @@
+package csql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// WithTypeCodec registers how values of type V, such as a UUID or money
+// type, are scanned from and bound to the database, so V need not
+// implement sql.Scanner and driver.Valuer itself. ScanRow destinations of
+// type *V are scanned with scan, which is passed the column's bytes, nil
+// for NULL; non-byte, non-string values are formatted as by fmt, times as
+// RFC 3339. Arguments and Fields of type V are bound with value. Codecs
+// take precedence over methods V implements, and over ScanInto's scanning
+// of named basic types
+func WithTypeCodec[V any](scan func([]byte) (V, error), value func(V) (driver.Value, error)) Option {
+	return func(o *options) error {
+		if scan == nil || value == nil {
+			return fmt.Errorf("csql: type codec funcs must not be nil")
+		}
+		if o.codecs == nil {
+			o.codecs = make(map[reflect.Type]typeCodec)
+		}
+		o.codecs[reflect.TypeOf((*V)(nil)).Elem()] = typeCodec{
+			scan: func(src []byte, dest any) error {
+				v, err := scan(src)
+				if err != nil {
+					return err
+				}
+				*dest.(*V) = v
+				return nil
+			},
+			value: func(v any) (driver.Value, error) {
+				return value(v.(V))
+			},
+		}
+		return nil
+	}
+}
+
+// typeCodec is a codec registered by WithTypeCodec, with its value type erased
+type typeCodec struct {
+	// scan stores the value decoded from src in dest, a pointer to the type
+	scan  func(src []byte, dest any) error
+	value func(v any) (driver.Value, error)
+}
+
+// codecScanner is a RowScanner scanning through codecs, see WithTypeCodec
+type codecScanner struct {
+	RowScanner
+	codecs map[reflect.Type]typeCodec
+}
+
+func (s codecScanner) Scan(dest ...any) error {
+	return s.RowScanner.Scan(codecDest(s.codecs, dest)...)
+}
+
+func (s codecScanner) hasCodec(t reflect.Type) bool {
+	_, ok := s.codecs[t]
+	return ok
+}
+
+// codecColumnScanner is codecScanner for a ColumnScanner
+type codecColumnScanner struct {
+	ColumnScanner
+	codecs map[reflect.Type]typeCodec
+}
+
+func (s codecColumnScanner) Scan(dest ...any) error {
+	return s.ColumnScanner.Scan(codecDest(s.codecs, dest)...)
+}
+
+func (s codecColumnScanner) hasCodec(t reflect.Type) bool {
+	_, ok := s.codecs[t]
+	return ok
+}
+
+// codecScanning wraps r to scan through codecs
+func codecScanning(r RowScanner, codecs map[reflect.Type]typeCodec) RowScanner {
+	if c, ok := r.(ColumnScanner); ok {
+		return codecColumnScanner{c, codecs}
+	}
+	return codecScanner{r, codecs}
+}
+
+// scansWithCodec reports whether r scans values of type t through a codec.
+// Scanners wrapping another forward hasCodec to it
+func scansWithCodec(r RowScanner, t reflect.Type) bool {
+	c, ok := r.(interface{ hasCodec(reflect.Type) bool })
+	return ok && c.hasCodec(t)
+}
+
+// codecDest returns dest with the destinations of registered types wrapped
+func codecDest(codecs map[reflect.Type]typeCodec, dest []any) []any {
+	wrapped := make([]any, len(dest))
+	for i, d := range dest {
+		wrapped[i] = d
+		if t := reflect.TypeOf(d); t != nil && t.Kind() == reflect.Pointer {
+			if c, ok := codecs[t.Elem()]; ok {
+				wrapped[i] = codecTarget{d, c.scan}
+			}
+		}
+	}
+	return wrapped
+}
+
+// codecTarget scans into dest through a codec
+type codecTarget struct {
+	dest any
+	scan func(src []byte, dest any) error
+}
+
+func (c codecTarget) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		return c.scan(nil, c.dest)
+	case []byte:
+		// the driver may reuse v once Scan returns
+		return c.scan(append([]byte{}, v...), c.dest)
+	case string:
+		return c.scan([]byte(v), c.dest)
+	case time.Time:
+		return c.scan(v.AppendFormat(nil, time.RFC3339Nano), c.dest)
+	default:
+		return c.scan(fmt.Append(nil, v), c.dest)
+	}
+}
+
+// codecValue binds a value through a codec
+type codecValue struct {
+	v     any
+	value func(v any) (driver.Value, error)
+}
+
+func (c codecValue) Value() (driver.Value, error) {
+	return c.value(c.v)
+}
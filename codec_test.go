@@ -0,0 +1,73 @@
+package csql_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// UUID is an application type without Scanner or Valuer methods
+type UUID [16]byte
+
+// Session is a reflected Schema of the sessions (id, user) table
+type Session struct {
+	ID   UUID   `csql:"id"`
+	User string `csql:"user"`
+}
+
+func (s *Session) ScanRow(r csql.RowScanner) error { return csql.ScanInto(r, s) }
+
+func (s *Session) Fields() []any { return csql.ReflectFields(s) }
+
+// uuidCodec binds a UUID as its hex text
+var uuidCodec = csql.WithTypeCodec(
+	func(b []byte) (UUID, error) {
+		var u UUID
+		if b == nil {
+			return u, nil
+		}
+		if n, err := hex.Decode(u[:], b); err != nil || n != len(u) {
+			return u, fmt.Errorf("bad uuid %q", b)
+		}
+		return u, nil
+	},
+	func(u UUID) (driver.Value, error) { return hex.EncodeToString(u[:]), nil },
+)
+
+func TestTypeCodec(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE sessions (id TEXT, user TEXT)")
+	m := csql.NewSQLTableManager[Session](db, uuidCodec)
+	ctx := context.Background()
+	id := UUID{0xde, 0xad, 0xbe, 0xef, 15: 1}
+	if _, err := m.Transaction("INSERT INTO sessions (id, user) VALUES (?, ?)", []Session{{id, "ada"}}); err != nil {
+		t.Fatal(err)
+	}
+	var stored string
+	if err := db.QueryRow("SELECT id FROM sessions").Scan(&stored); err != nil || stored != "deadbeef000000000000000000000001" {
+		t.Fatalf("stored id %q, %v, want the codec's hex", stored, err)
+	}
+	got, err := m.QueryRowContext(ctx, "SELECT id, user FROM sessions WHERE id = ?", id)
+	if err != nil || got != (Session{id, "ada"}) {
+		t.Fatalf("QueryRow = %v, %v, want the session round-tripped", got, err)
+	}
+	mustExec(t, db, "INSERT INTO sessions (id, user) VALUES (NULL, 'anon'), ('zz', 'bad')")
+	if got, err := m.QueryRowContext(ctx, "SELECT id, user FROM sessions WHERE user = 'anon'"); err != nil || got != (Session{User: "anon"}) {
+		t.Fatalf("QueryRow of a NULL id = %v, %v, want the codec's zero", got, err)
+	}
+	if _, err := m.QueryRowContext(ctx, "SELECT id, user FROM sessions WHERE user = 'bad'"); err == nil || !strings.Contains(err.Error(), `bad uuid "zz"`) {
+		t.Fatalf("QueryRow of a bad id = %v, want the codec's error", err)
+	}
+	if got := constructPanic(func() { csql.NewSQLTableManager[Session](db, csql.WithTypeCodec[UUID](nil, nil)) }); !strings.Contains(got, "must not be nil") {
+		t.Fatalf("NewSQLTableManager panicked with %q, want the nil funcs rejected", got)
+	}
+	// without the codec, UUID is neither scanned nor bound
+	if _, err := csql.NewSQLTableManager[Session](db).QueryRowContext(ctx, "SELECT id, user FROM sessions WHERE user = 'ada'"); err == nil {
+		t.Fatal("QueryRow into a UUID without its codec succeeded")
+	}
+}
@@ -0,0 +1,320 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ColumnCountError is returned when a query yields a different number of
+// columns than a Columner Schema declares
+type ColumnCountError struct {
+	Schema int
+	Query  int
+}
+
+func (e *ColumnCountError) Error() string {
+	return fmt.Sprintf("csql: schema expects %d columns, query returned %d", e.Schema, e.Query)
+}
+
+// schemaColumns returns the column names the Schema declares, or nil when
+// it does not implement Columner and results are scanned as they come.
+// Under WithOptionalColumns the names of Schemas scanning through their
+// reflection plan come from columns
+func (m *SQLTableManager[T, R]) schemaColumns() []string {
+	if c, ok := any(R(new(T))).(Columner); ok {
+		return c.Columns()
+	}
+	if len(m.opts.optionalColumns) > 0 && scansByPlan[T, R]() {
+		return m.columns()
+	}
+	return nil
+}
+
+var planScanners sync.Map // reflect.Type -> bool
+
+// scansByPlan reports whether the ScanRow of the Schema R scans the fields
+// of T's reflection plan in order, as ScanInto does, which a zero row is
+// probed for once per type. Schemas scanning their columns otherwise, as
+// Joined does, are not named by the plan
+func scansByPlan[T any, R Schema[T]]() bool {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if ok, found := planScanners.Load(t); found {
+		return ok.(bool)
+	}
+	plan := planOf(t)
+	var row T
+	var probe planProbe
+	ok := probe.scan(R(&row)) && len(probe.dest) == len(plan)
+	rv := reflect.ValueOf(&row).Elem()
+	for i := 0; ok && i < len(plan); i++ {
+		fv, err := rv.FieldByIndexErr(plan[i].index)
+		d := reflect.ValueOf(probe.dest[i])
+		ok = err == nil && d.Kind() == reflect.Pointer && d.Type().Elem() == fv.Type() && d.Pointer() == fv.Addr().Pointer()
+	}
+	planScanners.Store(t, ok)
+	return ok
+}
+
+// planProbe is the RowScanner of scansByPlan, keeping the destinations a
+// ScanRow passes it. It claims a codec for every type, so ScanInto passes
+// the fields themselves rather than temporaries
+type planProbe struct {
+	dest []any
+}
+
+var errProbed = errors.New("csql: probed")
+
+func (p *planProbe) Scan(dest ...any) error {
+	p.dest = dest
+	return errProbed
+}
+
+func (p *planProbe) hasCodec(reflect.Type) bool { return true }
+
+// scan runs the ScanRow of s on p, reporting whether it scanned
+func (p *planProbe) scan(s interface{ ScanRow(RowScanner) error }) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return errors.Is(s.ScanRow(p), errProbed)
+}
+
+// WithOptionalColumns lets queries leave out columns of the Schema, such as
+// columns a rolling migration has yet to add. When a result lacks some of
+// columns, matched case-insensitively, and has the Schema's other columns,
+// its columns are scanned by name and the destinations of the missing ones
+// are not scanned, so their fields keep the zero value of a new row.
+// Results lacking any other column still fail with a *ColumnCountError.
+// Schemas not implementing Columner whose ScanRow is ScanInto are named by
+// their csql tags, or field names, in field order; other Schemas not
+// implementing Columner, such as the Joined of QueryJoined, are unaffected
+func WithOptionalColumns(columns ...string) Option {
+	return func(o *options) error {
+		if len(columns) == 0 {
+			return fmt.Errorf("csql: WithOptionalColumns requires at least one column")
+		}
+		o.optionalColumns = append(o.optionalColumns, columns...)
+		return nil
+	}
+}
+
+// ResultColumns returns the names and database types of the columns query
+// yields without fetching its rows, for tools inferring a result schema.
+// query is run as a derived table limited to no rows, so it must be a
+// single SELECT
+func (m *SQLTableManager[T, R]) ResultColumns(ctx context.Context, query string, args ...any) (cols []*sql.ColumnType, err error) {
+	query = "SELECT * FROM (" + strings.TrimRight(query, "; \t\r\n") + ") csql_columns LIMIT 0"
+	defer m.opts.annotate(&err, "Query", query)
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	query = m.opts.finalize(ctx, query, args)
+	args = m.opts.convertArgs(args)
+	ctx, start := m.opts.begin(ctx, OpQuery, query, args)
+	err = m.opts.retry(ctx, false, func() error {
+		return m.read(ctx, func(q querier) error {
+			rows, err := q.QueryContext(ctx, query, args...)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			if cols, err = rows.ColumnTypes(); err != nil {
+				return err
+			}
+			return rows.Close()
+		})
+	})
+	if m.opts.observed() {
+		m.opts.observe(ctx, OpQuery, query, args, start, 0, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cols, nil
+}
+
+// ColumnInfo describes a result column. The OK flags are false for the
+// attributes the driver does not report
+type ColumnInfo struct {
+	Name string
+	// DatabaseType is the database type name, such as VARCHAR or INT4,
+	// empty when the driver does not report it
+	DatabaseType string
+	// ScanType is a Go type suitable for scanning the column into
+	ScanType reflect.Type
+
+	Nullable   bool
+	NullableOK bool
+	// Length is the length of variable length text and binary types
+	Length   int64
+	LengthOK bool
+
+	Precision int64
+	Scale     int64
+	DecimalOK bool
+}
+
+// Describe returns the ColumnInfo of each column query yields, without
+// fetching its rows, see ResultColumns
+func (m *SQLTableManager[T, R]) Describe(ctx context.Context, query string, args ...any) ([]ColumnInfo, error) {
+	types, err := m.ResultColumns(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]ColumnInfo, len(types))
+	for i, t := range types {
+		c := ColumnInfo{Name: t.Name(), DatabaseType: t.DatabaseTypeName(), ScanType: t.ScanType()}
+		c.Nullable, c.NullableOK = t.Nullable()
+		c.Length, c.LengthOK = t.Length()
+		c.Precision, c.Scale, c.DecimalOK = t.DecimalSize()
+		cols[i] = c
+	}
+	return cols, nil
+}
+
+// DescribeTable is Describe for every column of the WithTable table
+func (m *SQLTableManager[T, R]) DescribeTable(ctx context.Context) ([]ColumnInfo, error) {
+	if m.opts.table == "" {
+		return nil, ErrNoTable
+	}
+	return m.Describe(ctx, "SELECT * FROM "+m.opts.table)
+}
+
+// columnScanner returns the RowScanner to scan the results of rows through
+// for a Schema declaring names. It rejects a different column count, and
+// when the query selects the same columns in another order it reorders
+// them into Schema order. Otherwise the columns are scanned by position.
+// Under WithOptionalColumns a query missing some of those columns is
+// scanned by name, leaving their destinations unscanned
+func (o *options) columnScanner(rows *sql.Rows, names []string) (RowScanner, error) {
+	if names == nil {
+		return rows, nil
+	}
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if len(cols) != len(names) {
+		if s, ok := o.optionalScanner(rows, cols, names); ok {
+			return s, nil
+		}
+		return nil, &ColumnCountError{Schema: len(names), Query: len(cols)}
+	}
+	order := make([]int, len(cols))
+	taken := make([]bool, len(names))
+	reordered := false
+	for i, col := range cols {
+		j := indexFold(names, col)
+		if j < 0 || taken[j] {
+			return rows, nil
+		}
+		taken[j] = true
+		order[i] = j
+		reordered = reordered || j != i
+	}
+	if !reordered {
+		return rows, nil
+	}
+	return reorderScanner{rows: rows, order: order, width: len(names)}, nil
+}
+
+// optionalScanner returns the reorderScanner scanning the columns cols of
+// rows by name into a Schema declaring names, or false unless cols are
+// names less some WithOptionalColumns columns
+func (o *options) optionalScanner(rows *sql.Rows, cols, names []string) (RowScanner, bool) {
+	if len(o.optionalColumns) == 0 || len(cols) > len(names) {
+		return nil, false
+	}
+	order := make([]int, len(cols))
+	taken := make([]bool, len(names))
+	for i, col := range cols {
+		j := indexFold(names, col)
+		if j < 0 || taken[j] {
+			return nil, false
+		}
+		taken[j] = true
+		order[i] = j
+	}
+	for j, name := range names {
+		if !taken[j] && indexFold(o.optionalColumns, name) < 0 {
+			return nil, false
+		}
+	}
+	return reorderScanner{rows: rows, order: order, width: len(names)}, true
+}
+
+func indexFold(names []string, name string) int {
+	for i, n := range names {
+		if strings.EqualFold(n, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// reorderScanner scans query column i into the Schema destination
+// order[i], of width destinations. Destinations no column is ordered into
+// are left unscanned
+type reorderScanner struct {
+	rows  *sql.Rows
+	order []int
+	width int
+}
+
+// Columns returns the query's columns in Schema order, as Scan fills them,
+// with "" for destinations left unscanned
+func (s reorderScanner) Columns() ([]string, error) {
+	cols, err := s.rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	ordered := make([]string, s.width)
+	for i, j := range s.order {
+		ordered[j] = cols[i]
+	}
+	return ordered, nil
+}
+
+func (s reorderScanner) Scan(dest ...any) error {
+	if len(dest) != s.width {
+		return s.rows.Scan(dest...)
+	}
+	ordered := make([]any, len(s.order))
+	for i, j := range s.order {
+		ordered[i] = dest[j]
+	}
+	return s.rows.Scan(ordered...)
+}
+
+// queryFirst scans the first row of query into box through the Schema's
+// column names. It stands in for QueryRow, whose *sql.Row hides its columns
+func (m *SQLTableManager[T, R]) queryFirst(ctx context.Context, q querier, query string, args []any, box *T, names []string) error {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	scanner, err := m.opts.columnScanner(rows, names)
+	if err != nil {
+		return err
+	}
+	if err := m.scanRow(scanner, box); err != nil {
+		return err
+	}
+	return rows.Close()
+}
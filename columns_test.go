@@ -0,0 +1,219 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// NamedItem is Item declaring its columns
+type NamedItem struct {
+	ID   int64
+	Name string
+}
+
+func (i *NamedItem) ScanRow(s csql.RowScanner) error { return s.Scan(&i.ID, &i.Name) }
+
+func (i *NamedItem) Fields() []any { return []any{i.ID, i.Name} }
+
+func (i *NamedItem) Columns() []string { return []string{"id", "name"} }
+
+func TestColumnCountMismatch(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 2)
+	m := csql.NewSQLTableManager[NamedItem](db)
+	tests := []struct {
+		name  string
+		query string
+		want  csql.ColumnCountError
+	}{
+		{"under", "SELECT id FROM items", csql.ColumnCountError{Schema: 2, Query: 1}},
+		{"over", "SELECT id, name, id FROM items", csql.ColumnCountError{Schema: 2, Query: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ce *csql.ColumnCountError
+			if _, err := m.Query(tt.query); !errors.As(err, &ce) || *ce != tt.want {
+				t.Fatalf("Query = %v, want %v", err, &tt.want)
+			}
+			if _, err := m.QueryRow(tt.query); !errors.As(err, &ce) || *ce != tt.want {
+				t.Fatalf("QueryRow = %v, want %v", err, &tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnReorder(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 1)
+	m := csql.NewSQLTableManager[NamedItem](db)
+	row, err := m.QueryRow("SELECT name, id FROM items")
+	if err != nil || row != (NamedItem{ID: 1, Name: "item1"}) {
+		t.Fatalf("QueryRow = %v, %v", row, err)
+	}
+	mustExec(t, db, "INSERT INTO items (id, name) VALUES (2, 'item2'), (3, 'item3')")
+	rows, err := m.Query("SELECT name AS NAME, id FROM items ORDER BY id DESC")
+	if err != nil || !slices.Equal(rows, []NamedItem{{3, "item3"}, {2, "item2"}, {1, "item1"}}) {
+		t.Fatalf("Query = %v, %v, want the fields by name", rows, err)
+	}
+	// without Columns the scan stays positional
+	if _, err := csql.NewSQLTableManager[Item](db).Query("SELECT name, id FROM items"); err == nil {
+		t.Fatal("positional scan of a name into an int64 succeeded")
+	}
+}
+
+// Tagged is a reflected Schema whose tags name its columns
+type Tagged struct {
+	ID    int64  `csql:"id"`
+	Name  string `csql:"name"`
+	Notes string `csql:"notes"`
+}
+
+func (g *Tagged) ScanRow(r csql.RowScanner) error { return csql.ScanInto(r, g) }
+
+func (g *Tagged) Fields() []any { return csql.ReflectFields(g) }
+
+func TestOptionalColumns(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 1)
+	t.Run("columner", func(t *testing.T) {
+		m := csql.NewSQLTableManager[NamedItem](db, csql.WithOptionalColumns("name"))
+		row, err := m.QueryRow("SELECT id FROM items")
+		if err != nil || row != (NamedItem{ID: 1}) {
+			t.Fatalf("QueryRow = %v, %v, want name zeroed", row, err)
+		}
+	})
+	t.Run("reflected", func(t *testing.T) {
+		m := csql.NewSQLTableManager[Tagged](db, csql.WithOptionalColumns("notes"))
+		rows, err := m.Query("SELECT name, id FROM items")
+		if err != nil || len(rows) != 1 || rows[0] != (Tagged{ID: 1, Name: "item1"}) {
+			t.Fatalf("Query = %v, %v, want notes zeroed", rows, err)
+		}
+		var ce *csql.ColumnCountError
+		if _, err := m.Query("SELECT id FROM items"); !errors.As(err, &ce) {
+			t.Fatalf("Query missing a required column = %v, want a *ColumnCountError", err)
+		}
+	})
+}
+
+// Adaptive is a Schema scanning notes only when the result has that column
+type Adaptive struct {
+	ID      int64
+	Name    string
+	Notes   string
+	columns bool
+}
+
+func (a *Adaptive) ScanRow(s csql.RowScanner) error {
+	c, ok := s.(csql.ColumnScanner)
+	if a.columns = ok; !ok {
+		return s.Scan(&a.ID, &a.Name)
+	}
+	cols, err := c.Columns()
+	if err != nil {
+		return err
+	}
+	if slices.Contains(cols, "notes") {
+		return s.Scan(&a.ID, &a.Name, &a.Notes)
+	}
+	return s.Scan(&a.ID, &a.Name)
+}
+
+func (a *Adaptive) Fields() []any { return []any{a.ID, a.Name, a.Notes} }
+
+func TestColumnScanner(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 1)
+	m := csql.NewSQLTableManager[Adaptive](db)
+	if rows, err := m.Query("SELECT id, name FROM items"); err != nil || len(rows) != 1 || rows[0] != (Adaptive{ID: 1, Name: "item1", columns: true}) {
+		t.Fatalf("Query = %+v, %v, want two columns scanned", rows, err)
+	}
+	if rows, err := m.Query("SELECT id, name, 'note' AS notes FROM items"); err != nil || len(rows) != 1 || rows[0] != (Adaptive{1, "item1", "note", true}) {
+		t.Fatalf("Query = %+v, %v, want notes scanned", rows, err)
+	}
+	// QueryRow hides the columns
+	if row, err := m.QueryRow("SELECT id, name FROM items"); err != nil || row != (Adaptive{ID: 1, Name: "item1"}) {
+		t.Fatalf("QueryRow = %+v, %v, want a plain RowScanner", row, err)
+	}
+}
+
+// NamedProbe is Probe scanned by column name
+type NamedProbe struct{ Probe }
+
+func (*NamedProbe) Columns() []string { return []string{"id", "name"} }
+
+func TestColumnScannerReordered(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 1)
+	rows, err := csql.NewSQLTableManager[NamedProbe](db).Query("SELECT name, id FROM items")
+	if err != nil || len(rows) != 1 || rows[0].ID != 1 {
+		t.Fatal(rows, err)
+	}
+	if !slices.Equal(rows[0].cols, []string{"id", "name"}) {
+		t.Fatalf("ScanRow saw columns %q, want them in Schema order", rows[0].cols)
+	}
+}
+
+func TestResultColumns(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	seedItems(t, db, 3)
+	m := csql.NewSQLTableManager[Item](db)
+	cols, err := m.ResultColumns(context.Background(), "SELECT id, name FROM items WHERE id > ?;", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sqlite derives scan types from row values, so without rows only the
+	// declared types are known
+	var got []string
+	for _, c := range cols {
+		got = append(got, c.Name()+" "+c.DatabaseTypeName())
+	}
+	if want := []string{"id INTEGER", "name TEXT"}; !slices.Equal(got, want) {
+		t.Fatalf("ResultColumns = %q, want %q", got, want)
+	}
+	stmts := statementsAfterSetup(rec)
+	if last := stmts[len(stmts)-1].SQL; last != "SELECT * FROM (SELECT id, name FROM items WHERE id > ?) csql_columns LIMIT 0" {
+		t.Fatalf("sent %q, want the query limited to no rows", last)
+	}
+	if _, err := m.ResultColumns(context.Background(), "SELECT nope FROM items"); err == nil {
+		t.Fatal("ResultColumns of an unknown column succeeded")
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE gadgets (id INTEGER PRIMARY KEY, label VARCHAR(20) NOT NULL, price DECIMAL(8, 2), data BLOB, made DATETIME, ok BOOLEAN)")
+	m := csql.NewSQLTableManager[Item](db, csql.WithTable("gadgets"))
+	ctx := context.Background()
+	cols, err := m.DescribeTable(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []csql.ColumnInfo{
+		{Name: "id", DatabaseType: "INTEGER"},
+		{Name: "label", DatabaseType: "VARCHAR(20)"},
+		{Name: "price", DatabaseType: "DECIMAL(8, 2)"},
+		{Name: "data", DatabaseType: "BLOB"},
+		{Name: "made", DatabaseType: "DATETIME"},
+		{Name: "ok", DatabaseType: "BOOLEAN"},
+	}
+	if len(cols) != len(want) {
+		t.Fatalf("DescribeTable = %+v, want %d columns", cols, len(want))
+	}
+	for i, c := range cols {
+		// sqlite reports neither lengths nor decimal sizes
+		if c.Name != want[i].Name || c.DatabaseType != want[i].DatabaseType || c.LengthOK || c.DecimalOK {
+			t.Errorf("column %d = %+v, want %+v", i, c, want[i])
+		}
+	}
+	cols, err = m.Describe(ctx, "SELECT label, price * 2 AS doubled FROM gadgets WHERE id = ?", 1)
+	if err != nil || len(cols) != 2 || cols[0].Name != "label" || cols[1].Name != "doubled" || cols[1].DatabaseType != "" {
+		t.Fatalf("Describe = %+v, %v, want an expression column without a declared type", cols, err)
+	}
+	if _, err := csql.NewSQLTableManager[Item](db).DescribeTable(ctx); !errors.Is(err, csql.ErrNoTable) {
+		t.Fatalf("DescribeTable without a table = %v, want ErrNoTable", err)
+	}
+}
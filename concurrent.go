@@ -0,0 +1,165 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// WithConcurrency caps the number of statements the manager's fan-out
+// helpers, such as QueryConcurrent, run at once
+func WithConcurrency(n int) Option {
+	return func(o *options) error {
+		if n <= 0 {
+			return fmt.Errorf("csql: concurrency must be positive, got %d", n)
+		}
+		o.concurrency = n
+		return nil
+	}
+}
+
+// QueryConcurrent runs each query on its own goroutine, at most
+// WithConcurrency at a time, and returns their rows in input order. The
+// first failure cancels the queries still running and is returned
+func (m *SQLTableManager[T, R]) QueryConcurrent(ctx context.Context, queries []string) ([][]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	limit := m.opts.concurrency
+	if limit <= 0 || limit > len(queries) {
+		limit = len(queries)
+	}
+	results := make([][]T, len(queries))
+	sem := make(chan struct{}, limit)
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+dispatch:
+	for i, query := range queries {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+		wg.Add(1)
+		go func(i int, query string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			rows, err := m.QueryContext(ctx, query)
+			if err != nil {
+				once.Do(func() {
+					first = fmt.Errorf("csql: query %d: %w", i, err)
+					cancel()
+				})
+				return
+			}
+			results[i] = rows
+		}(i, query)
+	}
+	wg.Wait()
+	if first != nil {
+		return nil, first
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ForEach streams the rows of query to fn, running at most concurrency
+// calls at once and reading the next row only once a call is free. The
+// first failure, of fn or of the query, cancels the ctx of the calls still
+// running and stops reading, and is returned once they have ended. The
+// query holds its connection until its last row is handed to fn
+func (m *SQLTableManager[T, R]) ForEach(ctx context.Context, query string, concurrency int, fn func(ctx context.Context, row T) error, args ...any) error {
+	if concurrency <= 0 {
+		return fmt.Errorf("csql: concurrency must be positive, got %d", concurrency)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sem := make(chan struct{}, concurrency)
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			first = err
+			cancel()
+		})
+	}
+	names := m.schemaColumns()
+	var scanner RowScanner
+	err := m.queryEach(ctx, query, args, func(queryRows *sql.Rows) (err error) {
+		if scanner == nil {
+			if scanner, err = m.opts.columnScanner(queryRows, names); err != nil {
+				return err
+			}
+		}
+		var row T
+		if err := m.scanRow(scanner, &row); err != nil {
+			return err
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(ctx, row); err != nil {
+				fail(err)
+			}
+		}()
+		return nil
+	})
+	if err != nil {
+		fail(err)
+	}
+	wg.Wait()
+	return first
+}
+
+// TransactionParallel splits rows into up to workers contiguous shards and
+// runs each as its own Transaction, on its own connection, concurrently.
+// Atomicity is per shard, not global: a failed shard rolls back alone while
+// the others may commit. Failures are joined, each naming its shard, and
+// cancelling ctx aborts the shards still running. With workers <= 1 it is
+// Transaction. Databases serializing writers, such as sqlite, gain nothing
+// from it beyond overlapping the Go side of the shards
+func (m *SQLTableManager[T, R]) TransactionParallel(transaction string, rows []T, workers int) error {
+	return m.TransactionParallelContext(context.Background(), transaction, rows, workers)
+}
+
+// TransactionParallelContext is TransactionParallel bound to ctx
+func (m *SQLTableManager[T, R]) TransactionParallelContext(ctx context.Context, transaction string, rows []T, workers int) error {
+	if workers <= 1 {
+		_, err := m.TransactionContext(ctx, transaction, rows)
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	size := (len(rows) + workers - 1) / workers
+	shards := (len(rows) + size - 1) / size
+	errs := make([]error, shards)
+	var wg sync.WaitGroup
+	for i := 0; i < shards; i++ {
+		shard := rows[i*size : min((i+1)*size, len(rows))]
+		wg.Add(1)
+		go func(i int, shard []T) {
+			defer wg.Done()
+			if _, err := m.TransactionContext(ctx, transaction, shard); err != nil {
+				errs[i] = fmt.Errorf("csql: shard %d: %w", i, err)
+			}
+		}(i, shard)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
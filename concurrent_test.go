@@ -0,0 +1,200 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+// items returns items 1 to n
+func items(n int) []Item {
+	rows := make([]Item, n)
+	for i := range rows {
+		rows[i] = Item{ID: int64(i + 1), Name: fmt.Sprintf("item%d", i+1)}
+	}
+	return rows
+}
+
+// countItems returns the number of stored items
+func countItems(t testing.TB, m *csql.SQLTableManager[Item, *Item]) int {
+	t.Helper()
+	rows, err := m.Query("SELECT id, name FROM items")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(rows)
+}
+
+func TestTransactionParallel(t *testing.T) {
+	tests := []struct {
+		rows, workers int
+	}{
+		{0, 4},
+		{1, 4},
+		{5, 4},
+		{7, 3},
+		{10, 10},
+		{3, 8},
+		{4, 1},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d rows %d workers", tt.rows, tt.workers), func(t *testing.T) {
+			m := csql.NewSQLTableManager[Item](openFileDB(t))
+			if err := m.TransactionParallel(insertItem, items(tt.rows), tt.workers); err != nil {
+				t.Fatal(err)
+			}
+			if n := countItems(t, m); n != tt.rows {
+				t.Fatalf("stored %d rows, want %d", n, tt.rows)
+			}
+		})
+	}
+}
+
+func TestTransactionParallelShardFails(t *testing.T) {
+	db := openFileDB(t)
+	m := csql.NewSQLTableManager[Item](db)
+	rows := items(6)
+	mustExec(t, db, "INSERT INTO items (id, name) VALUES (5, 'taken')")
+	err := m.TransactionParallel(insertItem, rows, 3)
+	if err == nil || !strings.Contains(err.Error(), "shard 2") {
+		t.Fatalf("TransactionParallel = %v, want shard 2 to fail", err)
+	}
+	// shards 0 and 1 commit, shard 2 rolls back leaving only the taken row
+	if n := countItems(t, m); n != 5 {
+		t.Fatalf("stored %d rows, want 5", n)
+	}
+}
+
+func TestTransactionParallelCanceled(t *testing.T) {
+	m := csql.NewSQLTableManager[Item](openFileDB(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.TransactionParallelContext(ctx, insertItem, items(8), 4); !errors.Is(err, context.Canceled) {
+		t.Fatalf("TransactionParallelContext = %v, want context.Canceled", err)
+	}
+	if n := countItems(t, m); n != 0 {
+		t.Fatalf("stored %d rows, want 0", n)
+	}
+}
+
+func BenchmarkTransactionParallel(b *testing.B) {
+	rows := items(1000)
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers %d", workers), func(b *testing.B) {
+			db := openFileDB(b)
+			m := csql.NewSQLTableManager[Item](db)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				mustExec(b, db, "DELETE FROM items")
+				b.StartTimer()
+				if err := m.TransactionParallel(insertItem, rows, workers); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestForEach(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 100)
+	m := csql.NewSQLTableManager[Item](db)
+	fail := errors.New("row 50")
+	var calls, canceled atomic.Int32
+	err := m.ForEach(context.Background(), "SELECT id, name FROM items ORDER BY id", 4, func(ctx context.Context, row Item) error {
+		calls.Add(1)
+		if row.ID == 50 {
+			return fail
+		}
+		if row.ID > 50 {
+			<-ctx.Done()
+			canceled.Add(1)
+		}
+		return nil
+	})
+	if !errors.Is(err, fail) {
+		t.Fatalf("ForEach = %v, want the error of row 50", err)
+	}
+	if n := calls.Load(); n >= 100 {
+		t.Fatalf("fn ran for %d rows, want the rows after the failure skipped", n)
+	}
+	if calls.Load() > 50 && canceled.Load() == 0 {
+		t.Fatal("rows dispatched after the failure did not see their context canceled")
+	}
+}
+
+// peakMetrics is a Metrics keeping the most operations in flight at once
+type peakMetrics struct {
+	inFlight, peak atomic.Int32
+}
+
+func (p *peakMetrics) ObserveQuery(string, string, time.Duration, int, error) {}
+
+func (p *peakMetrics) AddInFlight(_ string, delta int) {
+	n := p.inFlight.Add(int32(delta))
+	for peak := p.peak.Load(); n > peak && !p.peak.CompareAndSwap(peak, n); peak = p.peak.Load() {
+	}
+}
+
+func TestQueryConcurrent(t *testing.T) {
+	db := openFileDB(t)
+	seedItems(t, db, 3)
+	var mx peakMetrics
+	m := csql.NewSQLTableManager[SlowItem](db, csql.WithConcurrency(2), csql.WithMetrics(&mx))
+	queries := []string{
+		"SELECT id, name FROM items WHERE id = 3",
+		"SELECT id, name FROM items WHERE id >= 2 ORDER BY id",
+		"SELECT id, name FROM items WHERE id = 1",
+	}
+	got, err := m.QueryConcurrent(context.Background(), queries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != "[[{{3 item3}}] [{{2 item2}} {{3 item3}}] [{{1 item1}}]]" {
+		t.Fatalf("QueryConcurrent = %v, want the results in query order", got)
+	}
+	if p := mx.peak.Load(); p != 2 {
+		t.Fatalf("%d queries ran at once, want 2", p)
+	}
+}
+
+func TestQueryConcurrentFails(t *testing.T) {
+	// the outer queries hang until canceled, so only the failure of the
+	// middle one can end them
+	db, _ := openRecorded(t, func(ctx context.Context, query string) bool {
+		if strings.Contains(query, "hang") {
+			<-ctx.Done()
+		}
+		return false
+	})
+	db.SetMaxOpenConns(3)
+	m := csql.NewSQLTableManager[Item](db)
+	queries := []string{
+		"SELECT id, name FROM items /* hang */",
+		"SELECT id, name FROM missing",
+		"SELECT id, name FROM items /* hang */",
+	}
+	done := make(chan error, 1)
+	go func() {
+		got, err := m.QueryConcurrent(context.Background(), queries)
+		if got != nil {
+			err = fmt.Errorf("got results %v", got)
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "query 1") || !strings.Contains(err.Error(), "no such table") {
+			t.Fatalf("QueryConcurrent = %v, want the middle query's error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("QueryConcurrent did not cancel the hanging queries")
+	}
+}
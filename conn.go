@@ -0,0 +1,42 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// conn is the part of *sql.DB a manager runs statements through, also
+// implemented by the *sql.Conn of WithConn
+type conn interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	PingContext(ctx context.Context) error
+}
+
+// WithConn takes a single connection from the pool and calls fn with a copy
+// of the manager running every statement on it, returning the connection
+// once fn does. Session state such as SET LOCAL, advisory locks, and
+// temporary tables is thus shared across fn. Reads within fn bypass the
+// WithQueryCache cache and WithSingleflight, and Close releases nothing.
+// The manager must not be used after fn returns. A manager from Bind
+// already runs on a single connection and passes itself
+func (m *SQLTableManager[T, R]) WithConn(ctx context.Context, fn func(conn *SQLTableManager[T, R]) error) error {
+	if m.group != nil {
+		return fn(m)
+	}
+	c, err := m.pool.Conn(ctx)
+	if err != nil {
+		m.opts.annotate(&err, "WithConn", "")
+		return err
+	}
+	defer c.Close()
+	pinned := *m
+	pinned.db = c
+	pinned.pinned = true
+	pinned.opts.closeDB = false
+	pinned.opts.explainDB = nil
+	return fn(&pinned)
+}
@@ -0,0 +1,64 @@
+package csql_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// openRecordedFile is openRecorded over a shared sqlite file, so that a
+// pool of up to conns connections sees one database
+func openRecordedFile(t *testing.T, conns int) (*sql.DB, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	db := sql.OpenDB(recConnector{dsn: "file:" + filepath.Join(t.TempDir(), "csql.db"), rec: rec})
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(conns)
+	mustExec(t, db, "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)")
+	return db, rec
+}
+
+func TestWithConn(t *testing.T) {
+	ctx := context.Background()
+	db, rec := openRecordedFile(t, 2)
+	m := csql.NewSQLTableManager[Item](db)
+	const outside = "SELECT id, name FROM scratch"
+	before := len(rec.Stmts())
+	err := m.WithConn(ctx, func(conn *csql.SQLTableManager[Item, *Item]) error {
+		if err := conn.Exec("CREATE TEMP TABLE scratch (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+			return err
+		}
+		if _, err := conn.Transaction("INSERT INTO scratch (id, name) VALUES (?, ?)", items(2)); err != nil {
+			return err
+		}
+		rows, err := conn.Query("SELECT id, name FROM scratch ORDER BY id")
+		if err != nil || !slices.Equal(rows, items(2)) {
+			t.Errorf("Query of the temp table = %v, %v", rows, err)
+		}
+		// the pool's other connection has no such table
+		if _, err := m.Query(outside); err == nil || !strings.Contains(err.Error(), "no such table") {
+			t.Errorf("Query of the temp table outside the connection = %v, want no such table", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inside := map[int]bool{}
+	other := 0
+	for _, s := range rec.Stmts()[before:] {
+		if s.SQL == outside {
+			other = s.Conn
+		} else {
+			inside[s.Conn] = true
+		}
+	}
+	if len(inside) != 1 || inside[other] {
+		t.Fatalf("fn ran on connections %v and the pool on %d, want fn on one of its own", inside, other)
+	}
+}
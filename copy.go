@@ -0,0 +1,94 @@
+package csql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CopyFrom bulk loads rows into table with the Postgres COPY protocol,
+// sending each row's Fields for columns, in order, within a database
+// transaction, and returns the number of rows copied. It follows the CopyIn
+// convention of lib/pq, preparing COPY ... FROM STDIN and executing it once
+// per row and once more without arguments to flush, so the driver must
+// support it. table and columns are spliced into the SQL and must not come
+// from user input. Other dialects get an error
+func (m *SQLTableManager[T, R]) CopyFrom(ctx context.Context, table string, columns []string, rows []T) (copied int64, err error) {
+	if m.opts.dialect != Postgres {
+		return 0, fmt.Errorf("csql: COPY is not supported by the %s dialect", m.opts.dialect)
+	}
+	if len(columns) == 0 {
+		return 0, errors.New("csql: COPY requires at least one column")
+	}
+	query := "COPY " + table + " (" + strings.Join(columns, ", ") + ") FROM STDIN"
+	defer m.opts.annotate(&err, "CopyFrom", query)
+	defer func() { m.opts.audit(ctx, "CopyFrom", copied, err) }()
+	if err := m.opts.writable(); err != nil {
+		return 0, err
+	}
+	for i, row := range rows {
+		if n := len(R(&row).Fields()); n != len(columns) {
+			return 0, fmt.Errorf("csql: row %d has %d fields for %d columns", i, n, len(columns))
+		}
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	if m.opts.dryRun != nil {
+		for _, row := range rows {
+			m.opts.dryRun(query, m.opts.sealFields(columns, R(&row).Fields()))
+		}
+		return 0, nil
+	}
+	var after func(*error)
+	if ctx, after, err = m.opts.beforeExec(ctx, "CopyFrom", query, nil, len(rows)); err != nil {
+		return 0, err
+	}
+	defer after(&err)
+	defer m.opts.invalidate()
+	if m.opts.observed() {
+		var start time.Time
+		ctx, start = m.opts.begin(ctx, OpTransaction, query, nil)
+		defer func() {
+			m.opts.observe(ctx, OpTransaction, query, nil, start, copied, err)
+		}()
+	}
+	return m.copyOnce(ctx, query, columns, rows)
+}
+
+// copyOnce runs the COPY statement of CopyFrom in a single database transaction
+func (m *SQLTableManager[T, R]) copyOnce(ctx context.Context, query string, columns []string, rows []T) (int64, error) {
+	tx, err := m.beginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, rollback(tx, err)
+	}
+	defer stmt.Close()
+	for i := range rows {
+		if err := ctx.Err(); err != nil {
+			return 0, rollback(tx, err)
+		}
+		if _, err := stmt.ExecContext(ctx, m.opts.sealFields(columns, R(&rows[i]).Fields())...); err != nil {
+			return 0, rollback(tx, err)
+		}
+	}
+	res, err := stmt.ExecContext(ctx)
+	if err != nil {
+		return 0, rollback(tx, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	// lib/pq reports the count of the COPY command on the flushing Exec
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		return n, nil
+	}
+	return int64(len(rows)), nil
+}
@@ -0,0 +1,77 @@
+package csql_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// Badge is a Schema binding its code through a codec
+type Badge struct {
+	ID   int64
+	Code Code
+}
+
+func (b *Badge) ScanRow(r csql.RowScanner) error { return csql.ScanInto(r, b) }
+
+func (b *Badge) Fields() []any { return csql.ReflectFields(b) }
+
+// stubCopy stubs the COPY statements sqlite lacks
+func stubCopy(_ context.Context, query string) bool {
+	return strings.HasPrefix(query, "PREPARE COPY")
+}
+
+// lowerCodes is a codec storing codes in lower case
+var lowerCodes = csql.WithTypeCodec(
+	func(b []byte) (Code, error) { return Code(strings.ToUpper(string(b))), nil },
+	func(c Code) (driver.Value, error) { return strings.ToLower(string(c)), nil },
+)
+
+func TestCopyFrom(t *testing.T) {
+	db, rec := openRecorded(t, stubCopy)
+	m := csql.NewSQLTableManager[Badge](db, csql.WithDialect(csql.Postgres), lowerCodes)
+	rows := []Badge{{1, "ABC"}, {2, "DEF"}}
+	n, err := m.CopyFrom(context.Background(), "badges", []string{"id", "code"}, rows)
+	if err != nil || n != 2 {
+		t.Fatalf("CopyFrom = %d, %v", n, err)
+	}
+	var copies []string
+	for _, s := range rec.Stmts() {
+		if s.Args != nil || s.SQL == "COPY badges (id, code) FROM STDIN" {
+			copies = append(copies, fmt.Sprint(s.Args))
+		}
+	}
+	if got, want := strings.Join(copies, " "), "[1 abc] [2 def] []"; got != want {
+		t.Fatalf("copied %s, want %s, bound through the codec and flushed", got, want)
+	}
+	if rec.Count("COMMIT") != 1 {
+		t.Fatalf("statements %v, want a commit", rec.Stmts())
+	}
+}
+
+func TestCopyFromDryRun(t *testing.T) {
+	var captured []string
+	m := csql.NewSQLTableManager[Badge](openDB(t), csql.WithDialect(csql.Postgres), lowerCodes,
+		csql.WithDryRun(func(_ string, args []any) {
+			for _, arg := range args {
+				v, err := driver.DefaultParameterConverter.ConvertValue(arg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				captured = append(captured, fmt.Sprint(v))
+			}
+		}))
+	if _, err := m.CopyFrom(context.Background(), "badges", []string{"id", "code"}, []Badge{{1, "ABC"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(captured, " "); got != "1 abc" {
+		t.Fatalf("captured %s, want the args as bound", got)
+	}
+	if _, err := csql.NewSQLTableManager[Badge](openDB(t)).CopyFrom(context.Background(), "badges", []string{"id", "code"}, nil); err == nil {
+		t.Fatal("CopyFrom on sqlite = nil, want COPY rejected")
+	}
+}
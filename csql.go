@@ -1,14 +1,33 @@
 package csql
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
 )
 
+// ErrTooManyRows is returned when a query yields more rows than WithMaxRows allows
+var ErrTooManyRows = errors.New("csql: too many rows")
+
 // RowScanner manages column scanning (SQL data types)
 type RowScanner interface {
 	Scan(args ...any) error
 }
 
+// ColumnScanner is a RowScanner that also knows the result's column names,
+// in the order Scan fills them. Query passes one to ScanRow for results read
+// from the database, so a Schema may type-assert for it. QueryRow reads
+// through *sql.Row, which hides its columns, and passes a plain RowScanner
+// unless the Schema is a Columner, as do results served from the cache
+type ColumnScanner interface {
+	RowScanner
+	Columns() ([]string, error)
+}
+
 // Schema defines expected behaviors for SQL documents
 type Schema[T any] interface {
 	ScanRow(RowScanner) error
@@ -19,70 +38,701 @@ type Schema[T any] interface {
 // SQLTable manages a SQL table through a Schema definition
 type SQLTable[T any, R Schema[T]] interface {
 	// Query returns rows
-	Query(query string) ([]T, error)
+	Query(query string, args ...any) ([]T, error)
 	// QueryRow returns a single row
 	QueryRow(query string, args ...any) (T, error)
 	// Exec executes a query
 	Exec(query string, args ...any) error
 	// Transaction attempt the prepared transaction using the row fields
 	Transaction(transaction string, rows []T) (bool, error)
+	// QueryContext is Query bound to ctx
+	QueryContext(ctx context.Context, query string, args ...any) ([]T, error)
+	// QueryRowContext is QueryRow bound to ctx
+	QueryRowContext(ctx context.Context, query string, args ...any) (T, error)
+	// ExecContext is Exec bound to ctx
+	ExecContext(ctx context.Context, query string, args ...any) error
+	// TransactionContext is Transaction bound to ctx
+	TransactionContext(ctx context.Context, transaction string, rows []T) (bool, error)
 }
 
-type sqlTableManager[T any, R Schema[T]] struct {
-	db *sql.DB
+// SQLTableManager implements SQLTable over a *sql.DB. Create one with
+// NewSQLTableManager
+type SQLTableManager[T any, R Schema[T]] struct {
+	db   conn
+	pool *sql.DB
+	opts options
+	// withTrashed includes soft-deleted rows in generated reads
+	withTrashed bool
+	// pinned is set on the managers of WithConn and Bind, whose reads bypass
+	// the cache and singleflight as they may depend on session state
+	pinned bool
+	// group is the Tx of a manager from Bind
+	group *Tx
+}
+
+var _ SQLTable[nopSchema, *nopSchema] = (*SQLTableManager[nopSchema, *nopSchema])(nil)
+
+// NewSQLTableManager returns a SQLTableManager configured by opts.
+// It panics if any option is invalid, or if T is a struct whose columns
+// cannot be derived from its fields, see ScanInto
+func NewSQLTableManager[T any, R Schema[T]](db *sql.DB, opts ...Option) *SQLTableManager[T, R] {
+	o, err := newOptions(opts)
+	if err == nil {
+		err = checkSchema[T, R]()
+	}
+	if err != nil {
+		panic(err)
+	}
+	if o.explainSlow {
+		o.explainDB = db
+	}
+	o.redactColumns = redactedColumns[T, R]()
+	return &SQLTableManager[T, R]{
+		db:   db,
+		pool: db,
+		opts: o,
+	}
 }
 
-// NewSQLTableManager returns a SQLTableManager
-func NewSQLTableManager[T any, R Schema[T]](db *sql.DB) *sqlTableManager[T, R] {
-	return &sqlTableManager[T, R]{
-		db: db,
+// NewSQLTableManagerWithRetry pings db until it answers, trying up to
+// attempts times and waiting backoff after the first failure, doubled after
+// each one. It returns the manager once connected, or the last ping error
+func NewSQLTableManagerWithRetry[T any, R Schema[T]](ctx context.Context, db *sql.DB, attempts int, backoff time.Duration, opts ...Option) (*SQLTableManager[T, R], error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if attempts <= 0 {
+		return nil, fmt.Errorf("csql: connection attempts must be positive, got %d", attempts)
+	}
+	if err := checkSchema[T, R](); err != nil {
+		return nil, err
+	}
+	if o.explainSlow {
+		o.explainDB = db
+	}
+	o.redactColumns = redactedColumns[T, R]()
+	for attempt := 1; ; attempt++ {
+		err = db.PingContext(ctx)
+		if err == nil {
+			return &SQLTableManager[T, R]{db: db, pool: db, opts: o}, nil
+		}
+		if attempt == attempts {
+			return nil, fmt.Errorf("csql: database unreachable after %d attempts: %w", attempts, err)
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+		// doubling stops short of overflowing into a negative wait
+		if backoff <= math.MaxInt64/2 {
+			backoff *= 2
+		}
 	}
 }
 
-func (m *sqlTableManager[_, _]) Exec(query string, args ...interface{}) error {
-	_, err := m.db.Exec(query, args...)
+func (m *SQLTableManager[_, _]) Exec(query string, args ...interface{}) error {
+	return m.ExecContext(context.Background(), query, args...)
+}
+
+func (m *SQLTableManager[_, _]) ExecContext(ctx context.Context, query string, args ...any) error {
+	_, err := m.execAudited(ctx, "Exec", query, args)
 	return err
 }
 
-func (m *sqlTableManager[T, R]) Transaction(transaction string, rows []T) (bool, error) {
-	tx, err := m.db.Begin()
+// ExecNamedArgs is ExecContext for drivers that bind sql.Named parameters
+// natively. The query is sent without placeholder rewriting
+func (m *SQLTableManager[_, _]) ExecNamedArgs(ctx context.Context, query string, args ...sql.NamedArg) error {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg
+	}
+	return m.ExecContext(ctx, query, values...)
+}
+
+// execAudited is exec, reporting the statement to the audit hook as method
+func (m *SQLTableManager[_, _]) execAudited(ctx context.Context, method, query string, args []any) (sql.Result, error) {
+	res, err := m.exec(ctx, method, query, args)
+	m.opts.audit(ctx, method, rowsAffected(res, err), err)
+	return res, err
+}
+
+// exec runs query for method and returns its result, which is nil under WithDryRun
+func (m *SQLTableManager[_, _]) exec(ctx context.Context, method, query string, args []any) (res sql.Result, err error) {
+	defer m.opts.annotate(&err, "Exec", query)
+	if err := m.opts.writable(); err != nil {
+		return nil, err
+	}
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	query = m.opts.finalize(ctx, query, args)
+	args = m.opts.convertArgs(args)
+	if m.opts.dryRun != nil {
+		m.opts.dryRun(query, append([]any(nil), args...))
+		return nil, nil
+	}
+	ctx, after, err := m.opts.beforeExec(ctx, method, query, args, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer after(&err)
+	defer m.opts.invalidate()
+	ctx, start := m.opts.begin(ctx, OpExec, query, args)
+	err = m.opts.retryBusy(ctx, func() error {
+		return m.opts.retry(ctx, true, func() (err error) {
+			res, err = m.execDB(ctx, query, args)
+			return err
+		})
+	})
+	if m.opts.observed() {
+		m.opts.observe(ctx, OpExec, query, args, start, rowsAffected(res, err), err)
+	}
+	return res, err
+}
+
+// execDB executes query on the manager's connection, setting the statement
+// timeout under WithStatementTimeout and on a connection acquired under
+// WithAcquireTimeout
+func (m *SQLTableManager[_, _]) execDB(ctx context.Context, query string, args []any) (sql.Result, error) {
+	if !m.opts.setsStatementTimeout() || m.group != nil {
+		c, err := m.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			return m.db.ExecContext(ctx, query, args...)
+		}
+		defer c.Close()
+		return c.ExecContext(ctx, query, args...)
+	}
+	if !m.pinned {
+		return m.execSession(ctx, query, args)
+	}
+	tx, err := m.beginTx(ctx)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	stmt, err := tx.Prepare(transaction)
+	res, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
-		return false, err
+		return nil, rollback(tx, err)
+	}
+	return res, tx.Commit()
+}
+
+func (m *SQLTableManager[T, R]) Transaction(transaction string, rows []T) (bool, error) {
+	return m.TransactionContext(context.Background(), transaction, rows)
+}
+
+func (m *SQLTableManager[T, R]) TransactionContext(ctx context.Context, transaction string, rows []T) (bool, error) {
+	_, ok, err := m.transact(ctx, transaction, rows, nil, true)
+	return ok, err
+}
+
+// TransactionCount is Transaction, also returning the number of rows the
+// statements affected once committed, or -1 if the driver cannot tell
+func (m *SQLTableManager[T, R]) TransactionCount(transaction string, rows []T) (int64, bool, error) {
+	return m.TransactionCountContext(context.Background(), transaction, rows)
+}
+
+// TransactionCountContext is TransactionCount bound to ctx
+func (m *SQLTableManager[T, R]) TransactionCountContext(ctx context.Context, transaction string, rows []T) (int64, bool, error) {
+	return m.transact(ctx, transaction, rows, nil, true)
+}
+
+// TransactionFunc is Transaction, binding the arguments argsFn returns for
+// each row instead of its Fields. A nil argsFn binds Fields
+func (m *SQLTableManager[T, R]) TransactionFunc(transaction string, rows []T, argsFn func(*T) []any) (bool, error) {
+	return m.TransactionFuncContext(context.Background(), transaction, rows, argsFn)
+}
+
+// TransactionFuncContext is TransactionFunc bound to ctx
+func (m *SQLTableManager[T, R]) TransactionFuncContext(ctx context.Context, transaction string, rows []T, argsFn func(*T) []any) (bool, error) {
+	_, ok, err := m.transact(ctx, transaction, rows, argsFn, true)
+	return ok, err
+}
+
+// TransactionSorted is TransactionContext, executing the rows in the order
+// less defines rather than as given. Running every batch that touches the
+// same rows in one consistent order avoids lock ordering deadlocks between
+// them, though the database can still deadlock for other reasons. rows
+// itself is not reordered
+func (m *SQLTableManager[T, R]) TransactionSorted(ctx context.Context, transaction string, rows []T, less func(a, b T) bool) (bool, error) {
+	sorted := append([]T(nil), rows...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	return m.TransactionContext(ctx, transaction, sorted)
+}
+
+// TransactionDryRun runs Transaction in full, returning the first error it
+// would, but always rolls back. Constraints deferred until commit are not checked
+func (m *SQLTableManager[T, R]) TransactionDryRun(transaction string, rows []T) error {
+	return m.TransactionDryRunContext(context.Background(), transaction, rows)
+}
+
+// TransactionDryRunContext is TransactionDryRun bound to ctx
+func (m *SQLTableManager[T, R]) TransactionDryRunContext(ctx context.Context, transaction string, rows []T) error {
+	_, _, err := m.transact(ctx, transaction, rows, nil, false)
+	return err
+}
+
+// transact executes transaction once per row within a database transaction,
+// binding the arguments argsFn returns, committing at the end when commit is
+// set and rolling back otherwise. It reports the rows affected by a committed transaction
+func (m *SQLTableManager[T, R]) transact(ctx context.Context, transaction string, rows []T, argsFn func(*T) []any, commit bool) (affected int64, ok bool, err error) {
+	defer m.opts.annotate(&err, "Transaction", transaction)
+	if commit {
+		defer func() { m.opts.audit(ctx, "Transaction", affected, err) }()
+	}
+	if err := m.opts.writable(); err != nil {
+		return 0, false, err
+	}
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	transaction = m.opts.finalize(ctx, transaction, nil)
+	var columns []string
+	if argsFn == nil {
+		columns = m.columns()
+	}
+	fail := m.opts.rowFailure(columns)
+	argsFn = convertingArgs[T, R](&m.opts, argsFn)
+	if m.opts.dryRun != nil {
+		for _, row := range rows {
+			m.opts.dryRun(transaction, bindArgs[T, R](argsFn, &row))
+		}
+		return 0, false, nil
+	}
+	if commit {
+		var after func(*error)
+		if ctx, after, err = m.opts.beforeExec(ctx, "Transaction", transaction, nil, len(rows)); err != nil {
+			return 0, false, err
+		}
+		defer after(&err)
+		defer m.opts.invalidate()
+	}
+	var execed int
+	if m.opts.observed() {
+		var start time.Time
+		ctx, start = m.opts.begin(ctx, OpTransaction, transaction, nil)
+		defer func() {
+			m.opts.observe(ctx, OpTransaction, transaction, nil, start, int64(execed), err)
+		}()
+	}
+	err = m.opts.retryBusy(ctx, func() (err error) {
+		execed = 0
+		affected, err = m.transactOnce(ctx, transaction, rows, argsFn, fail, commit, &execed)
+		return err
+	})
+	if err != nil || !commit {
+		return 0, false, err
+	}
+	return affected, true, nil
+}
+
+// transactOnce makes a single attempt at the database transaction of transact
+func (m *SQLTableManager[T, R]) transactOnce(ctx context.Context, transaction string, rows []T, argsFn func(*T) []any, fail func(int, []any, error) error, commit bool, execed *int) (int64, error) {
+	tx, err := m.beginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := tx.PrepareContext(ctx, transaction)
+	if err != nil {
+		return 0, rollback(tx, err)
 	}
 	defer stmt.Close()
-	for _, row := range rows {
-		_, err = stmt.Exec(R(&row).Fields()...)
+	affected, err := execRows[T, R](ctx, stmt, rows, argsFn, execed, m.opts.progress(len(rows)), fail)
+	if err != nil {
+		return 0, rollback(tx, err)
+	}
+	if !commit {
+		return 0, rollback(tx, nil)
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// execRows executes stmt once per row, counting the executions in execed
+// and reporting them to progress when not nil, and returns the rows
+// affected, or -1 if the driver cannot tell. A failing row's error is
+// passed through fail when not nil
+func execRows[T any, R Schema[T]](ctx context.Context, stmt *sql.Stmt, rows []T, argsFn func(*T) []any, execed *int, progress func(int, bool), fail func(int, []any, error) error) (int64, error) {
+	i := 0
+	return execFrom[T, R](ctx, stmt, func() (row T, ok bool, err error) {
+		if i == len(rows) {
+			return row, false, nil
+		}
+		i++
+		return rows[i-1], true, nil
+	}, argsFn, execed, progress, fail)
+}
+
+// execFrom is execRows over the rows pulled from next. A failure of next is
+// returned as a *SourceError
+func execFrom[T any, R Schema[T]](ctx context.Context, stmt *sql.Stmt, next func() (T, bool, error), argsFn func(*T) []any, execed *int, progress func(int, bool), fail func(int, []any, error) error) (affected int64, err error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
+		}
+		row, ok, err := next()
 		if err != nil {
-			return false, tx.Rollback()
+			return 0, &SourceError{Row: *execed, Err: err}
+		}
+		if !ok {
+			if progress != nil {
+				progress(*execed, true)
+			}
+			return affected, nil
+		}
+		args := bindArgs[T, R](argsFn, &row)
+		res, err := stmt.ExecContext(ctx, args...)
+		if err != nil {
+			if fail != nil {
+				err = fail(*execed, args, err)
+			}
+			return 0, err
+		}
+		*execed++
+		affected = addAffected(affected, rowsAffected(res, nil))
+		if progress != nil {
+			progress(*execed, false)
 		}
 	}
-	err = tx.Commit()
-	return err == nil, err
 }
 
-func (m *sqlTableManager[T, R]) Query(query string) (rows []T, err error) {
-	queryRows, err := m.db.Query(query)
+// addAffected adds n rows affected to total, either being -1 when unknown
+func addAffected(total, n int64) int64 {
+	if total < 0 || n < 0 {
+		return -1
+	}
+	return total + n
+}
+
+// bindArgs returns the statement arguments for row, its Fields unless argsFn is set
+func bindArgs[T any, R Schema[T]](argsFn func(*T) []any, row *T) []any {
+	if argsFn != nil {
+		return argsFn(row)
+	}
+	return R(row).Fields()
+}
+
+func (m *SQLTableManager[T, R]) Query(query string, args ...any) ([]T, error) {
+	return m.QueryContext(context.Background(), query, args...)
+}
+
+func (m *SQLTableManager[T, R]) QueryContext(ctx context.Context, query string, args ...any) ([]T, error) {
+	rows, err := m.QueryAppendContext(ctx, nil, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// QueryPtr is Query, returning a pointer to each row. Every row is
+// allocated once with new(T) and scanned in place, so large rows are never
+// copied. Query itself scans into its result slice without copying, but
+// grows it by copying; QueryPtr suits rows that are large or must stay at
+// a stable address. It bypasses WithQueryCache and WithSingleflight
+func (m *SQLTableManager[T, R]) QueryPtr(query string, args ...any) ([]*T, error) {
+	return m.QueryPtrContext(context.Background(), query, args...)
+}
+
+// QueryPtrContext is QueryPtr bound to ctx
+func (m *SQLTableManager[T, R]) QueryPtrContext(ctx context.Context, query string, args ...any) ([]*T, error) {
+	var rows []*T
+	names := m.schemaColumns()
+	var scanner RowScanner
+	err := m.queryEach(ctx, query, args, func(queryRows *sql.Rows) (err error) {
+		if m.opts.maxRows > 0 && len(rows) == m.opts.maxRows {
+			return ErrTooManyRows
+		}
+		if scanner == nil {
+			if scanner, err = m.opts.columnScanner(queryRows, names); err != nil {
+				return err
+			}
+		}
+		row := new(T)
+		if err := m.scanRow(scanner, row); err != nil {
+			return err
+		}
+		rows = append(rows, row)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return rows, nil
+}
+
+// QueryAppend is Query, appending the rows to dst and returning the extended
+// slice. Passing dst[:0] from a previous call reuses its backing array, so
+// rows returned by that call must no longer be in use. On error dst is
+// returned at its original length
+func (m *SQLTableManager[T, R]) QueryAppend(dst []T, query string, args ...any) ([]T, error) {
+	return m.QueryAppendContext(context.Background(), dst, query, args...)
+}
+
+// QueryAppendContext is QueryAppend bound to ctx
+func (m *SQLTableManager[T, R]) QueryAppendContext(ctx context.Context, dst []T, query string, args ...any) ([]T, error) {
+	key, keyed := m.resultKey(ctx, "Query", query, args)
+	cached, hit, store := m.fromCache(key, keyed, dst)
+	if hit {
+		return cached, nil
+	}
+	rows, err := m.share(ctx, key, keyed, dst, func(ctx context.Context, dst []T) ([]T, error) {
+		return m.queryAppend(ctx, dst, query, args)
+	})
+	if err != nil {
+		return dst, err
+	}
+	if store != nil {
+		store(rows[len(dst):])
+	}
+	return rows, nil
+}
+
+// queryAppend runs query and appends its rows to dst
+func (m *SQLTableManager[T, R]) queryAppend(ctx context.Context, dst []T, query string, args []any) ([]T, error) {
+	base := len(dst)
+	rows := dst
+	names := m.schemaColumns()
+	var scanner RowScanner
+	err := m.queryEach(ctx, query, args, func(queryRows *sql.Rows) (err error) {
+		if m.opts.maxRows > 0 && len(rows)-base == m.opts.maxRows {
+			return ErrTooManyRows
+		}
+		if scanner == nil {
+			if scanner, err = m.opts.columnScanner(queryRows, names); err != nil {
+				return err
+			}
+		}
+		var zero T
+		rows = append(rows, zero)
+		return m.scanRow(scanner, &rows[len(rows)-1])
+	})
+	if err != nil {
+		return dst, err
+	}
+	return rows, nil
+}
+
+// queryEach runs query and calls fn for each resulting row
+func (m *SQLTableManager[T, R]) queryEach(ctx context.Context, query string, args []any, fn func(*sql.Rows) error) error {
+	return m.queryEachChecked(ctx, query, args, nil, fn)
+}
+
+// queryEachChecked is queryEach, first calling check, when not nil, with
+// the result however many rows it has, such as to inspect its columns
+func (m *SQLTableManager[T, R]) queryEachChecked(ctx context.Context, query string, args []any, check func(*sql.Rows) error, fn func(*sql.Rows) error) error {
+	return m.queryEachSet(ctx, query, args, check, nil, func(_ int, queryRows *sql.Rows) error {
+		return fn(queryRows)
+	})
+}
+
+// queryEachSet is queryEachChecked, continuing through every result set
+// when startSet is not nil. startSet is called before each set is read,
+// and fn is passed the index of the set each row belongs to
+func (m *SQLTableManager[T, R]) queryEachSet(ctx context.Context, query string, args []any, check func(*sql.Rows) error, startSet func(int), fn func(int, *sql.Rows) error) (err error) {
+	defer m.opts.annotate(&err, "Query", query)
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	query = m.opts.finalize(ctx, query, args)
+	args = m.opts.convertArgs(args)
+	var n int
+	if m.opts.observed() {
+		var start time.Time
+		ctx, start = m.opts.begin(ctx, OpQuery, query, args)
+		defer func() {
+			m.opts.observe(ctx, OpQuery, query, args, start, int64(n), err)
+		}()
+	}
+	var queryRows *sql.Rows
+	end := func() {}
+	defer func() { end() }()
+	err = m.opts.retry(ctx, false, func() (err error) {
+		end()
+		var q querier
+		if q, end, err = m.reader(ctx); err != nil {
+			return err
+		}
+		queryRows, err = q.QueryContext(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
 	defer queryRows.Close()
-	for queryRows.Next() {
-		box := new(T)
-		err = R(box).ScanRow(queryRows)
-		if err != nil {
-			return nil, err
+	if check != nil {
+		if err = check(queryRows); err != nil {
+			return err
 		}
-		rows = append(rows, *box)
 	}
-	return
+	for set := 0; ; set++ {
+		if startSet != nil {
+			startSet(set)
+		}
+		for queryRows.Next() {
+			if err = fn(set, queryRows); err != nil {
+				return resultSetError(startSet != nil, set, err)
+			}
+			n++
+		}
+		if err = queryRows.Err(); err != nil {
+			return resultSetError(startSet != nil, set, err)
+		}
+		if startSet == nil || !queryRows.NextResultSet() {
+			return queryRows.Err()
+		}
+	}
+}
+
+func (m *SQLTableManager[T, R]) QueryRow(query string, args ...any) (T, error) {
+	return m.QueryRowContext(context.Background(), query, args...)
+}
+
+func (m *SQLTableManager[T, R]) QueryRowContext(ctx context.Context, query string, args ...any) (T, error) {
+	key, keyed := m.resultKey(ctx, "QueryRow", query, args)
+	cached, hit, store := m.fromCache(key, keyed, nil)
+	if hit {
+		return cached[0], nil
+	}
+	rows, err := m.share(ctx, key, keyed, nil, func(ctx context.Context, _ []T) ([]T, error) {
+		row, err := m.queryRow(ctx, query, args)
+		return []T{row}, err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if store != nil {
+		store(rows)
+	}
+	return rows[0], nil
+}
+
+// queryRow runs query and scans its first row
+func (m *SQLTableManager[T, R]) queryRow(ctx context.Context, query string, args []any) (row T, err error) {
+	err = m.queryRowInto(ctx, query, args, &row)
+	return row, err
+}
+
+// QueryRowInto is QueryRowContext, scanning the first row directly into
+// dst through the Schema rather than returning a copy, so large rows are
+// never copied and dst can be reused. It returns ErrNotFound when no row
+// matches; dst may be partly written when scanning fails. It bypasses
+// WithQueryCache and WithSingleflight
+func (m *SQLTableManager[T, R]) QueryRowInto(ctx context.Context, dst *T, query string, args ...any) error {
+	err := m.queryRowInto(ctx, query, args, dst)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// queryRowInto runs query and scans its first row into box
+func (m *SQLTableManager[T, R]) queryRowInto(ctx context.Context, query string, args []any, box *T) (err error) {
+	defer m.opts.annotate(&err, "QueryRow", query)
+	if m.group != nil {
+		// the *sql.Row of a bound manager cannot carry ErrTxEnded
+		if err := m.group.check(); err != nil {
+			return err
+		}
+	}
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	query = m.opts.finalize(ctx, query, args)
+	args = m.opts.convertArgs(args)
+	ctx, start := m.opts.begin(ctx, OpQueryRow, query, args)
+	names := m.schemaColumns()
+	err = m.opts.retry(ctx, false, func() error {
+		return m.read(ctx, func(q querier) error {
+			if names != nil || m.opts.scanDiagnostics {
+				return m.queryFirst(ctx, q, query, args, box, names)
+			}
+			return m.scanRow(q.QueryRowContext(ctx, query, args...), box)
+		})
+	})
+	if m.opts.observed() {
+		m.opts.observe(ctx, OpQueryRow, query, args, start, rowsFound(err), err)
+	}
+	return err
 }
 
-func (m *sqlTableManager[T, R]) QueryRow(query string, args ...any) (row T, err error) {
-	queryRow := m.db.QueryRow(query, args...)
-	err = R(&row).ScanRow(queryRow)
-	return
+// QueryRowPtr is QueryRowContext, returning nil rather than an error when
+// no row matches
+func (m *SQLTableManager[T, R]) QueryRowPtr(ctx context.Context, query string, args ...any) (*T, error) {
+	row, err := m.QueryRowContext(ctx, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// QueryRowOptional is QueryRow, reporting false rather than an error when
+// no row matches. The zero T is returned unless a row was found
+func (m *SQLTableManager[T, R]) QueryRowOptional(query string, args ...any) (T, bool, error) {
+	return m.QueryRowOptionalContext(context.Background(), query, args...)
+}
+
+// QueryRowOptionalContext is QueryRowOptional bound to ctx
+func (m *SQLTableManager[T, R]) QueryRowOptionalContext(ctx context.Context, query string, args ...any) (T, bool, error) {
+	row, err := m.QueryRowContext(ctx, query, args...)
+	if err != nil {
+		var zero T
+		if errors.Is(err, sql.ErrNoRows) {
+			return zero, false, nil
+		}
+		return zero, false, err
+	}
+	return row, true, nil
+}
+
+// rollback aborts tx, joining any rollback failure onto err
+func rollback(tx txn, err error) error {
+	if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+		return errors.Join(err, rbErr)
+	}
+	return err
+}
+
+// scanRow scans a row into box through the Schema
+func (m *SQLTableManager[T, R]) scanRow(r RowScanner, box *T) error {
+	if j, ok := any(box).(joinedRow); ok {
+		return j.scanJoined(r, &m.opts)
+	}
+	return m.opts.scanSchema(r, R(box), m.columns)
+}
+
+// scanSchema scans a row into the Schema s through the scanning options,
+// deciphering the columns columns names
+func (o *options) scanSchema(r RowScanner, s interface{ ScanRow(RowScanner) error }, columns func() []string) error {
+	if o.scanDiagnostics {
+		r = diagnosing(r)
+	}
+	if o.nullAsZero {
+		r = nullZeroScanning(r)
+	}
+	// deciphered bytes reach the codecs, which wrap the destinations first
+	if o.cipher != nil {
+		r = o.cipher.scanning(r, columns())
+	}
+	if o.codecs != nil {
+		r = codecScanning(r, o.codecs)
+	}
+	if err := s.ScanRow(r); err != nil {
+		return err
+	}
+	if o.location != nil {
+		localize(s, o.location)
+	}
+	return nil
 }
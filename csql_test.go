@@ -0,0 +1,422 @@
+package csql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+	"github.com/vtereso/csql/csqltest"
+	"modernc.org/sqlite"
+)
+
+// Item is the Schema of the items table openDB creates
+type Item struct {
+	ID   int64
+	Name string
+}
+
+func (i *Item) ScanRow(s csql.RowScanner) error { return s.Scan(&i.ID, &i.Name) }
+
+func (i *Item) Fields() []any { return []any{i.ID, i.Name} }
+
+// openDB returns an in-memory sqlite database on a single connection,
+// holding an empty items (id, name) table
+func openDB(t testing.TB) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	mustExec(t, db, "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)")
+	return db
+}
+
+// openFileDB is openDB on a file, so its connections share the database
+func openFileDB(t testing.TB) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "csql.db")+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	mustExec(t, db, "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)")
+	return db
+}
+
+// mustExec runs query on db, failing t on error
+func mustExec(t testing.TB, db *sql.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+}
+
+// seedItems stores items 1 to n, named item1 to itemn
+func seedItems(t testing.TB, db *sql.DB, n int) {
+	t.Helper()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= n; i++ {
+		if _, err := tx.Exec("INSERT INTO items (id, name) VALUES (?, ?)", i, fmt.Sprintf("item%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, fmt.Sprintf(csqltest.ConformanceTable, "conformance"))
+	csqltest.RunConformance(t, csql.NewSQLTableManager[csqltest.ConformanceRow](db), "conformance")
+}
+
+func TestQueryAppend(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 3)
+	m := csql.NewSQLTableManager[Item](db)
+	buf := make([]Item, 0, 8)
+	got, err := m.QueryAppend(buf, "SELECT id, name FROM items ORDER BY id")
+	if err != nil || len(got) != 3 || &got[0] != &buf[:1][0] {
+		t.Fatalf("QueryAppend = %v, %v, want 3 rows in buf", got, err)
+	}
+	got, err = m.QueryAppend(got, "SELECT id, name FROM items WHERE id = ?", 2)
+	if err != nil || len(got) != 4 || got[3] != (Item{ID: 2, Name: "item2"}) {
+		t.Fatalf("QueryAppend onto rows = %v, %v", got, err)
+	}
+	if got, err := m.QueryAppend(got[:2], "SELECT id, name FROM missing"); err == nil || len(got) != 2 {
+		t.Fatalf("failed QueryAppend = %v, %v, want dst at its length and an error", got, err)
+	}
+}
+
+func TestTransactionCanceledBetweenRows(t *testing.T) {
+	db, rec := openRecorded(t, func(_ context.Context, query string) bool {
+		return query == "PREPARE "+insertItem
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// cancel once the first row has executed
+	m := csql.NewSQLTableManager[Item](db, csql.WithProgress(1, func(done, _ int) {
+		if done == 1 {
+			cancel()
+		}
+	}))
+	bound := 0
+	ok, err := m.TransactionFuncContext(ctx, insertItem, items(3), func(i *Item) []any {
+		bound++
+		return i.Fields()
+	})
+	if ok || !errors.Is(err, context.Canceled) {
+		t.Fatalf("TransactionFuncContext = %t, %v, want context.Canceled", ok, err)
+	}
+	if n := rec.Count(insertItem); n != 1 || bound != 1 {
+		t.Fatalf("%d rows executed, %d bound, want only the first before cancellation", n, bound)
+	}
+	if rec.Count("ROLLBACK") != 1 || rec.Count("COMMIT") != 0 {
+		t.Fatalf("statements %v, want the transaction rolled back", rec.Stmts())
+	}
+}
+
+func TestTransactionDryRun(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	seedItems(t, db, 1)
+	m := csql.NewSQLTableManager[Item](db)
+	if err := m.TransactionDryRun(insertItem, []Item{{2, "item2"}, {3, "item3"}}); err != nil {
+		t.Fatal(err)
+	}
+	// seedItems commits once
+	if rec.Count("PREPARE "+insertItem) != 1 || rec.Count("ROLLBACK") != 1 || rec.Count("COMMIT") != 1 {
+		t.Fatalf("statements %v, want the dry run prepared and rolled back", rec.Stmts())
+	}
+	if got, err := m.Query(selectItems); err != nil || len(got) != 1 {
+		t.Fatalf("Query = %v, %v, want the table unchanged", got, err)
+	}
+	// the second row violates the primary key, as Transaction would report
+	err := m.TransactionDryRun(insertItem, []Item{{2, "item2"}, {1, "again"}})
+	_, txErr := m.Transaction(insertItem, []Item{{2, "item2"}, {1, "again"}})
+	if err == nil || txErr == nil || err.Error() != txErr.Error() {
+		t.Fatalf("TransactionDryRun = %v, want Transaction's %v", err, txErr)
+	}
+	if got, err := m.Query(selectItems); err != nil || len(got) != 1 {
+		t.Fatalf("Query = %v, %v, want the table unchanged", got, err)
+	}
+}
+
+// errRefused is the error flakyConnector refuses connections with
+var errRefused = errors.New("connection refused")
+
+// flakyConnector refuses its first fails connections, as a database not
+// yet up would, then connects to an in-memory sqlite database
+type flakyConnector struct {
+	mu     sync.Mutex
+	fails  int
+	dialed int
+}
+
+func (c *flakyConnector) Connect(context.Context) (driver.Conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dialed++
+	if c.dialed <= c.fails {
+		return nil, errRefused
+	}
+	return (&sqlite.Driver{}).Open(":memory:")
+}
+
+func (c *flakyConnector) Driver() driver.Driver { return &sqlite.Driver{} }
+
+func TestNewSQLTableManagerWithRetry(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		attempts int
+		wantErr  bool
+	}{
+		{"connects on the third attempt", 3, false},
+		{"gives up after two", 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &flakyConnector{fails: 2}
+			db := sql.OpenDB(c)
+			defer db.Close()
+			m, err := csql.NewSQLTableManagerWithRetry[Item](ctx, db, tt.attempts, time.Millisecond)
+			if tt.wantErr != (err != nil) || tt.wantErr != (m == nil) {
+				t.Fatalf("NewSQLTableManagerWithRetry = %v, %v, want error %t", m, err, tt.wantErr)
+			}
+			if tt.wantErr && (!errors.Is(err, errRefused) || !strings.Contains(err.Error(), "after 2 attempts")) {
+				t.Fatalf("NewSQLTableManagerWithRetry = %v, want the last ping error", err)
+			}
+			if c.dialed != tt.attempts {
+				t.Fatalf("dialed %d times, want %d", c.dialed, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestNewSQLTableManagerWithRetryCanceled(t *testing.T) {
+	db := sql.OpenDB(&flakyConnector{fails: 1})
+	defer db.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := csql.NewSQLTableManagerWithRetry[Item](ctx, db, 3, time.Hour)
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errRefused) {
+		t.Fatalf("NewSQLTableManagerWithRetry = %v, want the deadline and ping error", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("waited %v despite the deadline", d)
+	}
+}
+
+func TestTransactionCount(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 3)
+	m := csql.NewSQLTableManager[Item](db)
+	// the row for id 7 matches nothing
+	n, ok, err := m.TransactionCount("UPDATE items SET name = name || '!' WHERE id = ? AND ? <> ''", []Item{{1, "a"}, {7, "b"}, {3, "c"}})
+	if n != 2 || !ok || err != nil {
+		t.Fatalf("TransactionCount = %d, %t, %v, want 2 rows affected", n, ok, err)
+	}
+	// moving item2 onto id 1 violates the primary key
+	if n, ok, err := m.TransactionCount("UPDATE items SET id = ? WHERE name = ?", []Item{{9, "item3"}, {1, "item2"}}); n != 0 || ok || err == nil {
+		t.Fatalf("failing TransactionCount = %d, %t, %v, want no count reported", n, ok, err)
+	}
+
+	// stubbed statements cannot report the rows they affected
+	db, _ = openRecorded(t, func(_ context.Context, query string) bool {
+		return query == "PREPARE "+insertItem
+	})
+	m = csql.NewSQLTableManager[Item](db)
+	if n, ok, err := m.TransactionCount(insertItem, items(2)); n != -1 || !ok || err != nil {
+		t.Fatalf("TransactionCount = %d, %t, %v, want -1 when the driver cannot tell", n, ok, err)
+	}
+}
+
+func TestTransactionFunc(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 3)
+	m := csql.NewSQLTableManager[Item](db)
+	// the id binds last, unlike in Fields
+	ok, err := m.TransactionFunc("UPDATE items SET name = ? WHERE id = ?", []Item{{1, "one"}, {3, "three"}}, func(i *Item) []any {
+		return []any{i.Name, i.ID}
+	})
+	if !ok || err != nil {
+		t.Fatalf("TransactionFunc = %t, %v", ok, err)
+	}
+	// a single placeholder, fewer than the Fields
+	if ok, err := m.TransactionFunc("DELETE FROM items WHERE id = ?", []Item{{2, ""}}, func(i *Item) []any { return []any{i.ID} }); !ok || err != nil {
+		t.Fatalf("TransactionFunc = %t, %v", ok, err)
+	}
+	if ok, err := m.TransactionFunc(insertItem, []Item{{4, "item4"}}, nil); !ok || err != nil {
+		t.Fatalf("TransactionFunc with nil argsFn = %t, %v", ok, err)
+	}
+	if got, err := m.Query(selectItems); err != nil || fmt.Sprint(got) != "[{1 one} {3 three} {4 item4}]" {
+		t.Fatalf("Query = %v, %v", got, err)
+	}
+}
+
+func TestQueryRowPtr(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "INSERT INTO items (id, name) VALUES (0, '')")
+	m := csql.NewSQLTableManager[Item](db)
+	ctx := context.Background()
+	// a row of zero values is still found
+	row, err := m.QueryRowPtr(ctx, "SELECT id, name FROM items WHERE id = ?", 0)
+	if err != nil || row == nil || *row != (Item{}) {
+		t.Fatalf("QueryRowPtr = %v, %v, want the zero row", row, err)
+	}
+	if row, err := m.QueryRowPtr(ctx, "SELECT id, name FROM items WHERE id = ?", 1); row != nil || err != nil {
+		t.Fatalf("QueryRowPtr of no row = %v, %v, want nil, nil", row, err)
+	}
+	if row, err := m.QueryRowPtr(ctx, "SELECT id, name FROM missing"); row != nil || err == nil {
+		t.Fatalf("QueryRowPtr = %v, %v, want the error", row, err)
+	}
+}
+
+func TestQueryRowOptional(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "INSERT INTO items (id, name) VALUES (1, 'a'), (2, 'bad')")
+	m := csql.NewSQLTableManager[Picky](db)
+	const byID = "SELECT id, name FROM items WHERE id = ?"
+	if row, ok, err := m.QueryRowOptional(byID, 1); !ok || err != nil || row.Item != (Item{1, "a"}) {
+		t.Fatalf("QueryRowOptional = %v, %t, %v, want the row", row, ok, err)
+	}
+	if row, ok, err := m.QueryRowOptional(byID, 9); ok || err != nil || row != (Picky{}) {
+		t.Fatalf("QueryRowOptional of no row = %v, %t, %v, want not found and no error", row, ok, err)
+	}
+	// Picky has scanned the row before rejecting it
+	if row, ok, err := m.QueryRowOptional(byID, 2); ok || !errors.Is(err, errBadRow) || row != (Picky{}) {
+		t.Fatalf("QueryRowOptional of a bad row = %v, %t, %v, want the zero row and the scan error", row, ok, err)
+	}
+}
+
+func TestQueryRowInto(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 2)
+	m := csql.NewSQLTableManager[NamedItem](db)
+	ctx := context.Background()
+	const byID = "SELECT name, id FROM items WHERE id = ?"
+	dst := NamedItem{ID: 9, Name: "stale"}
+	if err := m.QueryRowInto(ctx, &dst, byID, 1); err != nil || dst != (NamedItem{1, "item1"}) {
+		t.Fatalf("QueryRowInto = %v, %v, want the row scanned into dst", dst, err)
+	}
+	// dst is reused in place
+	if err := m.QueryRowInto(ctx, &dst, byID, 2); err != nil || dst != (NamedItem{2, "item2"}) {
+		t.Fatalf("reused QueryRowInto = %v, %v", dst, err)
+	}
+	if err := m.QueryRowInto(ctx, &dst, byID, 3); !errors.Is(err, csql.ErrNotFound) || dst != (NamedItem{2, "item2"}) {
+		t.Fatalf("QueryRowInto of no row = %v, %v, want ErrNotFound and dst untouched", dst, err)
+	}
+	if err := m.QueryRowInto(ctx, &dst, "SELECT id, name FROM missing"); err == nil || errors.Is(err, csql.ErrNotFound) {
+		t.Fatalf("QueryRowInto of a missing table = %v, want the query error", err)
+	}
+}
+
+func TestTransactionSorted(t *testing.T) {
+	db, rec := openRecorded(t, func(_ context.Context, query string) bool {
+		return query == "PREPARE "+insertItem
+	})
+	m := csql.NewSQLTableManager[Item](db)
+	rows := []Item{{3, "c"}, {1, "a"}, {2, "b"}, {1, "a2"}}
+	ok, err := m.TransactionSorted(context.Background(), insertItem, rows, func(a, b Item) bool { return a.ID < b.ID })
+	if !ok || err != nil {
+		t.Fatalf("TransactionSorted = %t, %v", ok, err)
+	}
+	var execed []string
+	for _, s := range rec.Stmts() {
+		if s.SQL == insertItem {
+			execed = append(execed, fmt.Sprint(s.Args))
+		}
+	}
+	// equal keys keep their given order
+	if want := []string{"[1 a]", "[1 a2]", "[2 b]", "[3 c]"}; !slices.Equal(execed, want) {
+		t.Fatalf("execed %q, want %q", execed, want)
+	}
+	if rows[0].ID != 3 {
+		t.Fatalf("rows reordered to %v, want them left as given", rows)
+	}
+}
+
+// benchRows is the number of rows the query benchmarks read
+const benchRows = 100
+
+func BenchmarkQuery(b *testing.B) {
+	db := openDB(b)
+	seedItems(b, db, benchRows)
+	m := csql.NewSQLTableManager[Item](db)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if rows, err := m.Query("SELECT id, name FROM items"); err != nil || len(rows) != benchRows {
+			b.Fatal(len(rows), err)
+		}
+	}
+}
+
+func BenchmarkQueryAppend(b *testing.B) {
+	db := openDB(b)
+	seedItems(b, db, benchRows)
+	m := csql.NewSQLTableManager[Item](db)
+	var buf []Item
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if buf, err = m.QueryAppend(buf[:0], "SELECT id, name FROM items"); err != nil || len(buf) != benchRows {
+			b.Fatal(len(buf), err)
+		}
+	}
+}
+
+// Wide is Item padded with arrays, so copying a row costs
+type Wide struct {
+	ID      int64
+	Name    string
+	History [64]int64
+	Scores  [64]float64
+}
+
+func (w *Wide) ScanRow(s csql.RowScanner) error { return s.Scan(&w.ID, &w.Name) }
+
+func (w *Wide) Fields() []any { return []any{w.ID, w.Name} }
+
+func BenchmarkQueryWide(b *testing.B) {
+	db := openDB(b)
+	seedItems(b, db, benchRows)
+	m := csql.NewSQLTableManager[Wide](db)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if rows, err := m.Query("SELECT id, name FROM items"); err != nil || len(rows) != benchRows {
+			b.Fatal(len(rows), err)
+		}
+	}
+}
+
+func BenchmarkQueryPtrWide(b *testing.B) {
+	db := openDB(b)
+	seedItems(b, db, benchRows)
+	m := csql.NewSQLTableManager[Wide](db)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if rows, err := m.QueryPtr("SELECT id, name FROM items"); err != nil || len(rows) != benchRows {
+			b.Fatal(len(rows), err)
+		}
+	}
+}
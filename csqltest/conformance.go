@@ -0,0 +1,195 @@
+package csqltest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// Fixture describes the statements TestSQLTable runs and the rows it
+// expects back. Statements use the placeholders the implementation takes
+type Fixture[T any] struct {
+	// Rows are what seed stores, at least two with distinct keys, in the
+	// order Select returns them
+	Rows []T
+	// Select returns every row
+	Select string
+	// SelectKey returns the row whose key equals its single argument
+	SelectKey string
+	// Key returns the SelectKey and DeleteKey argument of a row
+	Key func(T) any
+	// Missing is a key no row has
+	Missing any
+	// Narrow selects fewer columns than the Schema scans
+	Narrow string
+	// Insert is the Transaction statement storing a row from its Fields.
+	// It must fail for a row whose key is already stored
+	Insert string
+	// DeleteKey removes the row whose key equals its single argument
+	DeleteKey string
+}
+
+// TestSQLTable checks that the tables factory returns behave like a
+// csql.SQLTableManager over database/sql, so mocks, fakes, alternative
+// backends, and decorators can be held to the same contract. Each subtest
+// calls factory for an empty table, and seed to store f.Rows when it needs them
+func TestSQLTable[T any, R csql.Schema[T]](t *testing.T, factory func() csql.SQLTable[T, R], seed func(csql.SQLTable[T, R]), f Fixture[T]) {
+	ctx := context.Background()
+	seeded := func() csql.SQLTable[T, R] {
+		s := factory()
+		seed(s)
+		return s
+	}
+	expect := func(t *testing.T, s csql.SQLTable[T, R], want []T) {
+		t.Helper()
+		got, err := s.QueryContext(ctx, f.Select)
+		if err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+		if !sameRows[T, R](got, want) {
+			t.Fatalf("Query = %v, want %v", got, want)
+		}
+	}
+
+	t.Run("QueryEmpty", func(t *testing.T) {
+		rows, err := factory().Query(f.Select)
+		if len(rows) != 0 || err != nil {
+			t.Fatalf("Query = %v, %v, want no rows and no error", rows, err)
+		}
+	})
+	t.Run("QueryRows", func(t *testing.T) {
+		expect(t, seeded(), f.Rows)
+	})
+	t.Run("QueryScanError", func(t *testing.T) {
+		if _, err := seeded().Query(f.Narrow); err == nil {
+			t.Fatal("Query scanning too few columns succeeded")
+		}
+	})
+	t.Run("QueryRowFound", func(t *testing.T) {
+		want := f.Rows[1]
+		row, err := seeded().QueryRow(f.SelectKey, f.Key(want))
+		if err != nil || !sameRows[T, R]([]T{row}, []T{want}) {
+			t.Fatalf("QueryRow = %v, %v, want %v, nil", row, err, want)
+		}
+	})
+	t.Run("QueryRowNotFound", func(t *testing.T) {
+		row, err := seeded().QueryRow(f.SelectKey, f.Missing)
+		var zero T
+		if !errors.Is(err, sql.ErrNoRows) || !sameRows[T, R]([]T{row}, []T{zero}) {
+			t.Fatalf("QueryRow = %v, %v, want the zero row and sql.ErrNoRows", row, err)
+		}
+	})
+	t.Run("Exec", func(t *testing.T) {
+		s := seeded()
+		if err := s.Exec(f.DeleteKey, f.Key(f.Rows[0])); err != nil {
+			t.Fatalf("Exec: %v", err)
+		}
+		expect(t, s, f.Rows[1:])
+	})
+	t.Run("Transaction", func(t *testing.T) {
+		s := factory()
+		ok, err := s.Transaction(f.Insert, f.Rows)
+		if !ok || err != nil {
+			t.Fatalf("Transaction = %v, %v, want true, nil", ok, err)
+		}
+		expect(t, s, f.Rows)
+	})
+	t.Run("TransactionRollsBack", func(t *testing.T) {
+		s := factory()
+		rows := append(append([]T(nil), f.Rows...), f.Rows[0])
+		ok, err := s.Transaction(f.Insert, rows)
+		if ok || err == nil {
+			t.Fatalf("Transaction with a duplicate last row = %v, %v, want false and an error", ok, err)
+		}
+		expect(t, s, nil)
+	})
+	t.Run("CanceledContext", func(t *testing.T) {
+		s := seeded()
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, err := s.QueryContext(canceled, f.Select); !errors.Is(err, context.Canceled) {
+			t.Fatalf("QueryContext = %v, want context.Canceled", err)
+		}
+		if err := s.ExecContext(canceled, f.DeleteKey, f.Key(f.Rows[0])); !errors.Is(err, context.Canceled) {
+			t.Fatalf("ExecContext = %v, want context.Canceled", err)
+		}
+		expect(t, s, f.Rows)
+	})
+}
+
+// sameRows reports whether got and want hold the same rows, compared by
+// their Fields
+func sameRows[T any, R csql.Schema[T]](got, want []T) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if !reflect.DeepEqual(R(&got[i]).Fields(), R(&want[i]).Fields()) {
+			return false
+		}
+	}
+	return true
+}
+
+// ConformanceTable is the DDL, formatted with the table name, of the table
+// RunConformance expects
+const ConformanceTable = "CREATE TABLE %s (id BIGINT PRIMARY KEY, name TEXT NOT NULL)"
+
+// ConformanceRow is the Schema RunConformance reads and writes
+type ConformanceRow struct {
+	ID   int64
+	Name string
+}
+
+func (r *ConformanceRow) ScanRow(s csql.RowScanner) error {
+	return s.Scan(&r.ID, &r.Name)
+}
+
+func (r *ConformanceRow) Fields() []any {
+	return []any{r.ID, r.Name}
+}
+
+// RunConformance runs TestSQLTable against s over the table named table,
+// created with ConformanceTable, emptying it before each subtest. The
+// statements use ? placeholders, so a manager for Postgres needs
+// csql.WithDialect(csql.Postgres)
+func RunConformance(t *testing.T, s csql.SQLTable[ConformanceRow, *ConformanceRow], table string) {
+	f := Fixture[ConformanceRow]{
+		Rows:      []ConformanceRow{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}},
+		Select:    "SELECT id, name FROM " + table + " ORDER BY id",
+		SelectKey: "SELECT id, name FROM " + table + " WHERE id = ?",
+		Key:       func(r ConformanceRow) any { return r.ID },
+		Missing:   int64(-1),
+		Narrow:    "SELECT id FROM " + table,
+		Insert:    "INSERT INTO " + table + " (id, name) VALUES (?, ?)",
+		DeleteKey: "DELETE FROM " + table + " WHERE id = ?",
+	}
+	factory := func() csql.SQLTable[ConformanceRow, *ConformanceRow] {
+		if err := s.Exec("DELETE FROM " + table); err != nil {
+			t.Fatalf("emptying %s: %v", table, err)
+		}
+		return s
+	}
+	seed := func(s csql.SQLTable[ConformanceRow, *ConformanceRow]) {
+		if ok, err := s.Transaction(f.Insert, f.Rows); !ok || err != nil {
+			t.Fatalf("seeding %s: ok %v, err %v", table, ok, err)
+		}
+	}
+	TestSQLTable(t, factory, seed, f)
+	t.Run("StatementError", func(t *testing.T) {
+		missing := table + "_missing"
+		if _, err := s.Query("SELECT id, name FROM " + missing); err == nil {
+			t.Fatalf("Query of %s succeeded", missing)
+		}
+		if err := s.Exec("DELETE FROM " + missing); err == nil {
+			t.Fatalf("Exec on %s succeeded", missing)
+		}
+		if ok, err := s.Transaction("INSERT INTO "+missing+" (id, name) VALUES (?, ?)", f.Rows); ok || err == nil {
+			t.Fatalf("Transaction on %s = %v, %v, want false and an error", missing, ok, err)
+		}
+	})
+}
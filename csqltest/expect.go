@@ -0,0 +1,97 @@
+package csqltest
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+// QueryExpectN runs query on s and fails t unless it returns n rows,
+// reporting the rows it got. It returns the rows
+func QueryExpectN[T any, R csql.Schema[T]](t testing.TB, s csql.SQLTable[T, R], n int, query string, args ...any) []T {
+	t.Helper()
+	rows, err := s.Query(query, args...)
+	if err != nil {
+		t.Fatalf("Query %q: %v", query, err)
+	}
+	if len(rows) != n {
+		var b strings.Builder
+		for i := range rows {
+			fmt.Fprintf(&b, "\n\trow %d: %v", i, R(&rows[i]).Fields())
+		}
+		t.Fatalf("Query %q returned %d rows, want %d:%s", query, len(rows), n, b.String())
+	}
+	return rows
+}
+
+// QueryRowExpect runs query on s and fails t unless its row equals want,
+// comparing the Fields of both, so fields the Schema leaves out do not
+// count. Fields are compared by their driver values, with nil pointers
+// and invalid Null wrappers as NULL and times by instant. It returns the row
+func QueryRowExpect[T any, R csql.Schema[T]](t testing.TB, s csql.SQLTable[T, R], want T, query string, args ...any) T {
+	t.Helper()
+	row, err := s.QueryRow(query, args...)
+	if err != nil {
+		t.Fatalf("QueryRow %q: %v", query, err)
+	}
+	if diff := diffFields(R(&row).Fields(), R(&want).Fields()); diff != "" {
+		t.Fatalf("QueryRow %q row differs:%s", query, diff)
+	}
+	return row
+}
+
+// diffFields describes the fields differing between got and want, or
+// returns "" when they are equal
+func diffFields(got, want []any) string {
+	if len(got) != len(want) {
+		return fmt.Sprintf("\n\tgot %d fields, want %d", len(got), len(want))
+	}
+	var b strings.Builder
+	for i := range got {
+		g, gErr := fieldValue(got[i])
+		w, wErr := fieldValue(want[i])
+		switch {
+		case gErr != nil || wErr != nil:
+			fmt.Fprintf(&b, "\n\tfield %d: valuing: got %v, want %v", i, gErr, wErr)
+		case !sameValue(g, w):
+			fmt.Fprintf(&b, "\n\tfield %d: got %v, want %v", i, g, w)
+		}
+	}
+	return b.String()
+}
+
+// fieldValue returns the driver value of the field f, nil for NULL
+func fieldValue(f any) (any, error) {
+	rv := reflect.ValueOf(f)
+	for rv.IsValid() {
+		if v, ok := rv.Interface().(driver.Valuer); ok {
+			if rv.Kind() == reflect.Pointer && rv.IsNil() {
+				return nil, nil
+			}
+			return v.Value()
+		}
+		if rv.Kind() != reflect.Pointer {
+			return rv.Interface(), nil
+		}
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	return nil, nil
+}
+
+// sameValue reports whether the driver values a and b are equal, times
+// compared by instant
+func sameValue(a, b any) bool {
+	if at, ok := a.(time.Time); ok {
+		bt, ok := b.(time.Time)
+		return ok && at.Equal(bt)
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,217 @@
+package csqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/vtereso/csql"
+)
+
+// FakeTable is an in-memory csql.SQLTable. Transaction stores its rows and
+// Query returns them, filtered by the matcher registered for the query text.
+// Rows are copied through the Schema's Fields and ScanRow on the way in and
+// out, catching column order bugs without a database. It is safe for
+// concurrent use
+type FakeTable[T any, R csql.Schema[T]] struct {
+	mu       sync.Mutex
+	rows     []T
+	matchers map[string]func([]T) []T
+	execs    []string
+}
+
+var _ csql.SQLTable[nopSchema, *nopSchema] = (*FakeTable[nopSchema, *nopSchema])(nil)
+
+// NewFakeTable returns an empty FakeTable
+func NewFakeTable[T any, R csql.Schema[T]]() *FakeTable[T, R] {
+	return &FakeTable[T, R]{
+		matchers: make(map[string]func([]T) []T),
+	}
+}
+
+// Match registers filter to select the stored rows returned for query.
+// Queries without a matcher return every stored row
+func (f *FakeTable[T, R]) Match(query string, filter func([]T) []T) *FakeTable[T, R] {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.matchers[query] = filter
+	return f
+}
+
+// Seed stores rows as if inserted by Transaction
+func (f *FakeTable[T, R]) Seed(rows ...T) error {
+	copied, err := roundTrip[T, R](rows)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rows = append(f.rows, copied...)
+	return nil
+}
+
+// Rows returns copies of the stored rows
+func (f *FakeTable[T, R]) Rows() ([]T, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return roundTrip[T, R](f.rows)
+}
+
+// Execs returns the statements passed to Exec, in order
+func (f *FakeTable[T, R]) Execs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.execs...)
+}
+
+// Reset drops stored rows, matchers, and recorded statements
+func (f *FakeTable[T, R]) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rows = nil
+	f.matchers = make(map[string]func([]T) []T)
+	f.execs = nil
+}
+
+func (f *FakeTable[T, R]) Query(query string, args ...any) ([]T, error) {
+	return f.QueryContext(context.Background(), query, args...)
+}
+
+func (f *FakeTable[T, R]) QueryContext(_ context.Context, query string, _ ...any) ([]T, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rows := f.rows
+	if filter, ok := f.matchers[query]; ok {
+		rows = filter(append([]T(nil), rows...))
+	}
+	return roundTrip[T, R](rows)
+}
+
+func (f *FakeTable[T, R]) QueryRow(query string, args ...any) (T, error) {
+	return f.QueryRowContext(context.Background(), query, args...)
+}
+
+func (f *FakeTable[T, R]) QueryRowContext(ctx context.Context, query string, args ...any) (row T, err error) {
+	rows, err := f.QueryContext(ctx, query, args...)
+	if err != nil {
+		return row, err
+	}
+	if len(rows) == 0 {
+		return row, sql.ErrNoRows
+	}
+	return rows[0], nil
+}
+
+func (f *FakeTable[T, R]) Exec(query string, args ...any) error {
+	return f.ExecContext(context.Background(), query, args...)
+}
+
+func (f *FakeTable[T, R]) ExecContext(_ context.Context, query string, _ ...any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.execs = append(f.execs, query)
+	return nil
+}
+
+func (f *FakeTable[T, R]) Transaction(transaction string, rows []T) (bool, error) {
+	return f.TransactionContext(context.Background(), transaction, rows)
+}
+
+func (f *FakeTable[T, R]) TransactionContext(_ context.Context, _ string, rows []T) (bool, error) {
+	if err := f.Seed(rows...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// roundTrip copies rows by scanning each row's Fields into a new T
+func roundTrip[T any, R csql.Schema[T]](rows []T) ([]T, error) {
+	if rows == nil {
+		return nil, nil
+	}
+	copied := make([]T, len(rows))
+	for i := range rows {
+		if err := R(&copied[i]).ScanRow(fieldScanner(R(&rows[i]).Fields())); err != nil {
+			return nil, fmt.Errorf("csqltest: row %d: %w", i, err)
+		}
+	}
+	return copied, nil
+}
+
+// fieldScanner is a csql.RowScanner over a row's field values
+type fieldScanner []any
+
+func (s fieldScanner) Scan(dest ...any) error {
+	if len(dest) != len(s) {
+		return fmt.Errorf("csqltest: expected %d destination arguments in Scan, not %d", len(s), len(dest))
+	}
+	for i, src := range s {
+		if err := assign(dest[i], src); err != nil {
+			return fmt.Errorf("csqltest: column %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// assign stores src in the pointer dest, approximating database/sql conversions
+func assign(dest, src any) error {
+	if v, ok := src.(driver.Valuer); ok {
+		var err error
+		if src, err = v.Value(); err != nil {
+			return err
+		}
+	}
+	if s, ok := dest.(sql.Scanner); ok {
+		return s.Scan(src)
+	}
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Pointer || dv.IsNil() {
+		return fmt.Errorf("destination %T is not a non-nil pointer", dest)
+	}
+	dv = dv.Elem()
+	if src == nil {
+		dv.SetZero()
+		return nil
+	}
+	if dv.Kind() == reflect.Pointer {
+		p := reflect.New(dv.Type().Elem())
+		if err := assign(p.Interface(), src); err != nil {
+			return err
+		}
+		dv.Set(p)
+		return nil
+	}
+	sv := reflect.ValueOf(src)
+	switch {
+	case sv.Type().AssignableTo(dv.Type()):
+		dv.Set(sv)
+	case dv.Kind() == reflect.Interface:
+		dv.Set(sv)
+	case dv.Kind() == reflect.String && sv.Type() == reflect.TypeOf([]byte(nil)):
+		dv.SetString(string(src.([]byte)))
+	case sameKindFamily(sv.Kind(), dv.Kind()) && sv.Type().ConvertibleTo(dv.Type()):
+		dv.Set(sv.Convert(dv.Type()))
+	default:
+		return fmt.Errorf("cannot assign %T to %s", src, dv.Type())
+	}
+	return nil
+}
+
+// sameKindFamily reports whether a and b are both strings, both numbers, or both bools
+func sameKindFamily(a, b reflect.Kind) bool {
+	family := func(k reflect.Kind) int {
+		switch {
+		case k == reflect.String:
+			return 1
+		case k == reflect.Bool:
+			return 2
+		case k >= reflect.Int && k <= reflect.Float64:
+			return 3
+		}
+		return 0
+	}
+	return family(a) != 0 && family(a) == family(b)
+}
@@ -0,0 +1,188 @@
+// Package csqltest provides test doubles for code built on csql.SQLTable
+package csqltest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// Call records a single invocation of a MockTable method
+type Call[T any] struct {
+	// Method is the operation name, e.g. csql.OpQuery, shared by the
+	// plain and Context variants
+	Method string
+	// Query is the SQL text passed to the method
+	Query string
+	// Args holds a copy of the bound arguments
+	Args []any
+	// Rows holds a copy of the rows passed to Transaction
+	Rows []T
+}
+
+// Response is a stubbed method result. Only the fields relevant to the
+// stubbed method are used
+type Response[T any] struct {
+	Rows []T
+	Row  T
+	OK   bool
+	Err  error
+}
+
+// MockTable is a csql.SQLTable that records calls and returns stubbed
+// responses. Responses queued for a method are returned in order, and the
+// last one repeats once the queue is drained. Unstubbed methods return
+// zero values. It is safe for concurrent use
+type MockTable[T any, R csql.Schema[T]] struct {
+	mu        sync.Mutex
+	calls     []Call[T]
+	responses map[string][]Response[T]
+}
+
+var _ csql.SQLTable[nopSchema, *nopSchema] = (*MockTable[nopSchema, *nopSchema])(nil)
+
+// NewMockTable returns an empty MockTable
+func NewMockTable[T any, R csql.Schema[T]]() *MockTable[T, R] {
+	return &MockTable[T, R]{
+		responses: make(map[string][]Response[T]),
+	}
+}
+
+// StubQuery queues a response for Query and QueryContext
+func (m *MockTable[T, R]) StubQuery(rows []T, err error) *MockTable[T, R] {
+	return m.stub(csql.OpQuery, Response[T]{Rows: rows, Err: err})
+}
+
+// StubQueryRow queues a response for QueryRow and QueryRowContext
+func (m *MockTable[T, R]) StubQueryRow(row T, err error) *MockTable[T, R] {
+	return m.stub(csql.OpQueryRow, Response[T]{Row: row, Err: err})
+}
+
+// StubExec queues a response for Exec and ExecContext
+func (m *MockTable[T, R]) StubExec(err error) *MockTable[T, R] {
+	return m.stub(csql.OpExec, Response[T]{Err: err})
+}
+
+// StubTransaction queues a response for Transaction and TransactionContext
+func (m *MockTable[T, R]) StubTransaction(ok bool, err error) *MockTable[T, R] {
+	return m.stub(csql.OpTransaction, Response[T]{OK: ok, Err: err})
+}
+
+func (m *MockTable[T, R]) stub(method string, r Response[T]) *MockTable[T, R] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses[method] = append(m.responses[method], r)
+	return m
+}
+
+// Calls returns the calls recorded so far, in order
+func (m *MockTable[T, R]) Calls() []Call[T] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call[T](nil), m.calls...)
+}
+
+// Reset forgets all recorded calls and stubbed responses
+func (m *MockTable[T, R]) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+	m.responses = make(map[string][]Response[T])
+}
+
+// record stores a call and pops the next response for its method
+func (m *MockTable[T, R]) record(c Call[T]) Response[T] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c.Args != nil {
+		c.Args = append([]any(nil), c.Args...)
+	}
+	if c.Rows != nil {
+		c.Rows = append([]T(nil), c.Rows...)
+	}
+	m.calls = append(m.calls, c)
+	queue := m.responses[c.Method]
+	switch len(queue) {
+	case 0:
+		return Response[T]{}
+	case 1:
+		return queue[0]
+	}
+	m.responses[c.Method] = queue[1:]
+	return queue[0]
+}
+
+func (m *MockTable[T, R]) Query(query string, args ...any) ([]T, error) {
+	return m.QueryContext(context.Background(), query, args...)
+}
+
+func (m *MockTable[T, R]) QueryContext(_ context.Context, query string, args ...any) ([]T, error) {
+	r := m.record(Call[T]{Method: csql.OpQuery, Query: query, Args: args})
+	return r.Rows, r.Err
+}
+
+func (m *MockTable[T, R]) QueryRow(query string, args ...any) (T, error) {
+	return m.QueryRowContext(context.Background(), query, args...)
+}
+
+func (m *MockTable[T, R]) QueryRowContext(_ context.Context, query string, args ...any) (T, error) {
+	r := m.record(Call[T]{Method: csql.OpQueryRow, Query: query, Args: args})
+	return r.Row, r.Err
+}
+
+func (m *MockTable[T, R]) Exec(query string, args ...any) error {
+	return m.ExecContext(context.Background(), query, args...)
+}
+
+func (m *MockTable[T, R]) ExecContext(_ context.Context, query string, args ...any) error {
+	return m.record(Call[T]{Method: csql.OpExec, Query: query, Args: args}).Err
+}
+
+func (m *MockTable[T, R]) Transaction(transaction string, rows []T) (bool, error) {
+	return m.TransactionContext(context.Background(), transaction, rows)
+}
+
+func (m *MockTable[T, R]) TransactionContext(_ context.Context, transaction string, rows []T) (bool, error) {
+	r := m.record(Call[T]{Method: csql.OpTransaction, Query: transaction, Rows: rows})
+	return r.OK, r.Err
+}
+
+// AssertQueried fails t unless Query or QueryRow ran query
+func (m *MockTable[T, R]) AssertQueried(t testing.TB, query string) {
+	t.Helper()
+	m.assertCalled(t, query, csql.OpQuery, csql.OpQueryRow)
+}
+
+// AssertExeced fails t unless Exec ran query
+func (m *MockTable[T, R]) AssertExeced(t testing.TB, query string) {
+	t.Helper()
+	m.assertCalled(t, query, csql.OpExec)
+}
+
+// AssertTransacted fails t unless Transaction ran transaction
+func (m *MockTable[T, R]) AssertTransacted(t testing.TB, transaction string) {
+	t.Helper()
+	m.assertCalled(t, transaction, csql.OpTransaction)
+}
+
+func (m *MockTable[T, R]) assertCalled(t testing.TB, query string, methods ...string) {
+	t.Helper()
+	calls := m.Calls()
+	for _, c := range calls {
+		for _, method := range methods {
+			if c.Method == method && c.Query == query {
+				return
+			}
+		}
+	}
+	t.Errorf("csqltest: no %v call with query %q among %d calls", methods, query, len(calls))
+}
+
+// nopSchema instantiates the interface assertion
+type nopSchema struct{}
+
+func (*nopSchema) ScanRow(csql.RowScanner) error { return nil }
+
+func (*nopSchema) Fields() []any { return nil }
@@ -0,0 +1,111 @@
+package csqltest_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/vtereso/csql"
+	"github.com/vtereso/csql/csqltest"
+)
+
+type row struct {
+	ID int64
+}
+
+func (r *row) ScanRow(s csql.RowScanner) error { return s.Scan(&r.ID) }
+
+func (r *row) Fields() []any { return []any{r.ID} }
+
+// failures is a testing.TB counting the failures reported to it
+type failures struct {
+	testing.TB
+	n int
+}
+
+func (f *failures) Helper() {}
+
+func (f *failures) Errorf(string, ...any) { f.n++ }
+
+func TestMockTableResponses(t *testing.T) {
+	boom := errors.New("boom")
+	var table csql.SQLTable[row, *row] = csqltest.NewMockTable[row, *row]().
+		StubQuery([]row{{1}}, nil).
+		StubQuery(nil, boom).
+		StubExec(boom)
+	mock := table.(*csqltest.MockTable[row, *row])
+	if got, err := table.Query("SELECT 1"); err != nil || len(got) != 1 {
+		t.Fatalf("first Query = %v, %v", got, err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := table.Query("SELECT 2"); err != boom {
+			t.Fatalf("Query %d = %v, want the last response repeated", i+2, err)
+		}
+	}
+	if err := table.Exec("DELETE FROM t"); err != boom {
+		t.Fatalf("Exec = %v, want boom", err)
+	}
+	if got, err := table.QueryRow("SELECT 3"); err != nil || got != (row{}) {
+		t.Fatalf("unstubbed QueryRow = %v, %v, want zero values", got, err)
+	}
+	if ok, err := table.Transaction("INSERT", []row{{1}, {2}}); ok || err != nil {
+		t.Fatalf("unstubbed Transaction = %t, %v, want zero values", ok, err)
+	}
+	calls := mock.Calls()
+	if len(calls) != 6 || calls[5].Method != csql.OpTransaction || len(calls[5].Rows) != 2 {
+		t.Fatalf("calls = %+v", calls)
+	}
+	mock.Reset()
+	if _, err := table.Query("SELECT 1"); err != nil || len(mock.Calls()) != 1 {
+		t.Fatalf("after Reset Query = %v with %d calls", err, len(mock.Calls()))
+	}
+}
+
+func TestMockTableCopiesArgs(t *testing.T) {
+	mock := csqltest.NewMockTable[row, *row]()
+	args := []any{1, 2}
+	rows := []row{{1}}
+	mock.Exec("UPDATE t SET a = ? WHERE b = ?", args...)
+	mock.Transaction("INSERT", rows)
+	args[0], rows[0] = 9, row{9}
+	calls := mock.Calls()
+	if calls[0].Args[0] != 1 || calls[1].Rows[0] != (row{1}) {
+		t.Fatalf("calls = %+v, want the values at call time", calls)
+	}
+}
+
+func TestMockTableAssertions(t *testing.T) {
+	mock := csqltest.NewMockTable[row, *row]()
+	mock.QueryRow("SELECT a")
+	mock.Exec("DELETE b")
+	mock.Transaction("INSERT c", nil)
+	mock.AssertQueried(t, "SELECT a")
+	mock.AssertExeced(t, "DELETE b")
+	mock.AssertTransacted(t, "INSERT c")
+	f := &failures{TB: t}
+	mock.AssertQueried(f, "DELETE b")
+	mock.AssertExeced(f, "SELECT a")
+	mock.AssertTransacted(f, "INSERT d")
+	if f.n != 3 {
+		t.Fatalf("%d assertions failed, want 3", f.n)
+	}
+}
+
+func TestMockTableConcurrent(t *testing.T) {
+	mock := csqltest.NewMockTable[row, *row]().StubExec(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				mock.Exec(fmt.Sprintf("DELETE %d", i), j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := len(mock.Calls()); n != 800 {
+		t.Fatalf("%d calls recorded, want 800", n)
+	}
+}
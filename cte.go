@@ -0,0 +1,99 @@
+package csql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CTE composes a statement led by named common table expressions, as
+// WITH a AS (...), b AS (...) SELECT ..., binding the arguments of each
+// part in order. Parts use ? placeholders, rewritten for the manager's
+// dialect when run. Start one with WithCTE
+type CTE struct {
+	names   []string
+	queries []string
+	args    [][]any
+	main    string
+	mainSet bool
+	mainArg []any
+}
+
+// WithCTE starts a CTE with the expression name AS (query)
+func WithCTE(name, query string, args ...any) *CTE {
+	return new(CTE).With(name, query, args...)
+}
+
+// With adds the expression name AS (query), which may refer to the
+// expressions added before it
+func (c *CTE) With(name, query string, args ...any) *CTE {
+	c.names = append(c.names, name)
+	c.queries = append(c.queries, query)
+	c.args = append(c.args, args)
+	return c
+}
+
+// Select sets the statement the expressions lead, such as SELECT * FROM b
+func (c *CTE) Select(query string, args ...any) *CTE {
+	c.main, c.mainSet, c.mainArg = query, true, args
+	return c
+}
+
+// SQL returns the statement and its arguments in placeholder order. It
+// fails when no statement was set by Select, a name is empty or repeated,
+// or the arguments of a part do not match its placeholders; parts binding
+// sql.NamedArg values are not checked
+func (c *CTE) SQL() (string, []any, error) {
+	if !c.mainSet {
+		return "", nil, errors.New("csql: CTE has no statement; call Select")
+	}
+	var b strings.Builder
+	var args []any
+	seen := make(map[string]bool, len(c.names))
+	for i, name := range c.names {
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			return "", nil, fmt.Errorf("csql: CTE name %q is empty or repeated", name)
+		}
+		seen[key] = true
+		if err := checkPlaceholders(name, c.queries[i], c.args[i]); err != nil {
+			return "", nil, err
+		}
+		if i == 0 {
+			b.WriteString("WITH ")
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(name + " AS (" + strings.TrimRight(c.queries[i], "; \t\r\n") + ")")
+		args = append(args, c.args[i]...)
+	}
+	if err := checkPlaceholders("statement", c.main, c.mainArg); err != nil {
+		return "", nil, err
+	}
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString(c.main)
+	return b.String(), append(args, c.mainArg...), nil
+}
+
+// checkPlaceholders ensures the part of a CTE has an argument per placeholder
+func checkPlaceholders(part, query string, args []any) error {
+	if hasNamed(args) {
+		return nil
+	}
+	if n := countPlaceholders(query); n != len(args) {
+		return fmt.Errorf("csql: CTE %s has %d arguments for %d placeholders", part, len(args), n)
+	}
+	return nil
+}
+
+// QueryCTE runs the statement c composes, like QueryContext
+func (m *SQLTableManager[T, R]) QueryCTE(ctx context.Context, c *CTE) ([]T, error) {
+	query, args, err := c.SQL()
+	if err != nil {
+		return nil, err
+	}
+	return m.QueryContext(ctx, query, args...)
+}
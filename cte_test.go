@@ -0,0 +1,55 @@
+package csql_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestCTE(t *testing.T) {
+	c := csql.WithCTE("low", "SELECT id, name FROM items WHERE id <= ?", 3).
+		With("picked", "SELECT id, name FROM low WHERE name <> ? AND id >= ?;", "item2", 1).
+		Select("SELECT id, name FROM picked WHERE id <> ? ORDER BY id", 1)
+	query, args, err := c.SQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "WITH low AS (SELECT id, name FROM items WHERE id <= ?), " +
+		"picked AS (SELECT id, name FROM low WHERE name <> ? AND id >= ?) " +
+		"SELECT id, name FROM picked WHERE id <> ? ORDER BY id"
+	if query != want {
+		t.Fatalf("SQL = %q, want %q", query, want)
+	}
+	if got := fmt.Sprint(args); got != "[3 item2 1 1]" {
+		t.Fatalf("args = %v, want each part's in order", got)
+	}
+	db := openDB(t)
+	seedItems(t, db, 5)
+	rows, err := csql.NewSQLTableManager[Item](db).QueryCTE(context.Background(), c)
+	if err != nil || len(rows) != 1 || rows[0].ID != 3 {
+		t.Fatalf("QueryCTE = %v, %v, want item3 alone", rows, err)
+	}
+}
+
+func TestCTEInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cte  *csql.CTE
+		want string
+	}{
+		{"no statement", csql.WithCTE("a", "SELECT 1"), "no statement"},
+		{"repeated name", csql.WithCTE("a", "SELECT 1").With("A", "SELECT 2").Select("SELECT * FROM a"), "repeated"},
+		{"missing arg", csql.WithCTE("a", "SELECT ?, ?", 1).Select("SELECT * FROM a"), "CTE a has 1 arguments for 2 placeholders"},
+		{"extra arg", csql.WithCTE("a", "SELECT 1").Select("SELECT * FROM a", 1), "CTE statement has 1 arguments for 0 placeholders"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.cte.SQL(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("SQL = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
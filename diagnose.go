@@ -0,0 +1,70 @@
+package csql
+
+import "fmt"
+
+// ColumnScanError reports the result column a failed scan was traced to
+// under WithScanDiagnostics
+type ColumnScanError struct {
+	// Index is the position of the column in Schema order
+	Index int
+	// Column is the name of the column as the query returned it
+	Column string
+	Err    error
+}
+
+func (e *ColumnScanError) Error() string {
+	return fmt.Sprintf("csql: column %d (%q): %v", e.Index, e.Column, e.Err)
+}
+
+func (e *ColumnScanError) Unwrap() error { return e.Err }
+
+// WithScanDiagnostics traces a row that fails to scan in Query, QueryRow,
+// and their variants back to the column that failed, returning a
+// *ColumnScanError naming it. The row is scanned again once per column,
+// with the other columns scanned into placeholders, so it costs extra
+// scans of every failing row and is meant for debugging. QueryRow reads
+// its row through *sql.Rows under it, to learn the column names
+func WithScanDiagnostics(on bool) Option {
+	return func(o *options) error {
+		o.scanDiagnostics = on
+		return nil
+	}
+}
+
+// diagnoseScanner is the ColumnScanner of WithScanDiagnostics
+type diagnoseScanner struct {
+	r ColumnScanner
+}
+
+// diagnosing wraps r to trace scan failures to a column, or returns r as it
+// is when it does not report its columns
+func diagnosing(r RowScanner) RowScanner {
+	if c, ok := r.(ColumnScanner); ok {
+		return diagnoseScanner{c}
+	}
+	return r
+}
+
+func (s diagnoseScanner) Columns() ([]string, error) { return s.r.Columns() }
+
+func (s diagnoseScanner) Scan(dest ...any) error {
+	err := s.r.Scan(dest...)
+	if err == nil {
+		return nil
+	}
+	cols, colErr := s.r.Columns()
+	if colErr != nil || len(cols) != len(dest) {
+		return err
+	}
+	probe := make([]any, len(dest))
+	for i := range dest {
+		for j := range probe {
+			probe[j] = new(any)
+		}
+		probe[i] = dest[i]
+		if probeErr := s.r.Scan(probe...); probeErr != nil {
+			return &ColumnScanError{Index: i, Column: cols[i], Err: probeErr}
+		}
+	}
+	return err
+}
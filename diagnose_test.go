@@ -0,0 +1,59 @@
+package csql_test
+
+import (
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestScanDiagnostics(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "INSERT INTO items (id, name) VALUES (1, NULL)")
+	m := csql.NewSQLTableManager[Item](db, csql.WithScanDiagnostics(true))
+	want := csql.ColumnScanError{Index: 1, Column: "name"}
+	var ce *csql.ColumnScanError
+	if _, err := m.Query("SELECT id, name FROM items"); !errors.As(err, &ce) || ce.Index != want.Index || ce.Column != want.Column {
+		t.Fatalf("Query = %v, want the name column blamed", err)
+	}
+	if _, err := m.QueryRow("SELECT id, name FROM items"); !errors.As(err, &ce) || ce.Index != want.Index || ce.Column != want.Column {
+		t.Fatalf("QueryRow = %v, want the name column blamed", err)
+	}
+}
+
+// Probe is Item recording the columns its RowScanner reports
+type Probe struct {
+	ID   int64
+	Name string
+	cols []string
+}
+
+func (p *Probe) ScanRow(s csql.RowScanner) error {
+	if c, ok := s.(csql.ColumnScanner); ok {
+		p.cols, _ = c.Columns()
+	}
+	return s.Scan(&p.ID, &p.Name)
+}
+
+func (p *Probe) Fields() []any { return []any{p.ID, p.Name} }
+
+// TestScannerKeepsColumns checks no option wrapping the rows hides their
+// columns from the Schema
+func TestScannerKeepsColumns(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 1)
+	m := csql.NewSQLTableManager[Probe](db,
+		csql.WithScanDiagnostics(true),
+		csql.WithNullAsZero(),
+		csql.WithTypeCodec(func(b []byte) (Code, error) { return Code(b), nil }, func(c Code) (driver.Value, error) { return string(c), nil }),
+		csql.WithColumnCipher([]string{"name"}, reverse, reverse))
+	rows, err := m.Query("SELECT id, name FROM items")
+	if err != nil || len(rows) != 1 {
+		t.Fatal(rows, err)
+	}
+	if !slices.Equal(rows[0].cols, []string{"id", "name"}) {
+		t.Fatalf("ScanRow saw columns %q, want the query's", rows[0].cols)
+	}
+}
@@ -0,0 +1,119 @@
+package csql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect identifies the SQL flavor spoken by the database
+type Dialect int
+
+const (
+	// Generic forwards queries to the driver untouched
+	Generic Dialect = iota
+	// Postgres rewrites ? placeholders to $1, $2, ...
+	Postgres
+	// MySQL uses ? placeholders
+	MySQL
+	// SQLite uses ? placeholders
+	SQLite
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case Generic:
+		return "generic"
+	case Postgres:
+		return "postgres"
+	case MySQL:
+		return "mysql"
+	case SQLite:
+		return "sqlite"
+	}
+	return "Dialect(" + strconv.Itoa(int(d)) + ")"
+}
+
+func (d Dialect) valid() bool {
+	return d >= Generic && d <= SQLite
+}
+
+// WithCallDialect returns a view of the manager using d in place of its
+// WithDialect dialect, for placeholder rewriting and generated SQL alike,
+// so a call can target another database without a manager of its own. It
+// panics if d is not a known dialect
+func (m *SQLTableManager[T, R]) WithCallDialect(d Dialect) *SQLTableManager[T, R] {
+	if !d.valid() {
+		panic(fmt.Sprintf("csql: unknown dialect %v", d))
+	}
+	c := *m
+	c.opts.dialect = d
+	return &c
+}
+
+// maxArgs returns the most placeholders a statement may safely bind: the
+// SQLite default before 3.32 for SQLite and Generic, and the 16-bit limit
+// of the Postgres and MySQL protocols otherwise
+func (d Dialect) maxArgs() int {
+	switch d {
+	case Postgres, MySQL:
+		return 65535
+	}
+	return 999
+}
+
+// Rebind rewrites the ? placeholders of query into the dialect's native
+// form, as managers do, for backends that run SQL outside of csql
+func (d Dialect) Rebind(query string) string {
+	return d.rebind(query)
+}
+
+// countPlaceholders returns the number of ? placeholders in query outside
+// quotes, as rebind rewrites them
+func countPlaceholders(query string) int {
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+		}
+	}
+	return n
+}
+
+// rebind rewrites ? placeholders into the dialect's native form.
+// Question marks inside quoted strings and identifiers are left alone
+func (d Dialect) rebind(query string) string {
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
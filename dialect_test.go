@@ -0,0 +1,52 @@
+package csql_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestWithCallDialect(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	seedItems(t, db, 2)
+	m := csql.NewSQLTableManager[Item](db, csql.WithTable("items"), csql.WithDialect(csql.SQLite))
+	const byID = "SELECT id, name FROM items WHERE id = ? AND name = ?"
+	last := func() string {
+		stmts := rec.Stmts()
+		return stmts[len(stmts)-1].SQL
+	}
+	for _, tt := range []struct {
+		m    *csql.SQLTableManager[Item, *Item]
+		want string
+	}{
+		{m.WithCallDialect(csql.Postgres), "SELECT id, name FROM items WHERE id = $1 AND name = $2"},
+		{m, byID},
+		{m.WithCallDialect(csql.MySQL), byID},
+	} {
+		// sqlite binds both placeholder styles
+		if got, err := tt.m.QueryRow(byID, 2, "item2"); err != nil || got != (Item{2, "item2"}) {
+			t.Fatalf("QueryRow = %v, %v", got, err)
+		}
+		if got := last(); got != tt.want {
+			t.Errorf("sent %q, want %q", got, tt.want)
+		}
+	}
+	// generated SQL follows the call's dialect too
+	if _, err := m.WithCallDialect(csql.Postgres).Select("id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := last(); !strings.HasSuffix(got, "WHERE (id = $1)") {
+		t.Errorf("Select sent %q, want Postgres placeholders", got)
+	}
+	if _, err := m.Search(context.Background(), "name", "item", csql.Contains); err != nil {
+		t.Fatal(err)
+	}
+	if got := last(); !strings.Contains(got, "LIKE ?"+csql.SQLite.LikeEscape()) {
+		t.Errorf("Search sent %q, want the manager's dialect kept", got)
+	}
+	if got := constructPanic(func() { m.WithCallDialect(csql.Dialect(99)) }); !strings.Contains(got, "unknown dialect") {
+		t.Fatalf("WithCallDialect panicked with %q, want the unknown dialect", got)
+	}
+}
@@ -0,0 +1,260 @@
+package csql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"modernc.org/sqlite"
+)
+
+// Statement is a statement the recording driver saw, on the connection
+// Conn. Args are those of executions of stubbed prepared statements, and
+// Named those of statements run directly
+type Statement struct {
+	Conn  int
+	SQL   string
+	Args  []driver.Value
+	Named []driver.NamedValue
+}
+
+// recorder logs the statements of the database openRecorded returns, and
+// stubs those stub matches, which reach sqlite as no-ops. stub may also
+// block to hold a statement in flight. Queries with canned results, as
+// the driver-side procedures sqlite lacks, return them instead, and fail
+// may fail statements, as a flaky connection would
+type recorder struct {
+	mu     sync.Mutex
+	stmts  []Statement
+	conns  int
+	stub   func(ctx context.Context, query string) bool
+	canned map[string]*cannedRows
+	fail   func(query string) error
+}
+
+// Stmts returns the statements seen so far
+func (r *recorder) Stmts() []Statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Statement(nil), r.stmts...)
+}
+
+// Count returns how many statements seen so far start with prefix
+func (r *recorder) Count(prefix string) int {
+	n := 0
+	for _, s := range r.Stmts() {
+		if strings.HasPrefix(s.SQL, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *recorder) record(ctx context.Context, conn int, query string, named ...driver.NamedValue) bool {
+	r.mu.Lock()
+	r.stmts = append(r.stmts, Statement{Conn: conn, SQL: query, Named: named})
+	r.mu.Unlock()
+	return r.stub != nil && r.stub(ctx, query)
+}
+
+func (r *recorder) recordArgs(conn int, query string, args []driver.Value) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stmts = append(r.stmts, Statement{Conn: conn, SQL: query, Args: args})
+}
+
+// failure returns the error fail fails query with, if any
+func (r *recorder) failure(query string) error {
+	if r.fail == nil {
+		return nil
+	}
+	return r.fail(query)
+}
+
+// failFirst returns a fail func failing the first n statements starting
+// with prefix with err
+func failFirst(prefix string, n int, err error) func(string) error {
+	var mu sync.Mutex
+	return func(query string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if n > 0 && strings.HasPrefix(query, prefix) {
+			n--
+			return err
+		}
+		return nil
+	}
+}
+
+// openRecorded is openDB through a driver recording every statement,
+// transaction boundary, and connection into the returned recorder
+func openRecorded(t testing.TB, stub func(ctx context.Context, query string) bool) (*sql.DB, *recorder) {
+	t.Helper()
+	rec := &recorder{stub: stub}
+	db := sql.OpenDB(recConnector{dsn: ":memory:", rec: rec})
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	mustExec(t, db, "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)")
+	return db, rec
+}
+
+type recConnector struct {
+	dsn string
+	rec *recorder
+}
+
+func (c recConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := (&sqlite.Driver{}).Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	c.rec.mu.Lock()
+	c.rec.conns++
+	id := c.rec.conns
+	c.rec.mu.Unlock()
+	return &recConn{conn: conn.(sqliteConn), id: id, rec: c.rec}, nil
+}
+
+func (c recConnector) Driver() driver.Driver { return &sqlite.Driver{} }
+
+// sqliteConn is what the sqlite driver's connections implement
+type sqliteConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+}
+
+type recConn struct {
+	conn sqliteConn
+	id   int
+	rec  *recorder
+}
+
+func (c *recConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *recConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if c.rec.record(ctx, c.id, "PREPARE "+query) {
+		return stubStmt{c: c, query: query}, nil
+	}
+	if err := c.rec.failure("PREPARE " + query); err != nil {
+		return nil, err
+	}
+	return c.conn.PrepareContext(ctx, query)
+}
+
+// stubStmt is a stubbed prepared statement, recording each execution
+type stubStmt struct {
+	c     *recConn
+	query string
+}
+
+func (s stubStmt) Close() error  { return nil }
+func (s stubStmt) NumInput() int { return -1 }
+
+func (s stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.rec.recordArgs(s.c.id, s.query, args)
+	return driver.ResultNoRows, nil
+}
+
+func (s stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.rec.recordArgs(s.c.id, s.query, args)
+	return noRows{}, nil
+}
+
+func (c *recConn) Close() error { return c.conn.Close() }
+
+func (c *recConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *recConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.rec.record(ctx, c.id, "BEGIN")
+	tx, err := c.conn.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return recTx{tx: tx, c: c}, nil
+}
+
+func (c *recConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if c.rec.record(ctx, c.id, query, args...) {
+		return driver.ResultNoRows, nil
+	}
+	if err := c.rec.failure(query); err != nil {
+		return nil, err
+	}
+	return c.conn.ExecContext(ctx, query, args)
+}
+
+func (c *recConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.rec.record(ctx, c.id, query, args...) {
+		return noRows{}, nil
+	}
+	if err := c.rec.failure(query); err != nil {
+		return nil, err
+	}
+	if rows, ok := c.rec.canned[query]; ok {
+		return &cannedRows{cols: rows.cols, rows: rows.rows, next: rows.next}, nil
+	}
+	return c.conn.QueryContext(ctx, query, args)
+}
+
+type recTx struct {
+	tx driver.Tx
+	c  *recConn
+}
+
+func (t recTx) Commit() error {
+	t.c.rec.record(context.Background(), t.c.id, "COMMIT")
+	return t.tx.Commit()
+}
+
+func (t recTx) Rollback() error {
+	t.c.rec.record(context.Background(), t.c.id, "ROLLBACK")
+	return t.tx.Rollback()
+}
+
+// noRows is the result of a stubbed query
+type noRows struct{}
+
+func (noRows) Columns() []string              { return nil }
+func (noRows) Close() error                   { return nil }
+func (noRows) Next(dest []driver.Value) error { return io.EOF }
+
+// cannedRows is a canned query result, read once per query, followed by
+// the result set next when not nil
+type cannedRows struct {
+	cols []string
+	rows [][]driver.Value
+	next *cannedRows
+}
+
+func (r *cannedRows) Columns() []string { return r.cols }
+func (r *cannedRows) Close() error      { return nil }
+
+func (r *cannedRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func (r *cannedRows) HasNextResultSet() bool { return r.next != nil }
+
+func (r *cannedRows) NextResultSet() error {
+	if r.next == nil {
+		return io.EOF
+	}
+	*r = *r.next
+	return nil
+}
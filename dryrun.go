@@ -0,0 +1,19 @@
+package csql
+
+import (
+	"fmt"
+)
+
+// WithDryRun sends writes to capture instead of the database. Exec, the
+// generated writes built on it, and each row of Transaction are captured
+// with their final SQL and args, and report success without running.
+// Transaction reports ok as false since nothing was committed. Reads run normally
+func WithDryRun(capture func(query string, args []any)) Option {
+	return func(o *options) error {
+		if capture == nil {
+			return fmt.Errorf("csql: dry run capture must not be nil")
+		}
+		o.dryRun = capture
+		return nil
+	}
+}
@@ -0,0 +1,65 @@
+package csql_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// capture is a WithDryRun capture keeping each statement with its args,
+// as the driver would be passed them
+type capture struct {
+	lines []string
+}
+
+func (c *capture) record(query string, args []any) {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		v, err := driver.DefaultParameterConverter.ConvertValue(arg)
+		if err != nil {
+			v = err
+		}
+		values[i] = v
+	}
+	c.lines = append(c.lines, fmt.Sprint(query, " ", values))
+}
+
+func TestDryRun(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 1)
+	var c capture
+	var audited int
+	m := csql.NewSQLTableManager[Item](db, csql.WithTable("items"), csql.WithDryRun(c.record),
+		csql.WithAuditHook(func(csql.AuditEvent) { audited++ }))
+	if err := m.Exec(insertItem, 2, "item2"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := m.Transaction(insertItem, []Item{{3, "item3"}}); ok || err != nil {
+		t.Fatalf("Transaction = %t, %v, want not ok and no error", ok, err)
+	}
+	if err := m.Delete("id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := m.InsertManyReturning(context.Background(), "items", []string{"id", "name"}, []Item{{4, "item4"}}, "id"); ids != nil || err != nil {
+		t.Fatalf("InsertManyReturning = %v, %v", ids, err)
+	}
+	want := []string{
+		insertItem + " [2 item2]",
+		insertItem + " [3 item3]",
+		"DELETE FROM items WHERE (id = ?) [1]",
+		"INSERT INTO items (id, name) VALUES (?, ?) RETURNING id [4 item4]",
+	}
+	if fmt.Sprint(c.lines) != fmt.Sprint(want) {
+		t.Fatalf("captured\n%q\nwant\n%q", c.lines, want)
+	}
+	// reads still run
+	if got, err := m.Query(selectItems); err != nil || len(got) != 1 || got[0] != (Item{1, "item1"}) {
+		t.Fatalf("Query = %v, %v, want only the seeded row", got, err)
+	}
+	if audited != 0 {
+		t.Fatalf("audited %d dry-run writes", audited)
+	}
+}
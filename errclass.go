@@ -0,0 +1,202 @@
+package csql
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ErrorClass categorizes database errors across drivers
+type ErrorClass int
+
+const (
+	// UnknownError is any error that could not be classified
+	UnknownError ErrorClass = iota
+	// UniqueViolation is a duplicate key in a unique index or primary key
+	UniqueViolation
+	// ForeignKeyViolation is a missing or still referenced foreign row
+	ForeignKeyViolation
+	// CheckViolation is a failed CHECK constraint
+	CheckViolation
+	// NotNullViolation is a NULL written to a NOT NULL column
+	NotNullViolation
+	// SerializationFailure is a transaction aborted by a serialization
+	// conflict or deadlock, which is usually safe to retry
+	SerializationFailure
+	// Busy is SQLite's database is busy or locked, which clears once the
+	// other connection finishes its write
+	Busy
+)
+
+// IsUniqueViolation reports whether err is a duplicate key error
+func IsUniqueViolation(err error) bool { return Classify(err) == UniqueViolation }
+
+// IsForeignKeyViolation reports whether err is a foreign key error
+func IsForeignKeyViolation(err error) bool { return Classify(err) == ForeignKeyViolation }
+
+// IsCheckViolation reports whether err is a CHECK constraint error
+func IsCheckViolation(err error) bool { return Classify(err) == CheckViolation }
+
+// IsNotNullViolation reports whether err is a NOT NULL constraint error
+func IsNotNullViolation(err error) bool { return Classify(err) == NotNullViolation }
+
+// IsSerializationFailure reports whether err is a serialization failure or deadlock
+func IsSerializationFailure(err error) bool { return Classify(err) == SerializationFailure }
+
+// IsBusy reports whether err is SQLITE_BUSY or SQLITE_LOCKED
+func IsBusy(err error) bool { return Classify(err) == Busy }
+
+var classifiers struct {
+	sync.RWMutex
+	fns []func(error) ErrorClass
+}
+
+// RegisterClassifier adds fn to the classifiers consulted by Classify,
+// ahead of the built-in ones. fn must return UnknownError for errors it
+// does not recognize
+func RegisterClassifier(fn func(error) ErrorClass) {
+	classifiers.Lock()
+	defer classifiers.Unlock()
+	classifiers.fns = append(classifiers.fns, fn)
+}
+
+// Classify inspects the chain of err for a driver error and returns its class.
+// It understands lib/pq and pgx (SQLSTATE), go-sql-driver/mysql (error
+// numbers), and mattn/go-sqlite3 and modernc.org/sqlite (extended result
+// codes) without importing them
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return UnknownError
+	}
+	classifiers.RLock()
+	fns := classifiers.fns
+	classifiers.RUnlock()
+	class := UnknownError
+	walkErrors(err, func(e error) bool {
+		for _, fn := range fns {
+			if class = fn(e); class != UnknownError {
+				return true
+			}
+		}
+		class = classify(e)
+		return class != UnknownError
+	})
+	return class
+}
+
+// walkErrors calls fn on every error in the tree of err until fn returns true
+func walkErrors(err error, fn func(error) bool) bool {
+	if err == nil {
+		return false
+	}
+	if fn(err) {
+		return true
+	}
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		return walkErrors(u.Unwrap(), fn)
+	case interface{ Unwrap() []error }:
+		for _, e := range u.Unwrap() {
+			if walkErrors(e, fn) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// classify recognizes a single driver error. SQLSTATE is standard, so any
+// error reporting one is classified by it, while numeric codes are only
+// read from the error types of the drivers defining them, since other
+// errors, such as those of RPC libraries, number theirs differently
+func classify(err error) ErrorClass {
+	if e, ok := err.(interface{ SQLState() string }); ok {
+		return classifySQLState(e.SQLState())
+	}
+	t := reflect.TypeOf(err)
+	v := reflect.ValueOf(err)
+	for t.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return UnknownError
+		}
+		t, v = t.Elem(), v.Elem()
+	}
+	switch t.PkgPath() {
+	case "modernc.org/sqlite":
+		if e, ok := err.(interface{ Code() int }); ok {
+			return classifySQLite(int64(e.Code()))
+		}
+	case "github.com/mattn/go-sqlite3":
+		if n, ok := intField(v, "ExtendedCode"); ok {
+			return classifySQLite(n)
+		}
+	case "github.com/go-sql-driver/mysql":
+		if n, ok := intField(v, "Number"); ok {
+			return classifyMySQL(n)
+		}
+	}
+	return UnknownError
+}
+
+func intField(v reflect.Value, name string) (int64, bool) {
+	if v.Kind() != reflect.Struct {
+		return 0, false
+	}
+	f := v.FieldByName(name)
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return f.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(f.Uint()), true
+	}
+	return 0, false
+}
+
+func classifySQLState(state string) ErrorClass {
+	switch state {
+	case "23505":
+		return UniqueViolation
+	case "23503":
+		return ForeignKeyViolation
+	case "23514":
+		return CheckViolation
+	case "23502":
+		return NotNullViolation
+	case "40001", "40P01":
+		return SerializationFailure
+	}
+	return UnknownError
+}
+
+func classifyMySQL(number int64) ErrorClass {
+	switch number {
+	case 1062, 1586:
+		return UniqueViolation
+	case 1216, 1217, 1451, 1452:
+		return ForeignKeyViolation
+	case 3819:
+		return CheckViolation
+	case 1048, 1364:
+		return NotNullViolation
+	case 1213:
+		return SerializationFailure
+	}
+	return UnknownError
+}
+
+func classifySQLite(code int64) ErrorClass {
+	switch code {
+	case 2067, 1555: // SQLITE_CONSTRAINT_UNIQUE, SQLITE_CONSTRAINT_PRIMARYKEY
+		return UniqueViolation
+	case 787: // SQLITE_CONSTRAINT_FOREIGNKEY
+		return ForeignKeyViolation
+	case 275: // SQLITE_CONSTRAINT_CHECK
+		return CheckViolation
+	case 1299: // SQLITE_CONSTRAINT_NOTNULL
+		return NotNullViolation
+	}
+	switch code & 0xff {
+	case 5, 6: // SQLITE_BUSY, SQLITE_LOCKED and their extended codes
+		return Busy
+	}
+	return UnknownError
+}
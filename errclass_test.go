@@ -0,0 +1,109 @@
+package csql_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// stateError is a driver error reporting a SQLSTATE, as pgx and lib/pq do
+type stateError string
+
+func (e stateError) Error() string    { return "state " + string(e) }
+func (e stateError) SQLState() string { return string(e) }
+
+// codeError is a non-driver error numbering itself, as RPC status errors do
+type codeError int
+
+func (e codeError) Error() string { return fmt.Sprintf("code %d", int(e)) }
+func (e codeError) Code() int     { return int(e) }
+
+// numberedError carries a code only as a struct field, like a driver's
+type numberedError struct {
+	Number       uint16
+	ExtendedCode int
+}
+
+func (e *numberedError) Error() string { return "numbered" }
+
+// registeredError is classified only by the classifier its test registers
+type registeredError struct{}
+
+func (registeredError) Error() string { return "registered" }
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want csql.ErrorClass
+	}{
+		{"nil", nil, csql.UnknownError},
+		{"plain", errors.New("boom"), csql.UnknownError},
+		{"unique state", stateError("23505"), csql.UniqueViolation},
+		{"foreign key state", stateError("23503"), csql.ForeignKeyViolation},
+		{"check state", stateError("23514"), csql.CheckViolation},
+		{"not null state", stateError("23502"), csql.NotNullViolation},
+		{"serialization state", stateError("40001"), csql.SerializationFailure},
+		{"deadlock state", stateError("40P01"), csql.SerializationFailure},
+		{"other state", stateError("42601"), csql.UnknownError},
+		{"wrapped state", fmt.Errorf("insert: %w", stateError("23505")), csql.UniqueViolation},
+		{"joined state", errors.Join(errors.New("rollback"), stateError("23503")), csql.ForeignKeyViolation},
+		{"foreign code", codeError(2067), csql.UnknownError},
+		{"foreign busy code", codeError(5), csql.UnknownError},
+		{"foreign fields", &numberedError{Number: 1062, ExtendedCode: 2067}, csql.UnknownError},
+		{"nil pointer", (*numberedError)(nil), csql.UnknownError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csql.Classify(tt.err); got != tt.want {
+				t.Fatalf("Classify(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifySQLite(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "PRAGMA foreign_keys = ON")
+	mustExec(t, db, "CREATE TABLE tags (id INTEGER PRIMARY KEY, item_id INTEGER NOT NULL REFERENCES items (id), label TEXT UNIQUE CHECK (label <> ''))")
+	mustExec(t, db, "INSERT INTO items (id, name) VALUES (1, 'item1')")
+	mustExec(t, db, "INSERT INTO tags (id, item_id, label) VALUES (1, 1, 'a')")
+	tests := []struct {
+		name  string
+		query string
+		want  csql.ErrorClass
+		is    func(error) bool
+	}{
+		{"primary key", "INSERT INTO tags (id, item_id, label) VALUES (1, 1, 'b')", csql.UniqueViolation, csql.IsUniqueViolation},
+		{"unique", "INSERT INTO tags (id, item_id, label) VALUES (2, 1, 'a')", csql.UniqueViolation, csql.IsUniqueViolation},
+		{"foreign key", "INSERT INTO tags (id, item_id, label) VALUES (2, 9, 'b')", csql.ForeignKeyViolation, csql.IsForeignKeyViolation},
+		{"check", "INSERT INTO tags (id, item_id, label) VALUES (2, 1, '')", csql.CheckViolation, csql.IsCheckViolation},
+		{"not null", "INSERT INTO tags (id, item_id, label) VALUES (2, NULL, 'b')", csql.NotNullViolation, csql.IsNotNullViolation},
+	}
+	m := csql.NewSQLTableManager[Item](db)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.Exec(tt.query)
+			if got := csql.Classify(err); got != tt.want || !tt.is(err) {
+				t.Fatalf("Classify(%v) = %v, want %v", err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterClassifier(t *testing.T) {
+	csql.RegisterClassifier(func(err error) csql.ErrorClass {
+		if _, ok := err.(registeredError); ok {
+			return csql.SerializationFailure
+		}
+		return csql.UnknownError
+	})
+	if got := csql.Classify(fmt.Errorf("tx: %w", registeredError{})); got != csql.SerializationFailure {
+		t.Fatalf("Classify = %v, want the registered class", got)
+	}
+	if got := csql.Classify(stateError("23505")); got != csql.UniqueViolation {
+		t.Fatalf("Classify = %v, want the built-in classes kept", got)
+	}
+}
@@ -0,0 +1,50 @@
+package csql_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/vtereso/csql"
+)
+
+// tracing is a middleware written outside the package, as a tracer would
+// be, reporting each call as a span
+func tracing[T any, R csql.Schema[T]](service string) csql.Middleware[T, R] {
+	return csql.Around[T, R](func(ctx context.Context, call csql.Call, next func(context.Context) (int64, error)) error {
+		fmt.Printf("span %s.%s start: %s\n", service, call.Op, call.SQL)
+		rows, err := next(ctx)
+		fmt.Printf("span %s.%s end: rows=%d err=%v\n", service, call.Op, rows, err)
+		return err
+	})
+}
+
+func ExampleAround() {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	table := csql.Chain[Item](csql.NewSQLTableManager[Item](db), tracing[Item]("items"))
+	if err := table.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := table.Transaction("INSERT INTO items (id, name) VALUES (?, ?)", []Item{{1, "one"}, {2, "two"}}); err != nil {
+		log.Fatal(err)
+	}
+	rows, err := table.Query("SELECT id, name FROM items ORDER BY id")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(rows)
+	// Output:
+	// span items.Exec start: CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)
+	// span items.Exec end: rows=-1 err=<nil>
+	// span items.Transaction start: INSERT INTO items (id, name) VALUES (?, ?)
+	// span items.Transaction end: rows=2 err=<nil>
+	// span items.Query start: SELECT id, name FROM items ORDER BY id
+	// span items.Query end: rows=2 err=<nil>
+	// [{1 one} {2 two}]
+}
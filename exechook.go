@@ -0,0 +1,86 @@
+package csql
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExecInfo describes a write about to run, for ExecHook
+type ExecInfo struct {
+	// Method is the manager method, e.g. Exec, Transaction, Insert, Update, or Delete
+	Method string
+	// Table is the WithTable table, if any
+	Table string
+	// SQL is the statement as sent to the driver; batches join theirs with "; "
+	SQL string
+	// Args are the bound arguments as returned by the WithExecArgRedactor
+	// function, and nil without one or for transactions and batches
+	Args []any
+	// Rows is the number of rows of a Transaction, -1 when they are
+	// streamed, the number of statements of a batch, and 0 for a single
+	// statement
+	Rows int
+}
+
+// ExecHook is called around every write a manager makes. An error from
+// BeforeExec aborts the write and is returned to the caller; the context
+// it returns is passed to the driver and to AfterExec
+type ExecHook interface {
+	BeforeExec(ctx context.Context, info ExecInfo) (context.Context, error)
+	AfterExec(ctx context.Context, info ExecInfo, err error)
+}
+
+// WithExecHooks adds hooks around Exec, the Transaction variants, ExecBatch,
+// ExecScript, and the generated Insert, Update, and Delete statements.
+// Writes under WithDryRun and TransactionDryRun are not reported.
+// BeforeExec runs in the order hooks were added, and AfterExec in reverse
+func WithExecHooks(hooks ...ExecHook) Option {
+	return func(o *options) error {
+		for _, h := range hooks {
+			if h == nil {
+				return fmt.Errorf("csql: exec hook must not be nil")
+			}
+		}
+		o.execHooks = append(o.execHooks, hooks...)
+		return nil
+	}
+}
+
+// WithExecArgRedactor passes a copy of the bound arguments of every write
+// through fn before ExecHook sees them. Without it the hooks get no arguments
+func WithExecArgRedactor(fn func(args []any) []any) Option {
+	return func(o *options) error {
+		o.execRedact = fn
+		return nil
+	}
+}
+
+// beforeExec runs the BeforeExec hooks for a write of method, returning the
+// context to run it with and a func to defer that runs the AfterExec hooks
+// with its error. A hook failing aborts the write, after unwinding the
+// hooks that already ran
+func (o *options) beforeExec(ctx context.Context, method, query string, args []any, rows int) (context.Context, func(*error), error) {
+	if len(o.execHooks) == 0 {
+		return ctx, func(*error) {}, nil
+	}
+	info := ExecInfo{Method: method, Table: o.table, SQL: query, Rows: rows}
+	if o.execRedact != nil && args != nil {
+		info.Args = o.execRedact(append([]any(nil), o.redactArgs(ctx, args)...))
+	}
+	ran := 0
+	after := func(err *error) {
+		for i := ran - 1; i >= 0; i-- {
+			o.execHooks[i].AfterExec(ctx, info, *err)
+		}
+	}
+	for _, h := range o.execHooks {
+		next, err := h.BeforeExec(ctx, info)
+		if err != nil {
+			after(&err)
+			return ctx, nil, err
+		}
+		ctx = next
+		ran++
+	}
+	return ctx, after, nil
+}
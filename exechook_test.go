@@ -0,0 +1,116 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// execLog is an ExecHook appending what it sees to lines, as "name before
+// SQL" and "name after SQL: err", failing BeforeExec with fail when set
+type execLog struct {
+	name  string
+	fail  error
+	lines *[]string
+}
+
+func (h execLog) BeforeExec(ctx context.Context, info csql.ExecInfo) (context.Context, error) {
+	*h.lines = append(*h.lines, fmt.Sprintf("%s before %s", h.name, info.SQL))
+	return ctx, h.fail
+}
+
+func (h execLog) AfterExec(_ context.Context, info csql.ExecInfo, err error) {
+	*h.lines = append(*h.lines, fmt.Sprintf("%s after %s: %v", h.name, info.SQL, err))
+}
+
+// auditIDKey is the context key auditID attaches its id under
+type auditIDKey struct{}
+
+// auditID is an ExecHook attaching an audit id to the write's context and
+// keeping the ExecInfo it was given
+type auditID struct {
+	infos *[]csql.ExecInfo
+}
+
+func (h auditID) BeforeExec(ctx context.Context, info csql.ExecInfo) (context.Context, error) {
+	*h.infos = append(*h.infos, info)
+	return context.WithValue(ctx, auditIDKey{}, "audit-1"), nil
+}
+
+func (auditID) AfterExec(context.Context, csql.ExecInfo, error) {}
+
+func TestExecHooksOrder(t *testing.T) {
+	db := openDB(t)
+	var lines []string
+	m := csql.NewSQLTableManager[Item](db, csql.WithExecHooks(execLog{name: "a", lines: &lines}, execLog{name: "b", lines: &lines}))
+	if err := m.Exec(insertItem, 1, "item1"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"a before " + insertItem,
+		"b before " + insertItem,
+		"b after " + insertItem + ": <nil>",
+		"a after " + insertItem + ": <nil>",
+	}
+	if fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Fatalf("hooks ran\n%q\nwant\n%q", lines, want)
+	}
+	lines = nil
+	if _, err := m.Query(selectItems); err != nil || lines != nil {
+		t.Fatalf("Query ran exec hooks %q, %v, want reads skipped", lines, err)
+	}
+}
+
+func TestExecHooksAbort(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	readOnly := errors.New("read-only mode")
+	var lines []string
+	m := csql.NewSQLTableManager[Item](db, csql.WithExecHooks(execLog{name: "a", lines: &lines}, execLog{name: "gate", fail: readOnly, lines: &lines}, execLog{name: "c", lines: &lines}))
+	if err := m.Exec(insertItem, 1, "item1"); !errors.Is(err, readOnly) {
+		t.Fatalf("Exec = %v, want the hook's error", err)
+	}
+	if _, err := m.Transaction(insertItem, items(2)); !errors.Is(err, readOnly) {
+		t.Fatalf("Transaction = %v, want the hook's error", err)
+	}
+	if n := rec.Count("INSERT") + rec.Count("BEGIN"); n != 0 {
+		t.Fatalf("sent %d statements, want the writes aborted", n)
+	}
+	// the hooks that already ran are unwound, the later ones never run
+	want := fmt.Sprint([]string{"a before " + insertItem, "gate before " + insertItem, "a after " + insertItem + ": " + readOnly.Error()})
+	if got := fmt.Sprint(lines[:3]); got != want {
+		t.Fatalf("hooks ran %s, want %s", got, want)
+	}
+}
+
+func TestExecHooksContext(t *testing.T) {
+	var seen []any
+	db, _ := openRecorded(t, func(ctx context.Context, query string) bool {
+		if strings.HasPrefix(query, "INSERT") || strings.HasPrefix(query, "PREPARE INSERT") {
+			seen = append(seen, ctx.Value(auditIDKey{}))
+		}
+		return false
+	})
+	var infos []csql.ExecInfo
+	m := csql.NewSQLTableManager[Item](db, csql.WithTable("items"), csql.WithExecHooks(auditID{&infos}),
+		csql.WithExecArgRedactor(func(args []any) []any { return args[:1] }))
+	if err := m.Exec(insertItem, 1, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Transaction(insertItem, []Item{{2, "b"}, {3, "c"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 2 || seen[0] != "audit-1" || seen[1] != "audit-1" {
+		t.Fatalf("driver saw audit ids %v, want the hook's context", seen)
+	}
+	want := []csql.ExecInfo{
+		{Method: "Exec", Table: "items", SQL: insertItem, Args: []any{1}},
+		{Method: "Transaction", Table: "items", SQL: insertItem, Rows: 2},
+	}
+	if fmt.Sprint(infos) != fmt.Sprint(want) {
+		t.Fatalf("hook saw %+v, want %+v", infos, want)
+	}
+}
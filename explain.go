@@ -0,0 +1,152 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNotExplainable is returned when explaining a statement other than a
+// SELECT, WITH, UPDATE, or DELETE
+var ErrNotExplainable = errors.New("csql: statement cannot be explained")
+
+// Explain returns the database's plan for query, one line per plan row.
+// The statement itself is not run. Postgres and MySQL use EXPLAIN and
+// SQLite uses EXPLAIN QUERY PLAN; Generic has no known form
+func (m *SQLTableManager[T, R]) Explain(ctx context.Context, query string, args ...any) ([]string, error) {
+	return m.explain(ctx, query, args, false)
+}
+
+// ExplainAnalyze is Explain using EXPLAIN ANALYZE, which runs the statement
+// to report actual costs, so an UPDATE or DELETE takes effect. As a guard,
+// it refuses to run unless execute is set. SQLite has no EXPLAIN ANALYZE
+func (m *SQLTableManager[T, R]) ExplainAnalyze(ctx context.Context, execute bool, query string, args ...any) ([]string, error) {
+	if !execute {
+		return nil, errors.New("csql: ExplainAnalyze runs the statement; set execute to confirm")
+	}
+	return m.explain(ctx, query, args, true)
+}
+
+func (m *SQLTableManager[T, R]) explain(ctx context.Context, query string, args []any, analyze bool) ([]string, error) {
+	prefix, err := explainPrefix(m.opts.dialect, query, analyze)
+	if err != nil {
+		return nil, err
+	}
+	var plan []string
+	err = m.queryEach(ctx, prefix+query, args, func(rows *sql.Rows) error {
+		line, err := planLine(rows)
+		plan = append(plan, line)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// WithSlowQueryExplain fills SlowQuery.Plan with the plan of slow queries.
+// The plan is fetched, without analyzing, on the calling goroutine before
+// the WithSlowQueryThreshold callback runs, and given no longer than the
+// threshold, or a second for thresholds below that. Statements that cannot
+// be explained, such as a Transaction, are passed without a plan, as are
+// the statements of managers from WithConn and Bind, whose session state
+// or uncommitted writes a pooled connection would not see, and which would
+// wait on the pool for a connection they may hold the last of
+func WithSlowQueryExplain() Option {
+	return func(o *options) error {
+		o.explainSlow = true
+		return nil
+	}
+}
+
+// minExplainTimeout is the least time slowPlan gives a plan
+const minExplainTimeout = time.Second
+
+// slowPlan returns the plan of the slow query, which was already
+// finalized, or nil when it cannot be explained
+func (o *options) slowPlan(ctx context.Context, op, query string, args []any) []string {
+	if o.explainDB == nil || op == OpTransaction {
+		return nil
+	}
+	prefix, err := explainPrefix(o.dialect, query, false)
+	if err != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), max(o.slowThreshold, minExplainTimeout))
+	defer cancel()
+	rows, err := o.explainDB.QueryContext(ctx, prefix+query, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var plan []string
+	for rows.Next() {
+		line, err := planLine(rows)
+		if err != nil {
+			return nil
+		}
+		plan = append(plan, line)
+	}
+	if rows.Err() != nil {
+		return nil
+	}
+	return plan
+}
+
+// explainPrefix returns the dialect's EXPLAIN keywords for query
+func explainPrefix(d Dialect, query string, analyze bool) (string, error) {
+	words := strings.Fields(strings.TrimLeft(query, " \t\r\n("))
+	if len(words) == 0 {
+		return "", ErrNotExplainable
+	}
+	switch strings.ToUpper(words[0]) {
+	case "SELECT", "WITH", "UPDATE", "DELETE":
+	default:
+		return "", ErrNotExplainable
+	}
+	switch {
+	case d == Postgres || d == MySQL:
+		if analyze {
+			return "EXPLAIN ANALYZE ", nil
+		}
+		return "EXPLAIN ", nil
+	case d == SQLite && !analyze:
+		return "EXPLAIN QUERY PLAN ", nil
+	case analyze:
+		return "", fmt.Errorf("csql: EXPLAIN ANALYZE is not supported by the %s dialect", d)
+	}
+	return "", fmt.Errorf("csql: EXPLAIN is not supported by the %s dialect", d)
+}
+
+// planLine formats a plan row: its only column, SQLite's detail column, or
+// else each non-NULL column as name=value
+func planLine(rows *sql.Rows) (string, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	values := make([]sql.NullString, len(cols))
+	dest := make([]any, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return "", err
+	}
+	if len(cols) == 1 {
+		return values[0].String, nil
+	}
+	if i := indexFold(cols, "detail"); i >= 0 {
+		return values[i].String, nil
+	}
+	parts := make([]string, 0, len(cols))
+	for i, v := range values {
+		if v.Valid {
+			parts = append(parts, cols[i]+"="+v.String)
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
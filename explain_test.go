@@ -0,0 +1,73 @@
+package csql_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+// hungExplain holds SELECTs for a moment, so they are slow, and EXPLAINs
+// until canceled
+func hungExplain(ctx context.Context, query string) bool {
+	switch {
+	case strings.HasPrefix(query, "EXPLAIN"):
+		<-ctx.Done()
+		return true
+	case strings.HasPrefix(query, "SELECT"):
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
+
+func TestSlowQueryExplain(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 1)
+	var slow []csql.SlowQuery
+	m := csql.NewSQLTableManager[Item](db, csql.WithDialect(csql.SQLite), csql.WithSlowQueryExplain(),
+		csql.WithSlowQueryThreshold(time.Nanosecond, func(q csql.SlowQuery) { slow = append(slow, q) }))
+	if _, err := m.Query(selectItems); err != nil {
+		t.Fatal(err)
+	}
+	if len(slow) != 1 || len(slow[0].Plan) == 0 {
+		t.Fatalf("slow queries = %+v, want one with a plan", slow)
+	}
+}
+
+func TestSlowQueryExplainBounded(t *testing.T) {
+	db, rec := openRecorded(t, hungExplain)
+	seedItems(t, db, 1)
+	var slow []csql.SlowQuery
+	m := csql.NewSQLTableManager[Item](db, csql.WithDialect(csql.SQLite), csql.WithSlowQueryExplain(),
+		csql.WithSlowQueryThreshold(time.Millisecond, func(q csql.SlowQuery) { slow = append(slow, q) }))
+	start := time.Now()
+	if _, err := m.Query(selectItems); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("Query took %v behind a hung EXPLAIN", elapsed)
+	}
+	if len(slow) != 1 || slow[0].Plan != nil || rec.Count("EXPLAIN") != 1 {
+		t.Fatalf("slow queries = %+v, want one without a plan", slow)
+	}
+}
+
+func TestSlowQueryExplainPinned(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	seedItems(t, db, 1)
+	var slow []csql.SlowQuery
+	m := csql.NewSQLTableManager[Item](db, csql.WithDialect(csql.SQLite), csql.WithSlowQueryExplain(),
+		csql.WithSlowQueryThreshold(time.Nanosecond, func(q csql.SlowQuery) { slow = append(slow, q) }))
+	err := m.WithConn(context.Background(), func(conn *csql.SQLTableManager[Item, *Item]) error {
+		_, err := conn.Query(selectItems)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(slow) != 1 || slow[0].Plan != nil || rec.Count("EXPLAIN") != 0 {
+		t.Fatalf("slow queries = %+v, want one left unexplained", slow)
+	}
+}
@@ -0,0 +1,243 @@
+package csql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// ExportCSV streams the rows of query to w as CSV without collecting them,
+// writing each record as it is read. The header holds the Schema's column
+// names, or the query's when the Schema does not name them. Fields are
+// written as RFC 3339 for times, base64 for byte slices, and empty for NULL
+func (m *SQLTableManager[T, R]) ExportCSV(ctx context.Context, w io.Writer, query string, args ...any) error {
+	cw := csv.NewWriter(w)
+	header := m.columns()
+	if len(header) > 0 {
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+	}
+	var record []string
+	err := m.exportEach(ctx, query, args, func(names []string, fields []any) error {
+		if header == nil {
+			header = names
+			if err := cw.Write(header); err != nil {
+				return err
+			}
+		}
+		record = record[:0]
+		for _, f := range fields {
+			v, err := exportValue(f)
+			if err != nil {
+				return err
+			}
+			record = append(record, csvField(v))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportJSON streams the rows of query to w as a JSON array of objects
+// without collecting them, writing each object as it is read. Keys are
+// the Schema's column names, or the query's when the Schema does not name
+// them, in column order. Fields implementing json.Marshaler are encoded
+// as such, and others by their driver value
+func (m *SQLTableManager[T, R]) ExportJSON(ctx context.Context, w io.Writer, query string, args ...any) error {
+	var keys [][]byte
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	err := m.exportEach(ctx, query, args, func(names []string, fields []any) error {
+		if keys == nil {
+			keys = jsonKeys(names)
+		} else {
+			buf.WriteByte(',')
+		}
+		if err := appendJSONObject(&buf, keys, fields); err != nil {
+			return err
+		}
+		_, err := w.Write(buf.Bytes())
+		buf.Reset()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	buf.WriteString("]\n")
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// ndjsonFlushEvery is how many rows QueryNDJSON writes between flushes
+const ndjsonFlushEvery = 64
+
+// QueryNDJSON streams the rows of query to w as newline-delimited JSON
+// without collecting them, writing each row as one object, keyed and
+// encoded as by ExportJSON, followed by a newline. When w has a Flush
+// method, such as an http.ResponseWriter's http.Flusher or a
+// *bufio.Writer, it is flushed every few rows and at the end so clients
+// receive rows as they are read. A failed write stops the query
+func (m *SQLTableManager[T, R]) QueryNDJSON(ctx context.Context, w io.Writer, query string, args ...any) error {
+	var keys [][]byte
+	var buf bytes.Buffer
+	n := 0
+	err := m.exportEach(ctx, query, args, func(names []string, fields []any) error {
+		if keys == nil {
+			keys = jsonKeys(names)
+		}
+		if err := appendJSONObject(&buf, keys, fields); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		_, err := w.Write(buf.Bytes())
+		buf.Reset()
+		if err != nil {
+			return err
+		}
+		if n++; n%ndjsonFlushEvery == 0 {
+			return flush(w)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush(w)
+}
+
+// flush flushes w when it has a Flush method
+func flush(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return nil
+}
+
+// jsonKeys encodes the column names of an exported object
+func jsonKeys(names []string) [][]byte {
+	keys := make([][]byte, len(names))
+	for i, name := range names {
+		keys[i], _ = json.Marshal(name)
+	}
+	return keys
+}
+
+// appendJSONObject writes the object of a row's fields under keys to buf
+func appendJSONObject(buf *bytes.Buffer, keys [][]byte, fields []any) error {
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(keys[i])
+		buf.WriteByte(':')
+		v, err := jsonField(f)
+		if err != nil {
+			return fmt.Errorf("csql: encoding column %s: %w", keys[i], err)
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// exportEach runs query and calls fn with the column names and Fields of
+// each row, scanned through the Schema one at a time
+func (m *SQLTableManager[T, R]) exportEach(ctx context.Context, query string, args []any, fn func(names []string, fields []any) error) error {
+	names := m.schemaColumns()
+	var scanner RowScanner
+	var header []string
+	return m.queryEach(ctx, query, args, func(queryRows *sql.Rows) (err error) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if scanner == nil {
+			if scanner, err = m.opts.columnScanner(queryRows, names); err != nil {
+				return err
+			}
+		}
+		var row T
+		if err := m.scanRow(scanner, &row); err != nil {
+			return err
+		}
+		fields := R(&row).Fields()
+		if header == nil {
+			if header = m.columns(); len(header) != len(fields) {
+				if header, err = queryRows.Columns(); err != nil {
+					return err
+				}
+			}
+			if len(header) != len(fields) {
+				return &ColumnCountError{Schema: len(fields), Query: len(header)}
+			}
+		}
+		return fn(header, fields)
+	})
+}
+
+// exportValue returns the driver value of the field f, nil for NULL
+func exportValue(f any) (any, error) {
+	rv := reflect.ValueOf(f)
+	for ; rv.Kind() == reflect.Pointer; rv = rv.Elem() {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if v, ok := rv.Interface().(driver.Valuer); ok {
+			return v.Value()
+		}
+	}
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	if v, ok := rv.Interface().(driver.Valuer); ok {
+		return v.Value()
+	}
+	return rv.Interface(), nil
+}
+
+// csvField formats the driver value v for ExportCSV
+func csvField(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// jsonField encodes the field f for ExportJSON
+func jsonField(f any) ([]byte, error) {
+	if _, ok := f.(json.Marshaler); ok {
+		return json.Marshal(f)
+	}
+	v, err := exportValue(f)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
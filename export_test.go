@@ -0,0 +1,176 @@
+package csql_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+// flushBuffer is a bytes.Buffer counting its flushes
+type flushBuffer struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (b *flushBuffer) Flush() { b.flushes++ }
+
+func TestQueryNDJSON(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 3)
+	m := csql.NewSQLTableManager[NamedItem](db)
+	var w flushBuffer
+	if err := m.QueryNDJSON(context.Background(), &w, "SELECT id, name FROM items ORDER BY id"); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":1,"name":"item1"}
+{"id":2,"name":"item2"}
+{"id":3,"name":"item3"}
+`
+	if got := w.String(); got != want {
+		t.Fatalf("QueryNDJSON wrote\n%s\nwant\n%s", got, want)
+	}
+	if w.flushes != 1 {
+		t.Fatalf("QueryNDJSON flushed %d times, want once at the end", w.flushes)
+	}
+}
+
+// Dump is a reflected Schema with each kind of field the exports format
+type Dump struct {
+	ID   int64          `csql:"id"`
+	Name string         `csql:"name"`
+	Data []byte         `csql:"data"`
+	At   time.Time      `csql:"at"`
+	Note sql.NullString `csql:"note"`
+}
+
+func (d *Dump) ScanRow(r csql.RowScanner) error { return csql.ScanInto(r, d) }
+
+func (d *Dump) Fields() []any { return csql.ReflectFields(d) }
+
+// exportRows is how many rows the export tests stream
+const exportRows = 3000
+
+// dumpAt is the time of every exported row
+var dumpAt = time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+// openDumps returns a manager of exportRows dumps, every other one with a note
+func openDumps(t *testing.T) *csql.SQLTableManager[Dump, *Dump] {
+	t.Helper()
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE dumps (id INTEGER PRIMARY KEY, name TEXT, data BLOB, at DATETIME, note TEXT)")
+	rows := make([]Dump, exportRows)
+	for i := range rows {
+		rows[i] = Dump{ID: int64(i + 1), Name: fmt.Sprintf("a,\"b\" %d", i+1), Data: []byte{byte(i), 0xff}, At: dumpAt}
+		if i%2 == 0 {
+			rows[i].Note = sql.NullString{String: "n", Valid: true}
+		}
+	}
+	m := csql.NewSQLTableManager[Dump](db)
+	if _, err := m.Transaction("INSERT INTO dumps (id, name, data, at, note) VALUES (?, ?, ?, ?, ?)", rows); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// countingWriter is a bytes.Buffer counting its writes
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+const selectDumps = "SELECT id, name, data, at, note FROM dumps ORDER BY id"
+
+func TestExportCSV(t *testing.T) {
+	m := openDumps(t)
+	var w countingWriter
+	if err := m.ExportCSV(context.Background(), &w, selectDumps); err != nil {
+		t.Fatal(err)
+	}
+	if w.writes < exportRows {
+		t.Fatalf("ExportCSV wrote %d times, want a write per row", w.writes)
+	}
+	records, err := csv.NewReader(&w).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != exportRows+1 || fmt.Sprint(records[0]) != "[id name data at note]" {
+		t.Fatalf("parsed %d records headed %q, want a header and %d rows", len(records), records[0], exportRows)
+	}
+	for i, rec := range records[1:] {
+		want := []string{fmt.Sprint(i + 1), fmt.Sprintf("a,\"b\" %d", i+1), base64.StdEncoding.EncodeToString([]byte{byte(i), 0xff}), dumpAt.Format(time.RFC3339), ""}
+		if i%2 == 0 {
+			want[4] = "n"
+		}
+		if !slices.Equal(rec, want) {
+			t.Fatalf("record %d = %q, want %q", i+1, rec, want)
+		}
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	m := openDumps(t)
+	var w countingWriter
+	if err := m.ExportJSON(context.Background(), &w, selectDumps); err != nil {
+		t.Fatal(err)
+	}
+	if w.writes < exportRows {
+		t.Fatalf("ExportJSON wrote %d times, want a write per row", w.writes)
+	}
+	var objects []map[string]any
+	if err := json.Unmarshal(w.Bytes(), &objects); err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != exportRows {
+		t.Fatalf("parsed %d objects, want %d", len(objects), exportRows)
+	}
+	for i, o := range objects {
+		want := map[string]any{"id": float64(i + 1), "name": fmt.Sprintf("a,\"b\" %d", i+1), "data": base64.StdEncoding.EncodeToString([]byte{byte(i), 0xff}), "at": dumpAt.Format(time.RFC3339), "note": nil}
+		if i%2 == 0 {
+			want["note"] = "n"
+		}
+		if !maps.Equal(o, want) {
+			t.Fatalf("object %d = %v, want %v", i, o, want)
+		}
+	}
+	var empty bytes.Buffer
+	if err := m.ExportJSON(context.Background(), &empty, selectDumps+" LIMIT 0"); err != nil || empty.String() != "[]\n" {
+		t.Fatalf("ExportJSON of no rows = %q, %v", empty.String(), err)
+	}
+}
+
+// cancelingWriter cancels its context on the first write
+type cancelingWriter struct {
+	cancel context.CancelFunc
+}
+
+func (w cancelingWriter) Write(p []byte) (int, error) {
+	w.cancel()
+	return len(p), nil
+}
+
+func TestExportCanceled(t *testing.T) {
+	m := openDumps(t)
+	for name, export := range map[string]func(context.Context, io.Writer, string, ...any) error{"CSV": m.ExportCSV, "JSON": m.ExportJSON} {
+		ctx, cancel := context.WithCancel(context.Background())
+		if err := export(ctx, cancelingWriter{cancel}, selectDumps); !errors.Is(err, context.Canceled) {
+			t.Errorf("Export%s canceled while streaming = %v, want context.Canceled", name, err)
+		}
+	}
+}
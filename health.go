@@ -0,0 +1,35 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// WithCloseDB makes Close close the manager's *sql.DB. Leave it unset when
+// the DB is shared with other code
+func WithCloseDB() Option {
+	return func(o *options) error {
+		o.closeDB = true
+		return nil
+	}
+}
+
+// Ping verifies the database is reachable, suiting readiness probes
+func (m *SQLTableManager[_, _]) Ping(ctx context.Context) (err error) {
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	return m.db.PingContext(ctx)
+}
+
+// Stats returns the connection pool statistics of the database
+func (m *SQLTableManager[_, _]) Stats() sql.DBStats {
+	return m.pool.Stats()
+}
+
+// Close releases the manager, closing its *sql.DB under WithCloseDB
+func (m *SQLTableManager[_, _]) Close() error {
+	if !m.opts.closeDB {
+		return nil
+	}
+	return m.pool.Close()
+}
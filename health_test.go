@@ -0,0 +1,40 @@
+package csql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestHealth(t *testing.T) {
+	db := openDB(t)
+	m := csql.NewSQLTableManager[Item](db)
+	ctx := context.Background()
+	if err := m.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Query(selectItems); err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Stats(); s.OpenConnections != 1 || s.MaxOpenConnections != 1 {
+		t.Fatalf("Stats = %+v, want the one open connection", s)
+	}
+	// the DB is shared, so Close leaves it open
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatalf("Close without WithCloseDB closed the DB: %v", err)
+	}
+	owner := csql.NewSQLTableManager[Item](db, csql.WithCloseDB())
+	if err := owner.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Ping(ctx); err == nil {
+		t.Fatal("Ping of a closed DB succeeded")
+	}
+	if s := m.Stats(); s.OpenConnections != 0 {
+		t.Fatalf("Stats = %+v, want no connections once closed", s)
+	}
+}
@@ -0,0 +1,30 @@
+package csql
+
+import (
+	"context"
+	"fmt"
+)
+
+// Hook wraps each operation with caller-defined behavior. method is the
+// operation name, e.g. OpQuery, and args are the bound arguments as
+// WithRedactor shows them, nil for a Transaction. The context Before returns is passed to the driver and to After.
+// Hooks can share values for the operation through WithMeta and Meta
+type Hook interface {
+	Before(ctx context.Context, method, query string, args []any) context.Context
+	After(ctx context.Context, method, query string, args []any, err error)
+}
+
+// WithHooks adds hooks around every Query, QueryRow, Exec, and Transaction.
+// Before runs in the order hooks were added, and After in reverse order,
+// so the first hook wraps all the others
+func WithHooks(hooks ...Hook) Option {
+	return func(o *options) error {
+		for _, h := range hooks {
+			if h == nil {
+				return fmt.Errorf("csql: hook must not be nil")
+			}
+		}
+		o.hooks = append(o.hooks, hooks...)
+		return nil
+	}
+}
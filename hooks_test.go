@@ -0,0 +1,67 @@
+package csql_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// traceKey is the context key hookLog stores its name under
+type traceKey struct{}
+
+// hookLog is a Hook appending what it sees to lines, as "name before
+// method" and "name after method: err", tagging the context with its name
+type hookLog struct {
+	name  string
+	lines *[]string
+}
+
+func (h hookLog) Before(ctx context.Context, method, _ string, _ []any) context.Context {
+	*h.lines = append(*h.lines, fmt.Sprintf("%s before %s", h.name, method))
+	return context.WithValue(ctx, traceKey{}, h.name)
+}
+
+func (h hookLog) After(ctx context.Context, method, _ string, _ []any, err error) {
+	*h.lines = append(*h.lines, fmt.Sprintf("%s after %s: %v (trace %v)", h.name, method, err, ctx.Value(traceKey{})))
+}
+
+func TestHooks(t *testing.T) {
+	var seen []any
+	db, _ := openRecorded(t, func(ctx context.Context, query string) bool {
+		if strings.HasPrefix(query, "SELECT") {
+			seen = append(seen, ctx.Value(traceKey{}))
+		}
+		return false
+	})
+	var lines []string
+	m := csql.NewSQLTableManager[Item](db, csql.WithHooks(hookLog{"outer", &lines}, hookLog{"inner", &lines}))
+	if _, err := m.Query(selectItems); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"outer before " + csql.OpQuery,
+		"inner before " + csql.OpQuery,
+		"inner after " + csql.OpQuery + ": <nil> (trace inner)",
+		"outer after " + csql.OpQuery + ": <nil> (trace inner)",
+	}
+	if fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Fatalf("hooks ran\n%q\nwant\n%q", lines, want)
+	}
+	if len(seen) != 1 || seen[0] != "inner" {
+		t.Fatalf("driver saw trace %v, want the context the last Before returned", seen)
+	}
+
+	lines = nil
+	if err := m.Exec("INSERT INTO missing VALUES (1)"); err == nil {
+		t.Fatal("Exec into a missing table succeeded")
+	}
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], "inner after "+csql.OpExec+": ") || strings.Contains(lines[2], "<nil>") {
+		t.Fatalf("hooks ran %q, want After to see the error", lines)
+	}
+	if got := constructPanic(func() { csql.NewSQLTableManager[Item](db, csql.WithHooks(nil)) }); !strings.Contains(got, "must not be nil") {
+		t.Fatalf("NewSQLTableManager panicked with %q, want the nil hook rejected", got)
+	}
+}
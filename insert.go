@@ -0,0 +1,265 @@
+package csql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// InsertManyReturning inserts rows into table with one multi-row INSERT and
+// returns the generated values of idColumn, in row order. columns name the
+// Schema's Fields in order. table, columns, and idColumn are spliced into
+// the SQL and must not come from user input.
+//
+// Postgres, SQLite, and Generic use RETURNING. MySQL has no RETURNING, so
+// the ids are derived from LastInsertId, which is the id of the first row,
+// assuming consecutive ids: this holds for InnoDB with
+// auto_increment_increment = 1 and a lock mode that keeps a single
+// statement's ids contiguous. Under WithDryRun no ids are returned
+func (m *SQLTableManager[T, R]) InsertManyReturning(ctx context.Context, table string, columns []string, rows []T, idColumn string) ([]int64, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("csql: insert requires at least one column")
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	var b strings.Builder
+	b.WriteString("INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES ")
+	tuple := "(?" + strings.Repeat(", ?", len(columns)-1) + ")"
+	args := make([]any, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		fields := R(&row).Fields()
+		if len(fields) != len(columns) {
+			return nil, fmt.Errorf("csql: row %d has %d fields for %d columns", i, len(fields), len(columns))
+		}
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(tuple)
+		args = append(args, m.opts.sealFields(columns, fields)...)
+	}
+	argCols := make([]string, 0, len(args))
+	for range rows {
+		argCols = append(argCols, columns...)
+	}
+	ctx = withArgColumns(ctx, argCols)
+	if m.opts.dialect == MySQL {
+		res, err := m.execAudited(ctx, "Insert", b.String(), args)
+		if err != nil || res == nil {
+			return nil, err
+		}
+		first, err := res.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]int64, len(rows))
+		for i := range ids {
+			ids[i] = first + int64(i)
+		}
+		return ids, nil
+	}
+	b.WriteString(" RETURNING " + idColumn)
+	return m.insertReturning(ctx, b.String(), args, len(rows))
+}
+
+// insertReturning runs the INSERT ... RETURNING of InsertManyReturning,
+// which writes, so unlike a query reading rows it is retried only under
+// WithExecRetry and runs within a database transaction it commits
+func (m *SQLTableManager[T, R]) insertReturning(ctx context.Context, query string, args []any, n int) (ids []int64, err error) {
+	defer m.opts.annotate(&err, "InsertManyReturning", query)
+	defer func() { m.opts.audit(ctx, "Insert", int64(len(ids)), err) }()
+	if err := m.opts.writable(); err != nil {
+		return nil, err
+	}
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	query = m.opts.finalize(ctx, query, args)
+	args = m.opts.convertArgs(args)
+	if m.opts.dryRun != nil {
+		m.opts.dryRun(query, append([]any(nil), args...))
+		return nil, nil
+	}
+	ctx, after, err := m.opts.beforeExec(ctx, "Insert", query, args, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer after(&err)
+	defer m.opts.invalidate()
+	ctx, start := m.opts.begin(ctx, OpExec, query, args)
+	err = m.opts.retryBusy(ctx, func() error {
+		return m.opts.retry(ctx, true, func() (err error) {
+			ids, err = m.insertReturningOnce(ctx, query, args, n)
+			return err
+		})
+	})
+	m.opts.observe(ctx, OpExec, query, args, start, int64(len(ids)), err)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) != n {
+		return nil, fmt.Errorf("csql: insert returned %d ids for %d rows", len(ids), n)
+	}
+	return ids, nil
+}
+
+// insertReturningOnce makes a single attempt at the transaction of insertReturning
+func (m *SQLTableManager[T, R]) insertReturningOnce(ctx context.Context, query string, args []any, n int) ([]int64, error) {
+	tx, err := m.beginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	queryRows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	ids := make([]int64, 0, n)
+	for queryRows.Next() {
+		var id int64
+		if err := queryRows.Scan(&id); err != nil {
+			queryRows.Close()
+			return nil, rollback(tx, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := queryRows.Close(); err != nil {
+		return nil, rollback(tx, err)
+	}
+	if err := queryRows.Err(); err != nil {
+		return nil, rollback(tx, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// InsertSelect inserts the rows selectQuery yields into destTable, as
+// INSERT INTO destTable (columns) selectQuery, and returns the number of
+// rows inserted, for moving rows between tables such as when archiving.
+// The select list must match columns, or every column of destTable when
+// columns is empty. destTable and columns are spliced into the SQL and must
+// not come from user input; args bind the placeholders of selectQuery.
+// Under WithDryRun nothing is inserted and 0 is returned
+func (m *SQLTableManager[T, R]) InsertSelect(ctx context.Context, destTable string, columns []string, selectQuery string, args ...any) (int64, error) {
+	query := "INSERT INTO " + destTable
+	if len(columns) > 0 {
+		query += " (" + strings.Join(columns, ", ") + ")"
+	}
+	res, err := m.execAudited(ctx, "InsertSelect", query+" "+strings.TrimSpace(selectQuery), args)
+	if err != nil || res == nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// InsertIgnore inserts row into table unless it conflicts with an existing
+// row, reporting whether it was inserted. MySQL uses INSERT IGNORE, which
+// also skips other errors it downgrades to warnings; the other dialects use
+// ON CONFLICT DO NOTHING. table and columns are spliced into the SQL and
+// must not come from user input. Under WithDryRun nothing is inserted
+func (m *SQLTableManager[T, R]) InsertIgnore(ctx context.Context, table string, columns []string, row T) (inserted bool, err error) {
+	if len(columns) == 0 {
+		return false, errors.New("csql: insert requires at least one column")
+	}
+	fields := R(&row).Fields()
+	if len(fields) != len(columns) {
+		return false, fmt.Errorf("csql: row has %d fields for %d columns", len(fields), len(columns))
+	}
+	insert, conflict := "INSERT INTO ", " ON CONFLICT DO NOTHING"
+	if m.opts.dialect == MySQL {
+		insert, conflict = "INSERT IGNORE INTO ", ""
+	}
+	query := insert + table + " (" + strings.Join(columns, ", ") + ") VALUES (?" + strings.Repeat(", ?", len(columns)-1) + ")" + conflict
+	res, err := m.execAudited(withArgColumns(ctx, columns), "Insert", query, m.opts.sealFields(columns, fields))
+	if err != nil || res == nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// UpsertWhere inserts row into table, or when it conflicts on
+// conflictColumns updates the other columns of the existing row to the
+// row's values, but only where updateWhere holds. updateWhere may refer to
+// the existing row by table and to the incoming one by excluded, as in
+// excluded.updated_at > items.updated_at; an empty updateWhere always
+// updates. Only Postgres and SQLite support it. table, the columns, and
+// updateWhere are spliced into the SQL and must not come from user input
+func (m *SQLTableManager[T, R]) UpsertWhere(ctx context.Context, table string, columns, conflictColumns []string, row T, updateWhere string) error {
+	if m.opts.dialect != Postgres && m.opts.dialect != SQLite {
+		return fmt.Errorf("csql: conditional upsert is not supported by the %s dialect", m.opts.dialect)
+	}
+	var sets []string
+	for _, c := range columns {
+		if !slices.Contains(conflictColumns, c) {
+			sets = append(sets, c+" = excluded."+c)
+		}
+	}
+	return m.upsert(ctx, table, columns, conflictColumns, row, sets, updateWhere)
+}
+
+// UpsertSet inserts row into table, or when it conflicts on
+// conflictColumns updates the existing row, setting each column of set to
+// its expression. The expressions may refer to the existing row by table
+// and to the incoming one by excluded, as in
+// count = items.count + excluded.count. MySQL, which updates on any
+// duplicate key and ignores conflictColumns, gets excluded.col rewritten
+// to VALUES(col). Generic is not supported. table, the columns, and the
+// expressions are spliced into the SQL and must not come from user input
+func (m *SQLTableManager[T, R]) UpsertSet(ctx context.Context, table string, columns, conflictColumns []string, row T, set map[string]string) error {
+	if m.opts.dialect == Generic {
+		return fmt.Errorf("csql: upsert is not supported by the %s dialect", m.opts.dialect)
+	}
+	cols := make([]string, 0, len(set))
+	for c := range set {
+		cols = append(cols, c)
+	}
+	slices.Sort(cols)
+	sets := make([]string, 0, len(set))
+	for _, c := range cols {
+		expr := set[c]
+		if m.opts.dialect == MySQL {
+			expr = excludedRef.ReplaceAllString(expr, "VALUES($1)")
+		}
+		sets = append(sets, c+" = "+expr)
+	}
+	return m.upsert(ctx, table, columns, conflictColumns, row, sets, "")
+}
+
+// excludedRef matches a reference to a column of the incoming row of an
+// upsert, capturing the column
+var excludedRef = regexp.MustCompile(`(?i)\bexcluded\.(\w+)`)
+
+// upsert runs an INSERT of row updating sets where updateWhere holds, if
+// not empty, on a conflict over conflictColumns, or doing nothing when
+// there is nothing to set
+func (m *SQLTableManager[T, R]) upsert(ctx context.Context, table string, columns, conflictColumns []string, row T, sets []string, updateWhere string) error {
+	if len(columns) == 0 || (len(conflictColumns) == 0 && m.opts.dialect != MySQL) {
+		return errors.New("csql: upsert requires at least one column and one conflict column")
+	}
+	fields := R(&row).Fields()
+	if len(fields) != len(columns) {
+		return fmt.Errorf("csql: row has %d fields for %d columns", len(fields), len(columns))
+	}
+	insert := "INSERT INTO "
+	var action string
+	switch {
+	case m.opts.dialect == MySQL && len(sets) == 0:
+		insert = "INSERT IGNORE INTO "
+	case m.opts.dialect == MySQL:
+		action = " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	case len(sets) == 0:
+		action = " ON CONFLICT (" + strings.Join(conflictColumns, ", ") + ") DO NOTHING"
+	default:
+		action = " ON CONFLICT (" + strings.Join(conflictColumns, ", ") + ") DO UPDATE SET " + strings.Join(sets, ", ")
+		if updateWhere != "" {
+			action += " WHERE " + updateWhere
+		}
+	}
+	query := insert + table + " (" + strings.Join(columns, ", ") + ") VALUES (?" + strings.Repeat(", ?", len(columns)-1) + ")" + action
+	_, err := m.execAudited(withArgColumns(ctx, columns), "Upsert", query, m.opts.sealFields(columns, fields))
+	return err
+}
@@ -0,0 +1,238 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+// opRecorder is a Metrics keeping the operation of each observation
+type opRecorder struct {
+	ops []string
+}
+
+func (r *opRecorder) ObserveQuery(op, _ string, _ time.Duration, _ int, _ error) {
+	r.ops = append(r.ops, op)
+}
+
+func (r *opRecorder) AddInFlight(string, int) {}
+
+func TestInsertManyReturning(t *testing.T) {
+	db := openDB(t)
+	var mx opRecorder
+	m := csql.NewSQLTableManager[Item](db, csql.WithMetrics(&mx))
+	ids, err := m.InsertManyReturning(context.Background(), "items", []string{"id", "name"}, items(3), "id")
+	if err != nil || !slices.Equal(ids, []int64{1, 2, 3}) {
+		t.Fatalf("InsertManyReturning = %v, %v", ids, err)
+	}
+	if !slices.Equal(mx.ops, []string{csql.OpExec}) {
+		t.Fatalf("observed %q, want one %s", mx.ops, csql.OpExec)
+	}
+	if got, err := m.Query(selectItems); err != nil || !slices.Equal(got, items(3)) {
+		t.Fatalf("Query = %v, %v", got, err)
+	}
+}
+
+func TestInsertManyReturningRetry(t *testing.T) {
+	retry := csql.WithRetry(csql.Backoff{Attempts: 3})
+	tests := []struct {
+		name     string
+		opts     []csql.Option
+		attempts int
+		wantErr  bool
+	}{
+		{"read retry only", []csql.Option{retry}, 1, true},
+		{"exec retry", []csql.Option{retry, csql.WithExecRetry()}, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := openRecorded(t, nil)
+			rec.fail = failFirst("INSERT", 1, io.ErrUnexpectedEOF)
+			m := csql.NewSQLTableManager[Item](db, tt.opts...)
+			_, err := m.InsertManyReturning(context.Background(), "items", []string{"id", "name"}, items(2), "id")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InsertManyReturning = %v, want error %t", err, tt.wantErr)
+			}
+			if tt.wantErr && (!errors.Is(err, io.ErrUnexpectedEOF) || !strings.Contains(err.Error(), "InsertManyReturning failed")) {
+				t.Fatalf("InsertManyReturning = %v, want it annotated", err)
+			}
+			if n := rec.Count("INSERT"); n != tt.attempts {
+				t.Fatalf("%d INSERTs, want %d", n, tt.attempts)
+			}
+			if n, want := countItems(t, m), 2*tt.attempts-2; n != want {
+				t.Fatalf("%d rows, want %d", n, want)
+			}
+		})
+	}
+}
+
+func TestInsertManyReturningIds(t *testing.T) {
+	ctx := context.Background()
+	m := csql.NewSQLTableManager[Item](openDB(t))
+	rows := []Item{{10, "a"}, {20, "b"}, {15, "c"}, {11, "d"}, {30, "e"}}
+	ids, err := m.InsertManyReturning(ctx, "items", []string{"id", "name"}, rows, "id")
+	if err != nil || !slices.Equal(ids, []int64{10, 20, 15, 11, 30}) {
+		t.Fatalf("InsertManyReturning = %v, %v, want one id per row in order", ids, err)
+	}
+	if ids, err := m.InsertManyReturning(ctx, "items", []string{"id", "name"}, nil, "id"); ids != nil || err != nil {
+		t.Fatalf("InsertManyReturning of no rows = %v, %v", ids, err)
+	}
+	if _, err := m.InsertManyReturning(ctx, "items", []string{"name"}, items(1), "id"); err == nil || !strings.Contains(err.Error(), "row 0 has 2 fields for 1 columns") {
+		t.Fatalf("InsertManyReturning = %v, want the column mismatch", err)
+	}
+	// MySQL derives the ids from LastInsertId, which sqlite also reports for a single row
+	mysql := csql.NewSQLTableManager[Item](openDB(t), csql.WithDialect(csql.MySQL))
+	if ids, err := mysql.InsertManyReturning(ctx, "items", []string{"id", "name"}, []Item{{7, "a"}}, "id"); err != nil || !slices.Equal(ids, []int64{7}) {
+		t.Fatalf("MySQL InsertManyReturning = %v, %v", ids, err)
+	}
+}
+
+func TestInsertIgnore(t *testing.T) {
+	ctx := context.Background()
+	m := csql.NewSQLTableManager[Item](openDB(t))
+	cols := []string{"id", "name"}
+	if inserted, err := m.InsertIgnore(ctx, "items", cols, Item{1, "first"}); err != nil || !inserted {
+		t.Fatalf("first InsertIgnore = %t, %v, want inserted", inserted, err)
+	}
+	if inserted, err := m.InsertIgnore(ctx, "items", cols, Item{1, "duplicate"}); err != nil || inserted {
+		t.Fatalf("duplicate InsertIgnore = %t, %v, want skipped", inserted, err)
+	}
+	if got, err := m.Query(selectItems); err != nil || !slices.Equal(got, []Item{{1, "first"}}) {
+		t.Fatalf("Query = %v, %v, want the first row kept", got, err)
+	}
+	if _, err := m.InsertIgnore(ctx, "items", []string{"id"}, Item{2, "b"}); err == nil || !strings.Contains(err.Error(), "2 fields for 1 columns") {
+		t.Fatalf("InsertIgnore = %v, want the column mismatch", err)
+	}
+
+	db, rec := openRecorded(t, func(_ context.Context, query string) bool { return strings.HasPrefix(query, "INSERT") })
+	mysql := csql.NewSQLTableManager[Item](db, csql.WithDialect(csql.MySQL))
+	mysql.InsertIgnore(ctx, "items", cols, Item{1, "a"})
+	if stmts := statementsAfterSetup(rec); len(stmts) != 1 || stmts[0].SQL != "INSERT IGNORE INTO items (id, name) VALUES (?, ?)" {
+		t.Fatalf("MySQL InsertIgnore sent %+v, want INSERT IGNORE", stmts)
+	}
+}
+
+// Reading is the Schema of the readings (id, at, value) table
+type Reading struct {
+	ID    int64
+	At    int64
+	Value string
+}
+
+func (r *Reading) ScanRow(s csql.RowScanner) error { return s.Scan(&r.ID, &r.At, &r.Value) }
+
+func (r *Reading) Fields() []any { return []any{r.ID, r.At, r.Value} }
+
+func TestUpsertWhere(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE readings (id INTEGER PRIMARY KEY, at INTEGER, value TEXT)")
+	m := csql.NewSQLTableManager[Reading](db, csql.WithDialect(csql.SQLite))
+	cols := []string{"id", "at", "value"}
+	newer := "excluded.at > readings.at"
+	for _, r := range []Reading{{1, 10, "first"}, {1, 20, "newer"}, {1, 15, "stale"}} {
+		if err := m.UpsertWhere(ctx, "readings", cols, []string{"id"}, r, newer); err != nil {
+			t.Fatalf("UpsertWhere(%v) = %v", r, err)
+		}
+	}
+	if got, err := m.Query("SELECT id, at, value FROM readings"); err != nil || !slices.Equal(got, []Reading{{1, 20, "newer"}}) {
+		t.Fatalf("Query = %v, %v, want the stale reading not to overwrite the newer one", got, err)
+	}
+	if err := m.UpsertWhere(ctx, "readings", cols, []string{"id"}, Reading{1, 5, "forced"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := m.Query("SELECT id, at, value FROM readings"); err != nil || !slices.Equal(got, []Reading{{1, 5, "forced"}}) {
+		t.Fatalf("Query = %v, %v, want an empty condition to always update", got, err)
+	}
+	mysql := csql.NewSQLTableManager[Reading](db, csql.WithDialect(csql.MySQL))
+	if err := mysql.UpsertWhere(ctx, "readings", cols, []string{"id"}, Reading{2, 1, "a"}, newer); err == nil || !strings.Contains(err.Error(), "not supported by the mysql dialect") {
+		t.Fatalf("MySQL UpsertWhere = %v, want the dialect rejected", err)
+	}
+	if err := m.UpsertWhere(ctx, "readings", cols, nil, Reading{2, 1, "a"}, newer); err == nil || !strings.Contains(err.Error(), "one conflict column") {
+		t.Fatalf("UpsertWhere without conflict columns = %v, want it rejected", err)
+	}
+}
+
+// Counter is the Schema of the counters (name, count) table
+type Counter struct {
+	Name  string
+	Count int64
+}
+
+func (c *Counter) ScanRow(s csql.RowScanner) error { return s.Scan(&c.Name, &c.Count) }
+
+func (c *Counter) Fields() []any { return []any{c.Name, c.Count} }
+
+func TestUpsertSet(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE counters (name TEXT PRIMARY KEY, count INTEGER)")
+	m := csql.NewSQLTableManager[Counter](db, csql.WithDialect(csql.SQLite))
+	cols := []string{"name", "count"}
+	accumulate := map[string]string{"count": "counters.count + EXCLUDED.count"}
+	for _, c := range []Counter{{"a", 2}, {"a", 3}, {"b", 1}} {
+		if err := m.UpsertSet(ctx, "counters", cols, []string{"name"}, c, accumulate); err != nil {
+			t.Fatalf("UpsertSet(%v) = %v", c, err)
+		}
+	}
+	if got, err := m.Query("SELECT name, count FROM counters ORDER BY name"); err != nil || !slices.Equal(got, []Counter{{"a", 5}, {"b", 1}}) {
+		t.Fatalf("Query = %v, %v, want the counts accumulated", got, err)
+	}
+
+	tests := []struct {
+		dialect csql.Dialect
+		want    string
+	}{
+		{csql.Postgres, "INSERT INTO counters (name, count) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET count = counters.count + EXCLUDED.count, seen = now()"},
+		{csql.MySQL, "INSERT INTO counters (name, count) VALUES (?, ?) ON DUPLICATE KEY UPDATE count = counters.count + VALUES(count), seen = now()"},
+	}
+	for _, tt := range tests {
+		db, rec := openRecorded(t, func(_ context.Context, query string) bool { return strings.HasPrefix(query, "INSERT") })
+		m := csql.NewSQLTableManager[Counter](db, csql.WithDialect(tt.dialect))
+		set := map[string]string{"seen": "now()", "count": "counters.count + EXCLUDED.count"}
+		if err := m.UpsertSet(ctx, "counters", cols, []string{"name"}, Counter{"a", 1}, set); err != nil {
+			t.Fatalf("%s UpsertSet = %v", tt.dialect, err)
+		}
+		if stmts := statementsAfterSetup(rec); len(stmts) != 1 || stmts[0].SQL != tt.want {
+			t.Errorf("%s UpsertSet sent %+v, want %q", tt.dialect, stmts, tt.want)
+		}
+	}
+	generic := csql.NewSQLTableManager[Counter](db, csql.WithDialect(csql.Generic))
+	if err := generic.UpsertSet(ctx, "counters", cols, []string{"name"}, Counter{"a", 1}, accumulate); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Fatalf("Generic UpsertSet = %v, want the dialect rejected", err)
+	}
+}
+
+func TestInsertSelect(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	seedItems(t, db, 5)
+	mustExec(t, db, "CREATE TABLE archive (id INTEGER PRIMARY KEY, name TEXT)")
+	m := csql.NewSQLTableManager[Item](db)
+	n, err := m.InsertSelect(ctx, "archive", []string{"id", "name"}, "SELECT id, name FROM items WHERE id > ?", 3)
+	if err != nil || n != 2 {
+		t.Fatalf("InsertSelect = %d, %v, want 2 rows", n, err)
+	}
+	if got, err := m.Query("SELECT id, name FROM archive ORDER BY id"); err != nil || !slices.Equal(got, []Item{{4, "item4"}, {5, "item5"}}) {
+		t.Fatalf("archive = %v, %v", got, err)
+	}
+	if n, err := m.InsertSelect(ctx, "archive", nil, "SELECT * FROM items WHERE id = ?", 1); err != nil || n != 1 {
+		t.Fatalf("InsertSelect of every column = %d, %v", n, err)
+	}
+
+	db, rec := openRecorded(t, nil)
+	m = csql.NewSQLTableManager[Item](db)
+	if _, err := m.InsertSelect(ctx, "archive", []string{"id", "name"}, "  SELECT id, name FROM items WHERE id < ?", 2); err == nil {
+		t.Fatal("InsertSelect into a missing table succeeded")
+	}
+	const want = "INSERT INTO archive (id, name) SELECT id, name FROM items WHERE id < ?"
+	if stmts := statementsAfterSetup(rec); len(stmts) != 1 || stmts[0].SQL != want {
+		t.Fatalf("InsertSelect sent %+v, want %q", stmts, want)
+	}
+}
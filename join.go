@@ -0,0 +1,250 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Pair is a row of a JOIN across two Schemas. Matched is false when every
+// column of the right side was NULL, as for an unmatched LEFT JOIN row,
+// in which case Right is the zero B
+type Pair[A, B any] struct {
+	Left    A
+	Right   B
+	Matched bool
+}
+
+// Joined adapts two Schemas into one for JOIN queries. The first
+// len(RA.Fields()) columns scan into Left and the rest into Right. Rows a
+// manager scans, as for QueryJoined and QueryRow2, pass each side through
+// the manager's scanning options, such as WithTypeCodec, WithNullAsZero,
+// WithColumnCipher, and WithTimeLocation, as its own manager would
+type Joined[A, B any, RA Schema[A], RB Schema[B]] struct {
+	Pair[A, B]
+}
+
+// joinedRow is implemented by the Joined rows scanRow scans side by side
+type joinedRow interface {
+	scanJoined(r RowScanner, o *options) error
+}
+
+// ScanRow implements Schema
+func (j *Joined[A, B, RA, RB]) ScanRow(r RowScanner) error {
+	return j.scanSides(r, func(r RowScanner, s interface{ ScanRow(RowScanner) error }, _ func() []string) error {
+		return s.ScanRow(r)
+	})
+}
+
+func (j *Joined[A, B, RA, RB]) scanJoined(r RowScanner, o *options) error {
+	return j.scanSides(r, o.scanSchema)
+}
+
+// scanSides reads the values of a row and has scan scan each side from its own
+func (j *Joined[A, B, RA, RB]) scanSides(r RowScanner, scan func(RowScanner, interface{ ScanRow(RowScanner) error }, func() []string) error) error {
+	n := len(RA(&j.Left).Fields())
+	raw := make([]any, n+len(RB(&j.Right).Fields()))
+	dest := make([]any, len(raw))
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+	if err := r.Scan(dest...); err != nil {
+		return err
+	}
+	*j = Joined[A, B, RA, RB]{}
+	if err := scan(valueScanner(raw[:n]), RA(&j.Left), columnsOf[A, RA]); err != nil {
+		return err
+	}
+	for _, v := range raw[n:] {
+		if v != nil {
+			j.Matched = true
+			break
+		}
+	}
+	if !j.Matched {
+		return nil
+	}
+	return scan(valueScanner(raw[n:]), RB(&j.Right), columnsOf[B, RB])
+}
+
+// Fields implements Schema
+func (j *Joined[A, B, RA, RB]) Fields() []any {
+	return append(RA(&j.Left).Fields(), RB(&j.Right).Fields()...)
+}
+
+// QueryJoined runs a JOIN query on m's database and options, scanning each
+// row into a Pair. A and B must be given; their Schemas are inferred
+func QueryJoined[A, B any, RA Schema[A], RB Schema[B], T any, R Schema[T]](ctx context.Context, m *SQLTableManager[T, R], query string, args ...any) ([]Pair[A, B], error) {
+	rows, err := joinedManager[A, B, RA, RB](m).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	pairs := make([]Pair[A, B], len(rows))
+	for i, row := range rows {
+		pairs[i] = row.Pair
+	}
+	return pairs, nil
+}
+
+// QueryRow2 runs a JOIN query such as SELECT a.*, b.* FROM a JOIN b on m's
+// database and options, scanning the first row into an A and a B. The
+// first len(RA.Fields()) columns scan into the A and the rest into the B,
+// which is the zero B when all of its columns are NULL. A and B must be
+// given; their Schemas are inferred
+func QueryRow2[A, B any, RA Schema[A], RB Schema[B], T any, R Schema[T]](ctx context.Context, m *SQLTableManager[T, R], query string, args ...any) (A, B, error) {
+	row, err := joinedManager[A, B, RA, RB](m).QueryRowContext(ctx, query, args...)
+	return row.Left, row.Right, err
+}
+
+// joinedManager returns a manager of Joined rows sharing m's database and options
+func joinedManager[A, B any, RA Schema[A], RB Schema[B], T any, R Schema[T]](m *SQLTableManager[T, R]) *SQLTableManager[Joined[A, B, RA, RB], *Joined[A, B, RA, RB]] {
+	return managerFor[Joined[A, B, RA, RB], *Joined[A, B, RA, RB]](m)
+}
+
+// managerFor returns a manager of U rows sharing m's database and options
+func managerFor[U any, RU Schema[U], T any, R Schema[T]](m *SQLTableManager[T, R]) *SQLTableManager[U, RU] {
+	return &SQLTableManager[U, RU]{db: m.db, pool: m.pool, opts: m.opts, pinned: m.pinned, group: m.group}
+}
+
+// QueryMapped runs query on m's database and options, scanning each row
+// into a D through its Schema and collecting mapper's result for it, so a
+// DTO matching the columns can feed a domain type in one pass. The types
+// are inferred from mapper
+func QueryMapped[D, V any, RD Schema[D], T any, R Schema[T]](ctx context.Context, m *SQLTableManager[T, R], query string, mapper func(D) V, args ...any) ([]V, error) {
+	dm := managerFor[D, RD](m)
+	names := dm.schemaColumns()
+	var scanner RowScanner
+	var values []V
+	err := dm.queryEach(ctx, query, args, func(queryRows *sql.Rows) (err error) {
+		if m.opts.maxRows > 0 && len(values) == m.opts.maxRows {
+			return ErrTooManyRows
+		}
+		if scanner == nil {
+			if scanner, err = dm.opts.columnScanner(queryRows, names); err != nil {
+				return err
+			}
+		}
+		var row D
+		if err := dm.scanRow(scanner, &row); err != nil {
+			return err
+		}
+		values = append(values, mapper(row))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// valueScanner is a RowScanner over driver values already read from a row
+type valueScanner []any
+
+func (s valueScanner) Scan(dest ...any) error {
+	if len(dest) != len(s) {
+		return fmt.Errorf("csql: expected %d destination arguments in Scan, not %d", len(s), len(dest))
+	}
+	for i, src := range s {
+		if err := convertAssign(dest[i], src); err != nil {
+			return fmt.Errorf("csql: converting column %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// convertAssign stores the driver value src in the pointer dest, following
+// the common conversions of database/sql
+func convertAssign(dest, src any) error {
+	if s, ok := dest.(sql.Scanner); ok {
+		return s.Scan(src)
+	}
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Pointer || dv.IsNil() {
+		return fmt.Errorf("destination %T is not a non-nil pointer", dest)
+	}
+	dv = dv.Elem()
+	if src == nil {
+		switch dv.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice:
+			dv.SetZero()
+			return nil
+		}
+		return fmt.Errorf("cannot scan NULL into %s", dv.Type())
+	}
+	if dv.Kind() == reflect.Pointer {
+		p := reflect.New(dv.Type().Elem())
+		if err := convertAssign(p.Interface(), src); err != nil {
+			return err
+		}
+		dv.Set(p)
+		return nil
+	}
+	sv := reflect.ValueOf(src)
+	if dv.Kind() == reflect.Interface || sv.Type().AssignableTo(dv.Type()) {
+		if b, ok := src.([]byte); ok {
+			sv = reflect.ValueOf(append([]byte(nil), b...))
+		}
+		dv.Set(sv)
+		return nil
+	}
+	var text string
+	switch s := src.(type) {
+	case string:
+		text = s
+	case []byte:
+		text = string(s)
+	}
+	switch k := dv.Kind(); {
+	case k == reflect.String:
+		switch src.(type) {
+		case string, []byte:
+			dv.SetString(text)
+		default:
+			dv.SetString(fmt.Sprint(src))
+		}
+		return nil
+	case k == reflect.Slice && dv.Type().Elem().Kind() == reflect.Uint8:
+		if _, ok := src.(string); ok {
+			dv.SetBytes([]byte(text))
+			return nil
+		}
+	case sv.Kind() >= reflect.Int && sv.Kind() <= reflect.Float64 && k >= reflect.Int && k <= reflect.Float64:
+		dv.Set(sv.Convert(dv.Type()))
+		return nil
+	case sv.Kind() == reflect.String || sv.Kind() == reflect.Slice:
+		return parseInto(dv, text)
+	}
+	return fmt.Errorf("cannot assign %T to %s", src, dv.Type())
+}
+
+// parseInto parses text into the numeric or boolean dv
+func parseInto(dv reflect.Value, text string) error {
+	var err error
+	switch k := dv.Kind(); {
+	case k >= reflect.Int && k <= reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(text, 10, dv.Type().Bits()); err == nil {
+			dv.SetInt(n)
+		}
+	case k >= reflect.Uint && k <= reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(text, 10, dv.Type().Bits()); err == nil {
+			dv.SetUint(n)
+		}
+	case k == reflect.Float32 || k == reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(text, dv.Type().Bits()); err == nil {
+			dv.SetFloat(f)
+		}
+	case k == reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(text); err == nil {
+			dv.SetBool(b)
+		}
+	default:
+		return fmt.Errorf("cannot assign %q to %s", text, dv.Type())
+	}
+	return err
+}
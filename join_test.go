@@ -0,0 +1,192 @@
+package csql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// Note is the Schema of the notes table openNotes creates
+type Note struct {
+	ItemID int64
+	Body   string
+}
+
+func (n *Note) ScanRow(s csql.RowScanner) error { return s.Scan(&n.ItemID, &n.Body) }
+
+func (n *Note) Fields() []any { return []any{n.ItemID, n.Body} }
+
+// openNotes returns openDB seeded with 3 items and a note on item 1
+func openNotes(t testing.TB) *sql.DB {
+	t.Helper()
+	db := openDB(t)
+	seedItems(t, db, 3)
+	mustExec(t, db, "CREATE TABLE notes (item_id INTEGER, body TEXT)")
+	mustExec(t, db, "INSERT INTO notes (item_id, body) VALUES (1, 'first')")
+	return db
+}
+
+// selectItemNotes joins each item with its notes
+const selectItemNotes = "SELECT items.id, items.name, notes.item_id, notes.body FROM items LEFT JOIN notes ON notes.item_id = items.id ORDER BY items.id"
+
+func TestQueryJoinedOptionalColumns(t *testing.T) {
+	db := openNotes(t)
+	m := csql.NewSQLTableManager[Item](db, csql.WithOptionalColumns("name"))
+	pairs, err := csql.QueryJoined[Item, Note](context.Background(), m, selectItemNotes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []csql.Pair[Item, Note]{
+		{Left: Item{ID: 1, Name: "item1"}, Right: Note{ItemID: 1, Body: "first"}, Matched: true},
+		{Left: Item{ID: 2, Name: "item2"}},
+		{Left: Item{ID: 3, Name: "item3"}},
+	}
+	if !slices.Equal(pairs, want) {
+		t.Fatalf("QueryJoined = %v, want %v", pairs, want)
+	}
+	left, right, err := csql.QueryRow2[Item, Note](context.Background(), m, selectItemNotes)
+	if err != nil || left != want[0].Left || right != want[0].Right {
+		t.Fatalf("QueryRow2 = %v, %v, %v", left, right, err)
+	}
+}
+
+// CodedNote is Note scanned through ScanInto, its body through a codec
+type CodedNote struct {
+	ItemID int64
+	Body   Code
+}
+
+func (n *CodedNote) ScanRow(r csql.RowScanner) error { return csql.ScanInto(r, n) }
+
+func (n *CodedNote) Fields() []any { return csql.ReflectFields(n) }
+
+func TestQueryJoinedScanOptions(t *testing.T) {
+	db := openNotes(t)
+	mustExec(t, db, "INSERT INTO notes (item_id, body) VALUES (2, NULL)")
+	m := csql.NewSQLTableManager[Item](db, csql.WithNullAsZero(),
+		csql.WithTypeCodec(
+			func(b []byte) (Code, error) { return Code(strings.ToUpper(string(b))), nil },
+			func(c Code) (driver.Value, error) { return string(c), nil },
+		))
+	pairs, err := csql.QueryJoined[Item, CodedNote](context.Background(), m, selectItemNotes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []csql.Pair[Item, CodedNote]{
+		{Left: Item{ID: 1, Name: "item1"}, Right: CodedNote{ItemID: 1, Body: "FIRST"}, Matched: true},
+		{Left: Item{ID: 2, Name: "item2"}, Right: CodedNote{ItemID: 2}, Matched: true},
+		{Left: Item{ID: 3, Name: "item3"}},
+	}
+	if !slices.Equal(pairs, want) {
+		t.Fatalf("QueryJoined = %v, want %v", pairs, want)
+	}
+}
+
+func TestQueryJoined(t *testing.T) {
+	db := openNotes(t)
+	mustExec(t, db, "INSERT INTO notes (item_id, body) VALUES (1, 'second')")
+	m := csql.NewSQLTableManager[Item](db)
+	pairs, err := csql.QueryJoined[Item, Note](context.Background(), m, selectItemNotes+", notes.body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []csql.Pair[Item, Note]{
+		{Left: Item{ID: 1, Name: "item1"}, Right: Note{ItemID: 1, Body: "first"}, Matched: true},
+		{Left: Item{ID: 1, Name: "item1"}, Right: Note{ItemID: 1, Body: "second"}, Matched: true},
+		{Left: Item{ID: 2, Name: "item2"}},
+		{Left: Item{ID: 3, Name: "item3"}},
+	}
+	if !slices.Equal(pairs, want) {
+		t.Fatalf("QueryJoined = %v, want unmatched LEFT JOIN rows with a zero Right", pairs)
+	}
+
+	// Joined also scans as a plain Schema
+	rows, err := db.Query(selectItemNotes + ", notes.body LIMIT 2 OFFSET 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []csql.Pair[Item, Note]
+	for rows.Next() {
+		var j csql.Joined[Item, Note, *Item, *Note]
+		if err := j.ScanRow(rows); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, j.Pair)
+	}
+	if err := rows.Err(); err != nil || !slices.Equal(got, want[1:3]) {
+		t.Fatalf("ScanRow = %v, %v", got, err)
+	}
+
+	if _, _, err := csql.QueryRow2[Item, Note](context.Background(), m, selectItemNotes+" LIMIT 0"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("QueryRow2 of no rows = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := csql.QueryJoined[Item, Note](context.Background(), m, "SELECT id, name, id FROM items"); err == nil {
+		t.Fatal("QueryJoined with too few columns succeeded")
+	}
+}
+
+func TestQueryRow2(t *testing.T) {
+	db := openNotes(t)
+	m := csql.NewSQLTableManager[Item](db)
+	ctx := context.Background()
+	const byItem = "SELECT items.*, notes.* FROM items LEFT JOIN notes ON notes.item_id = items.id WHERE items.id = ?"
+	item, note, err := csql.QueryRow2[Item, Note](ctx, m, byItem, 1)
+	if err != nil || item != (Item{1, "item1"}) || note != (Note{1, "first"}) {
+		t.Fatalf("QueryRow2 = %v, %v, %v, want the item and its note", item, note, err)
+	}
+	// an unmatched Note is all NULL, and zero
+	item, note, err = csql.QueryRow2[Item, Note](ctx, m, byItem, 2)
+	if err != nil || item != (Item{2, "item2"}) || note != (Note{}) {
+		t.Fatalf("QueryRow2 = %v, %v, %v, want the item and a zero note", item, note, err)
+	}
+	// the split follows the Fields of the first Schema
+	note, item, err = csql.QueryRow2[Note, Item](ctx, m, "SELECT notes.*, items.* FROM notes JOIN items ON items.id = notes.item_id")
+	if err != nil || note != (Note{1, "first"}) || item != (Item{1, "item1"}) {
+		t.Fatalf("QueryRow2 = %v, %v, %v", note, item, err)
+	}
+}
+
+// NameRow is a DTO Schema of the names (first_name, last_name) table
+type NameRow struct {
+	FirstName string
+	LastName  string
+}
+
+func (n *NameRow) ScanRow(s csql.RowScanner) error { return s.Scan(&n.FirstName, &n.LastName) }
+
+func (n *NameRow) Fields() []any { return []any{n.FirstName, n.LastName} }
+
+func (n *NameRow) Columns() []string { return []string{"first_name", "last_name"} }
+
+// Contact is the domain type NameRow maps to
+type Contact struct {
+	FullName string
+}
+
+func TestQueryMapped(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE names (first_name TEXT, last_name TEXT)")
+	mustExec(t, db, "INSERT INTO names VALUES ('Ada', 'Lovelace'), ('Alan', 'Turing')")
+	m := csql.NewSQLTableManager[Item](db)
+	ctx := context.Background()
+	full := func(n NameRow) Contact { return Contact{n.FirstName + " " + n.LastName} }
+	// the columns are matched by name to the DTO, whatever their order
+	got, err := csql.QueryMapped(ctx, m, "SELECT last_name, first_name FROM names ORDER BY first_name", full)
+	if err != nil || !slices.Equal(got, []Contact{{"Ada Lovelace"}, {"Alan Turing"}}) {
+		t.Fatalf("QueryMapped = %v, %v, want the names joined", got, err)
+	}
+	if got, err := csql.QueryMapped(ctx, m, "SELECT first_name, last_name FROM names WHERE first_name = ?", full, "Alan"); err != nil || !slices.Equal(got, []Contact{{"Alan Turing"}}) {
+		t.Fatalf("QueryMapped with args = %v, %v", got, err)
+	}
+	capped := csql.NewSQLTableManager[Item](db, csql.WithMaxRows(1))
+	if _, err := csql.QueryMapped(ctx, capped, "SELECT first_name, last_name FROM names", full); !errors.Is(err, csql.ErrTooManyRows) {
+		t.Fatalf("QueryMapped past WithMaxRows = %v, want ErrTooManyRows", err)
+	}
+}
@@ -0,0 +1,97 @@
+package csql
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JSON stores V as a JSON encoded column. SQL NULL scans as the zero V
+type JSON[T any] struct {
+	V T
+}
+
+// Scan implements sql.Scanner
+func (j *JSON[T]) Scan(src any) error {
+	var zero T
+	j.V = zero
+	_, err := scanJSON(src, &j.V)
+	return err
+}
+
+// Value implements driver.Valuer
+func (j JSON[T]) Value() (driver.Value, error) {
+	return json.Marshal(j.V)
+}
+
+// MarshalJSON encodes the wrapped value
+func (j JSON[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.V)
+}
+
+// UnmarshalJSON decodes into the wrapped value
+func (j *JSON[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &j.V)
+}
+
+// NullJSON stores V as a nullable JSON encoded column. Valid is false for
+// SQL NULL, which is also written when Valid is false
+type NullJSON[T any] struct {
+	V     T
+	Valid bool
+}
+
+// Scan implements sql.Scanner
+func (j *NullJSON[T]) Scan(src any) (err error) {
+	var zero T
+	j.V = zero
+	j.Valid, err = scanJSON(src, &j.V)
+	return err
+}
+
+// Value implements driver.Valuer
+func (j NullJSON[T]) Value() (driver.Value, error) {
+	if !j.Valid {
+		return nil, nil
+	}
+	return json.Marshal(j.V)
+}
+
+// MarshalJSON encodes the wrapped value, or null when not Valid
+func (j NullJSON[T]) MarshalJSON() ([]byte, error) {
+	if !j.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(j.V)
+}
+
+// UnmarshalJSON decodes into the wrapped value, treating null as not Valid
+func (j *NullJSON[T]) UnmarshalJSON(data []byte) error {
+	var zero T
+	j.V = zero
+	if string(data) == "null" {
+		j.Valid = false
+		return nil
+	}
+	j.Valid = true
+	return json.Unmarshal(data, &j.V)
+}
+
+// scanJSON decodes a JSON column into v, reporting whether it was not NULL
+func scanJSON(src, v any) (bool, error) {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		return false, nil
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return false, fmt.Errorf("csql: cannot scan %T into JSON", src)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return true, fmt.Errorf("csql: invalid JSON for %T: %w", v, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,97 @@
+package csql_test
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// Tally stores its counts as a JSON column
+type Tally struct {
+	ID     int64
+	Counts csql.JSON[map[string]int]
+	Extra  csql.NullJSON[[]string]
+}
+
+func (t *Tally) ScanRow(r csql.RowScanner) error { return csql.ScanInto(r, t) }
+
+func (t *Tally) Fields() []any { return csql.ReflectFields(t) }
+
+func TestJSONRoundTrip(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE tallies (ID INTEGER PRIMARY KEY, Counts TEXT, Extra TEXT)")
+	m := csql.NewSQLTableManager[Tally](db)
+	rows := []Tally{
+		{ID: 1, Counts: csql.JSON[map[string]int]{V: map[string]int{"a": 1, "b": 2}}, Extra: csql.NullJSON[[]string]{V: []string{"x"}, Valid: true}},
+		{ID: 2},
+	}
+	if _, err := m.Transaction("INSERT INTO tallies VALUES (?, ?, ?)", rows); err != nil {
+		t.Fatal(err)
+	}
+	mustExec(t, db, "INSERT INTO tallies VALUES (3, NULL, NULL)")
+	got, err := m.Query("SELECT ID, Counts, Extra FROM tallies ORDER BY ID")
+	if err != nil || len(got) != 3 {
+		t.Fatalf("Query = %+v, %v", got, err)
+	}
+	if !maps.Equal(got[0].Counts.V, rows[0].Counts.V) || !got[0].Extra.Valid || len(got[0].Extra.V) != 1 || got[0].Extra.V[0] != "x" {
+		t.Errorf("row 1 = %+v, want %+v", got[0], rows[0])
+	}
+	// a nil map is stored as JSON null, which decodes back to nil
+	if got[1].Counts.V != nil || got[1].Extra.Valid {
+		t.Errorf("row 2 = %+v, want zero values", got[1])
+	}
+	if got[2].Counts.V != nil || got[2].Extra.Valid {
+		t.Errorf("SQL NULL row = %+v, want zero values", got[2])
+	}
+	var raw string
+	if err := db.QueryRow("SELECT Counts FROM tallies WHERE ID = 1").Scan(&raw); err != nil || raw != `{"a":1,"b":2}` {
+		t.Errorf("stored %q, %v", raw, err)
+	}
+	var extra any
+	if err := db.QueryRow("SELECT Extra FROM tallies WHERE ID = 2").Scan(&extra); err != nil || extra != nil {
+		t.Errorf("invalid NullJSON stored %v, %v, want NULL", extra, err)
+	}
+}
+
+func TestJSONInvalid(t *testing.T) {
+	var j csql.JSON[map[string]int]
+	if err := j.Scan("{"); err == nil {
+		t.Fatal("Scan of invalid JSON succeeded")
+	}
+	if err := j.Scan(42); err == nil {
+		t.Fatal("Scan of an integer succeeded")
+	}
+}
+
+func TestNullJSON(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE tallies (ID INTEGER PRIMARY KEY, Counts TEXT, Extra TEXT)")
+	mustExec(t, db, `INSERT INTO tallies VALUES (1, '{}', '["a","b"]'), (2, '{}', NULL), (3, '{}', 'null'), (4, '{}', '[oops')`)
+	m := csql.NewSQLTableManager[Tally](db)
+	got, err := m.Query("SELECT ID, Counts, Extra FROM tallies WHERE ID < 4 ORDER BY ID")
+	if err != nil || len(got) != 3 {
+		t.Fatalf("Query = %+v, %v", got, err)
+	}
+	if !got[0].Extra.Valid || !slices.Equal(got[0].Extra.V, []string{"a", "b"}) {
+		t.Errorf("Extra = %+v, want [a b]", got[0].Extra)
+	}
+	if got[1].Extra.Valid || got[1].Extra.V != nil {
+		t.Errorf("SQL NULL Extra = %+v, want not Valid", got[1].Extra)
+	}
+	// a JSON null is present, unlike SQL NULL, though it decodes to nil
+	if !got[2].Extra.Valid || got[2].Extra.V != nil {
+		t.Errorf("JSON null Extra = %+v, want Valid and nil", got[2].Extra)
+	}
+	_, err = m.Query("SELECT ID, Counts, Extra FROM tallies WHERE ID = 4")
+	if err == nil || !strings.Contains(err.Error(), `"Extra"`) || !strings.Contains(err.Error(), "invalid JSON") {
+		t.Fatalf("Query of invalid JSON = %v, want the column named", err)
+	}
+	b, err := json.Marshal(got[1].Extra)
+	if err != nil || string(b) != "null" {
+		t.Fatalf("MarshalJSON = %s, %v, want null", b, err)
+	}
+}
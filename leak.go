@@ -0,0 +1,92 @@
+package csql
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithLeakDetection calls onLeak with the creation stack of every
+// PreparedTransaction garbage collected without being closed, and of every
+// iterator from TransactionIter and QueryAllPaged garbage collected without
+// having run to its end or been stopped. It only reports: nothing leaked is
+// closed. Reports depend on the garbage collector running, and an iterator
+// abandoned while suspended, as by an unstopped iter.Pull, is never
+// collected; WithLeakAge reports those. Nothing is recorded when disabled
+func WithLeakDetection(onLeak func(stack []byte)) Option {
+	return func(o *options) error {
+		if onLeak == nil {
+			return fmt.Errorf("csql: leak callback must not be nil")
+		}
+		o.onLeak = onLeak
+		return nil
+	}
+}
+
+// WithLeakAge also reports the objects WithLeakDetection tracks once they
+// are still open d after their creation, whether or not they are
+// collected. Objects are reported at most once, so d should exceed their
+// longest legitimate use. It requires WithLeakDetection
+func WithLeakAge(d time.Duration) Option {
+	return func(o *options) error {
+		if d <= 0 {
+			return fmt.Errorf("csql: leak age must be positive, got %v", d)
+		}
+		o.leakAge = d
+		return nil
+	}
+}
+
+// trackLeak arms leak detection for obj, returning the func releasing it
+// once closed. The release func must not hold obj, which obj may hold: a
+// finalizer never runs on a cycle
+func trackLeak[P any](o *options, obj *P) (release func()) {
+	if o.onLeak == nil {
+		return func() {}
+	}
+	onLeak, stack := o.onLeak, debug.Stack()
+	var released atomic.Bool
+	var once sync.Once
+	report := func() {
+		if !released.Load() {
+			once.Do(func() { onLeak(stack) })
+		}
+	}
+	runtime.SetFinalizer(obj, func(*P) { report() })
+	var timer *time.Timer
+	if o.leakAge > 0 {
+		timer = time.AfterFunc(o.leakAge, report)
+	}
+	return func() {
+		released.Store(true)
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// leakToken stands for an iterator under leak detection. It holds a
+// pointer so it is not batched with other tiny allocations, whose
+// finalizers may never run
+type leakToken struct {
+	_ *byte
+}
+
+// trackedIter arms leak detection for the iterator seq, released once a
+// run of it returns
+func trackedIter[T any](o *options, seq func(yield func(T, error) bool)) func(yield func(T, error) bool) {
+	if o.onLeak == nil {
+		return seq
+	}
+	token := new(leakToken)
+	release := trackLeak(o, token)
+	return func(yield func(T, error) bool) {
+		defer release()
+		// token is collected with the iterator, not before
+		defer runtime.KeepAlive(token)
+		seq(yield)
+	}
+}
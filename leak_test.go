@@ -0,0 +1,109 @@
+package csql_test
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+// leaks collects the stacks WithLeakDetection reports
+type leaks struct {
+	mu     sync.Mutex
+	stacks [][]byte
+}
+
+func (l *leaks) report(stack []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stacks = append(l.stacks, stack)
+}
+
+func (l *leaks) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.stacks)
+}
+
+// collect runs the garbage collector until n leaks are reported, or a
+// second passes
+func (l *leaks) collect(n int) int {
+	for deadline := time.Now().Add(time.Second); l.count() < n && time.Now().Before(deadline); {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	return l.count()
+}
+
+func TestLeakDetectionPrepared(t *testing.T) {
+	ctx := context.Background()
+	var l leaks
+	m := csql.NewSQLTableManager[Item](openDB(t), csql.WithLeakDetection(l.report))
+	func() {
+		if _, err := m.Prepare(ctx, insertItem); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	closed, err := m.Prepare(ctx, insertItem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+	if n := l.collect(1); n != 1 {
+		t.Fatalf("reported %d leaks, want the unclosed statement alone", n)
+	}
+	if n := l.collect(2); n != 1 {
+		t.Fatalf("reported %d leaks, want the closed statement left out", n)
+	}
+}
+
+func TestLeakDetectionIterators(t *testing.T) {
+	var l leaks
+	db := openDB(t)
+	seedItems(t, db, 3)
+	m := csql.NewSQLTableManager[Item](db, csql.WithTable("items"), csql.WithLeakDetection(l.report))
+	func() {
+		m.TransactionIter(context.Background(), insertItem+" RETURNING id, name", items(1))
+		m.QueryAllPaged(context.Background(), "ID", 2)
+	}()
+	m.QueryAllPaged(context.Background(), "ID", 2)(func(Item, error) bool { return false })
+	if n := l.collect(2); n != 2 {
+		t.Fatalf("reported %d leaks, want the two iterators never run", n)
+	}
+	if n := l.collect(3); n != 2 {
+		t.Fatalf("reported %d leaks, want the stopped iterator left out", n)
+	}
+}
+
+func TestLeakAge(t *testing.T) {
+	var l leaks
+	db := openDB(t)
+	m := csql.NewSQLTableManager[Item](db, csql.WithLeakDetection(l.report), csql.WithLeakAge(20*time.Millisecond))
+	seq := m.TransactionIter(context.Background(), insertItem+" RETURNING id, name", items(2))
+	// the consumer suspends forever after the first row, as an unstopped
+	// iter.Pull does, holding the transaction open
+	resume := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		seq(func(Item, error) bool { return <-resume })
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if n := l.count(); n != 1 {
+		t.Fatalf("reported %d leaks, want the suspended iterator", n)
+	}
+	resume <- false
+	<-done
+	if n := countItems(t, m); n != 0 {
+		t.Fatalf("stored %d rows, want the stopped iterator rolled back", n)
+	}
+}
+
+func TestLeakAgeRequiresDetection(t *testing.T) {
+	if msg := constructPanic(func() { csql.NewSQLTableManager[Item](openDB(t), csql.WithLeakAge(time.Second)) }); msg == "" {
+		t.Fatal("WithLeakAge without WithLeakDetection was accepted")
+	}
+}
@@ -0,0 +1,51 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// ScanError reports a result row that ScanRow failed on
+type ScanError struct {
+	// Row is the index of the row among the query's results
+	Row int
+	Err error
+}
+
+func (e *ScanError) Error() string {
+	return fmt.Sprintf("csql: row %d: %v", e.Row, e.Err)
+}
+
+func (e *ScanError) Unwrap() error { return e.Err }
+
+// QueryLenient is QueryContext, skipping rows that fail to scan instead of
+// failing the query. Each skipped row is reported in rowErrors as a
+// *ScanError; err is left for failures of the query itself
+func (m *SQLTableManager[T, R]) QueryLenient(ctx context.Context, query string, args ...any) (rows []T, rowErrors []error, err error) {
+	names := m.schemaColumns()
+	var scanner RowScanner
+	n := 0
+	err = m.queryEach(ctx, query, args, func(queryRows *sql.Rows) (err error) {
+		if m.opts.maxRows > 0 && n == m.opts.maxRows {
+			return ErrTooManyRows
+		}
+		if scanner == nil {
+			if scanner, err = m.opts.columnScanner(queryRows, names); err != nil {
+				return err
+			}
+		}
+		var row T
+		if err := m.scanRow(scanner, &row); err != nil {
+			rowErrors = append(rowErrors, &ScanError{Row: n, Err: err})
+		} else {
+			rows = append(rows, row)
+		}
+		n++
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return rows, rowErrors, nil
+}
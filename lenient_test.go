@@ -0,0 +1,60 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// errBadRow is the error Picky fails to scan a row named bad with
+var errBadRow = errors.New("bad row")
+
+// Picky is an Item failing to scan rows named bad
+type Picky struct{ Item }
+
+func (p *Picky) ScanRow(s csql.RowScanner) error {
+	if err := p.Item.ScanRow(s); err != nil {
+		return err
+	}
+	if p.Name == "bad" {
+		return errBadRow
+	}
+	return nil
+}
+
+func TestQueryLenient(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	mustExec(t, db, "INSERT INTO items (id, name) VALUES (1, 'a'), (2, 'bad'), (3, 'c'), (4, 'bad')")
+	m := csql.NewSQLTableManager[Picky](db)
+	rows, rowErrs, err := m.QueryLenient(ctx, selectItems)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, r := range rows {
+		ids = append(ids, r.ID)
+	}
+	if !slices.Equal(ids, []int64{1, 3}) {
+		t.Fatalf("QueryLenient returned %v, want the rows that scanned", ids)
+	}
+	if len(rowErrs) != 2 {
+		t.Fatalf("QueryLenient reported %v, want two row errors", rowErrs)
+	}
+	for i, want := range []int{1, 3} {
+		var se *csql.ScanError
+		if !errors.As(rowErrs[i], &se) || se.Row != want || !errors.Is(se, errBadRow) {
+			t.Errorf("row error %d = %v, want a *ScanError for row %d", i, rowErrs[i], want)
+		}
+	}
+	if rows, rowErrs, err := m.QueryLenient(ctx, "SELECT id, name FROM missing"); err == nil || rows != nil || rowErrs != nil {
+		t.Fatalf("QueryLenient = %v, %v, %v, want only the query error", rows, rowErrs, err)
+	}
+	capped := csql.NewSQLTableManager[Picky](db, csql.WithMaxRows(2))
+	if _, _, err := capped.QueryLenient(ctx, selectItems); !errors.Is(err, csql.ErrTooManyRows) {
+		t.Fatalf("QueryLenient past WithMaxRows = %v, want ErrTooManyRows", err)
+	}
+}
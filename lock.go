@@ -0,0 +1,31 @@
+package csql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// AdvisoryLock holds the Postgres session advisory lock key while fn runs,
+// blocking until it is acquired. The lock is taken with pg_advisory_lock on
+// a connection of its own, see WithConn, and released with
+// pg_advisory_unlock on the same connection once fn returns or panics,
+// even when ctx is canceled by then. Other dialects get an error
+func (m *SQLTableManager[T, R]) AdvisoryLock(ctx context.Context, key int64, fn func() error) error {
+	if m.opts.dialect != Postgres {
+		return fmt.Errorf("csql: advisory locks are not supported by the %s dialect", m.opts.dialect)
+	}
+	return m.WithConn(ctx, func(c *SQLTableManager[T, R]) (err error) {
+		if _, err := c.db.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+			m.opts.annotate(&err, "AdvisoryLock", "")
+			return err
+		}
+		defer func() {
+			if _, unlockErr := c.db.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", key); unlockErr != nil {
+				m.opts.annotate(&unlockErr, "AdvisoryLock", "")
+				err = errors.Join(err, unlockErr)
+			}
+		}()
+		return fn()
+	})
+}
@@ -0,0 +1,71 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// advisoryLocks stubs the Postgres advisory lock functions sqlite lacks
+func advisoryLocks(_ context.Context, query string) bool {
+	return strings.HasPrefix(query, "SELECT pg_advisory")
+}
+
+// lockStatements returns the advisory lock statements rec saw
+func lockStatements(rec *recorder) []Statement {
+	var stmts []Statement
+	for _, s := range rec.Stmts() {
+		if advisoryLocks(context.Background(), s.SQL) {
+			stmts = append(stmts, s)
+		}
+	}
+	return stmts
+}
+
+func TestAdvisoryLock(t *testing.T) {
+	ctx := context.Background()
+	db, rec := openRecordedFile(t, 2)
+	rec.stub = advisoryLocks
+	m := csql.NewSQLTableManager[Item](db, csql.WithDialect(csql.Postgres))
+	fnErr := errors.New("job failed")
+	err := m.AdvisoryLock(ctx, 42, func() error {
+		// the pool stays usable while the lock's connection is held
+		if _, err := m.Query(selectItems); err != nil {
+			t.Error(err)
+		}
+		return fnErr
+	})
+	if !errors.Is(err, fnErr) {
+		t.Fatalf("AdvisoryLock = %v, want fn's error", err)
+	}
+	stmts := lockStatements(rec)
+	if len(stmts) != 2 || stmts[0].SQL != "SELECT pg_advisory_lock($1)" || stmts[1].SQL != "SELECT pg_advisory_unlock($1)" {
+		t.Fatalf("sent %+v, want a lock then an unlock", stmts)
+	}
+	if stmts[0].Conn != stmts[1].Conn || stmts[0].Named[0].Value != int64(42) || stmts[1].Named[0].Value != int64(42) {
+		t.Fatalf("sent %+v, want key 42 locked and unlocked on one connection", stmts)
+	}
+}
+
+func TestAdvisoryLockPanic(t *testing.T) {
+	db, rec := openRecorded(t, advisoryLocks)
+	m := csql.NewSQLTableManager[Item](db, csql.WithDialect(csql.Postgres))
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Fatalf("recovered %v, want fn's panic", r)
+			}
+		}()
+		m.AdvisoryLock(context.Background(), 7, func() error { panic("boom") })
+	}()
+	if stmts := lockStatements(rec); len(stmts) != 2 || stmts[1].SQL != "SELECT pg_advisory_unlock($1)" {
+		t.Fatalf("sent %+v, want the lock released after the panic", stmts)
+	}
+	sqlite := csql.NewSQLTableManager[Item](db)
+	if err := sqlite.AdvisoryLock(context.Background(), 7, func() error { return nil }); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Fatalf("AdvisoryLock on sqlite = %v, want it unsupported", err)
+	}
+}
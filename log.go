@@ -0,0 +1,178 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Operation names reported to hooks
+const (
+	OpQuery       = "Query"
+	OpQueryRow    = "QueryRow"
+	OpExec        = "Exec"
+	OpTransaction = "Transaction"
+)
+
+// QueryInfo describes a completed operation
+type QueryInfo struct {
+	// Op is the operation name, e.g. OpQuery
+	Op string
+	// SQL is the statement as sent to the driver
+	SQL string
+	// NumArgs is the number of bound arguments
+	NumArgs int
+	// Args holds a copy of the bound arguments when WithLogArgs is set,
+	// as WithRedactor shows them
+	Args []any
+	// Duration is the wall time of the operation, including row iteration
+	Duration time.Duration
+	// Rows is the number of rows returned by a query, affected by Exec,
+	// or executed by Transaction. It is -1 when the driver cannot tell
+	Rows int64
+	// Err is the error the operation failed with, which the caller
+	// receives annotated with the failing method
+	Err error
+}
+
+// Logger observes the operations run by a manager
+type Logger interface {
+	LogQuery(ctx context.Context, info QueryInfo)
+}
+
+// WithLogger reports every Query, QueryRow, Exec, and Transaction to l
+func WithLogger(l Logger) Option {
+	return func(o *options) error {
+		o.logger = l
+		return nil
+	}
+}
+
+// WithLogArgs includes the bound arguments in QueryInfo.
+// Arguments are excluded by default since they may hold sensitive data
+func WithLogArgs() Option {
+	return func(o *options) error {
+		o.logArgs = true
+		return nil
+	}
+}
+
+// Tracer wraps each operation, e.g. in a tracing span. TraceStart may
+// derive a context that is passed to the driver and then to TraceEnd
+type Tracer interface {
+	TraceStart(ctx context.Context, op, query string) context.Context
+	TraceEnd(ctx context.Context, info QueryInfo)
+}
+
+// Target describes the manager running an operation, for tracers and
+// hooks labelling it
+type Target struct {
+	// Dialect is the manager's dialect
+	Dialect Dialect
+	// Table is the WithTable table, or "" when unset
+	Table string
+}
+
+type targetKey struct{}
+
+// TargetOf returns the Target of the operation ctx belongs to, as passed
+// to TraceStart, hooks, and loggers, or false outside an operation
+func TargetOf(ctx context.Context) (Target, bool) {
+	t, ok := ctx.Value(targetKey{}).(Target)
+	return t, ok
+}
+
+// WithTracer reports the start and end of every operation to t
+func WithTracer(t Tracer) Option {
+	return func(o *options) error {
+		o.tracer = t
+		return nil
+	}
+}
+
+// observed reports whether any hook wants to hear about operations
+func (o *options) observed() bool {
+	return o.logger != nil || o.tracer != nil || o.metrics != nil || o.slowQuery != nil || len(o.hooks) > 0 || o.stats != nil
+}
+
+// begin starts observing an operation, returning the context to run it with
+func (o *options) begin(ctx context.Context, op, query string, args []any) (context.Context, time.Time) {
+	if !o.observed() {
+		return ctx, time.Time{}
+	}
+	ctx = callMeta(ctx)
+	ctx = context.WithValue(ctx, targetKey{}, Target{Dialect: o.dialect, Table: o.table})
+	if o.metrics != nil {
+		o.metrics.AddInFlight(op, 1)
+	}
+	if o.tracer != nil {
+		ctx = o.tracer.TraceStart(ctx, op, query)
+	}
+	if len(o.hooks) > 0 {
+		shown := o.redactArgs(ctx, args)
+		for _, h := range o.hooks {
+			ctx = h.Before(ctx, op, query, shown)
+		}
+	}
+	return ctx, time.Now()
+}
+
+// observe reports a finished operation to the configured hooks
+func (o *options) observe(ctx context.Context, op, query string, args []any, start time.Time, rows int64, err error) {
+	if !o.observed() {
+		return
+	}
+	info := QueryInfo{
+		Op:       op,
+		SQL:      query,
+		NumArgs:  len(args),
+		Duration: time.Since(start),
+		Rows:     rows,
+		Err:      err,
+	}
+	shown := o.redactArgs(ctx, args)
+	if o.logArgs && len(args) > 0 {
+		info.Args = append([]any(nil), shown...)
+	}
+	for i := len(o.hooks) - 1; i >= 0; i-- {
+		o.hooks[i].After(ctx, op, query, shown, err)
+	}
+	if o.slowQuery != nil && info.Duration > o.slowThreshold {
+		slow := SlowQuery{Op: op, SQL: query, Duration: info.Duration, Rows: rows}
+		if o.explainSlow {
+			slow.Plan = o.slowPlan(ctx, op, query, args)
+		}
+		o.slowQuery(slow)
+	}
+	if o.stats != nil {
+		o.stats.record(OpStats{Operation: op, Query: query, Rows: int(rows), Duration: info.Duration, Err: err})
+	}
+	if o.metrics != nil {
+		o.metrics.AddInFlight(op, -1)
+		o.metrics.ObserveQuery(op, query, info.Duration, int(rows), err)
+	}
+	if o.tracer != nil {
+		o.tracer.TraceEnd(ctx, info)
+	}
+	if o.logger != nil {
+		o.logger.LogQuery(ctx, info)
+	}
+}
+
+func rowsAffected(res sql.Result, err error) int64 {
+	if err != nil || res == nil {
+		return 0
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+func rowsFound(err error) int64 {
+	if err != nil {
+		return 0
+	}
+	return 1
+}
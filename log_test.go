@@ -0,0 +1,96 @@
+package csql_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+// infoLog is a Logger keeping every QueryInfo
+type infoLog struct {
+	infos []csql.QueryInfo
+}
+
+func (l *infoLog) LogQuery(_ context.Context, info csql.QueryInfo) {
+	l.infos = append(l.infos, info)
+}
+
+// slowSelects delays every SELECT by d before running it
+func slowSelects(d time.Duration) func(context.Context, string) bool {
+	return func(_ context.Context, query string) bool {
+		if strings.HasPrefix(query, "SELECT") {
+			time.Sleep(d)
+		}
+		return false
+	}
+}
+
+func TestLogger(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	db, _ := openRecorded(t, slowSelects(delay))
+	var l infoLog
+	m := csql.NewSQLTableManager[Item](db, csql.WithLogger(&l))
+	if err := m.Exec(insertItem, 1, "item1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Query(selectItems); err != nil {
+		t.Fatal(err)
+	}
+	_, rowErr := m.QueryRow("SELECT id, name FROM items WHERE id = ?", 9)
+	if !errors.Is(rowErr, sql.ErrNoRows) {
+		t.Fatalf("QueryRow = %v, want sql.ErrNoRows", rowErr)
+	}
+	execErr := m.Exec("INSERT INTO missing VALUES (?)", 1)
+	if execErr == nil {
+		t.Fatal("Exec into a missing table succeeded")
+	}
+	if _, err := m.Transaction(insertItem, []Item{{2, "item2"}, {3, "item3"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		op      string
+		numArgs int
+		rows    int64
+		err     error
+		slow    bool
+	}{
+		{csql.OpExec, 2, 1, nil, false},
+		{csql.OpQuery, 0, 1, nil, true},
+		{csql.OpQueryRow, 1, 0, rowErr, true},
+		{csql.OpExec, 1, 0, execErr, false},
+		{csql.OpTransaction, 0, 2, nil, false},
+	}
+	if len(l.infos) != len(want) {
+		t.Fatalf("logged %d operations, want %d: %+v", len(l.infos), len(want), l.infos)
+	}
+	for i, w := range want {
+		info := l.infos[i]
+		if info.Op != w.op || info.NumArgs != w.numArgs || info.Rows != w.rows || (info.Err == nil) != (w.err == nil) || !errors.Is(w.err, info.Err) {
+			t.Errorf("info %d = %+v, want %+v", i, info, w)
+		}
+		if info.Args != nil {
+			t.Errorf("info %d logged args %v without WithLogArgs", i, info.Args)
+		}
+		if info.Duration <= 0 || w.slow && info.Duration < delay {
+			t.Errorf("info %d took %v, want at least %v", i, info.Duration, delay)
+		}
+	}
+}
+
+func TestLoggerArgs(t *testing.T) {
+	db := openDB(t)
+	var l infoLog
+	m := csql.NewSQLTableManager[Item](db, csql.WithLogger(&l), csql.WithLogArgs())
+	if err := m.Exec(insertItem, 1, "item1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.infos) != 1 || len(l.infos[0].Args) != 2 || l.infos[0].Args[0] != 1 || l.infos[0].Args[1] != "item1" {
+		t.Fatalf("logged %+v, want the two args", l.infos)
+	}
+}
@@ -0,0 +1,49 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// QueryMaps runs query on db and returns each row as a map from column name
+// to value, for dynamic queries no Schema fits. []byte values are converted
+// to string when stringBytes is set. Later duplicate column names win
+func QueryMaps(ctx context.Context, db *sql.DB, query string, stringBytes bool, args ...any) ([]map[string]any, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	var maps []map[string]any
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			v := values[i]
+			if b, ok := v.([]byte); ok {
+				if stringBytes {
+					v = string(b)
+				} else {
+					v = append([]byte(nil), b...)
+				}
+			}
+			row[column] = v
+		}
+		maps = append(maps, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return maps, nil
+}
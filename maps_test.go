@@ -0,0 +1,29 @@
+package csql_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestQueryMaps(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 2)
+	ctx := context.Background()
+	got, err := csql.QueryMaps(ctx, db, "SELECT id AS key, CAST(name AS BLOB) AS label FROM items WHERE id >= ? ORDER BY id", false, 1)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("QueryMaps = %v, %v", got, err)
+	}
+	if len(got[0]) != 2 || got[0]["key"] != int64(1) || fmt.Sprintf("%T %s", got[1]["label"], got[1]["label"]) != "[]uint8 item2" {
+		t.Fatalf("QueryMaps rows = %v, want key and label columns", got)
+	}
+	got, err = csql.QueryMaps(ctx, db, "SELECT CAST(name AS BLOB) AS label FROM items ORDER BY id", true)
+	if err != nil || got[0]["label"] != "item1" || got[1]["label"] != "item2" {
+		t.Fatalf("QueryMaps with string bytes = %v, %v", got, err)
+	}
+	if got, err := csql.QueryMaps(ctx, db, "SELECT id FROM items WHERE id > 2", false); err != nil || got != nil {
+		t.Fatalf("QueryMaps of no rows = %v, %v", got, err)
+	}
+}
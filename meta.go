@@ -0,0 +1,67 @@
+package csql
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+type metaKey struct{}
+
+// metaStore holds the values of WithMeta. The store of an operation is
+// written in place, so values recorded by one hook reach the others
+type metaStore struct {
+	mu     sync.Mutex
+	values map[any]any
+	// call is set for the store of a single operation
+	call bool
+}
+
+// WithMeta returns ctx carrying val under key, for Meta to read back.
+// Within an operation, as in a Hook's Before, the value is recorded for
+// the rest of the operation, so later hooks, After, and loggers see it
+// even when the returned context is not passed on. Values set on the
+// context of a call are seen by its operations, but values recorded
+// during an operation do not leak out of it
+func WithMeta(ctx context.Context, key, val any) context.Context {
+	s, _ := ctx.Value(metaKey{}).(*metaStore)
+	if s != nil && s.call {
+		s.mu.Lock()
+		s.values[key] = val
+		s.mu.Unlock()
+		return ctx
+	}
+	values := map[any]any{key: val}
+	if s != nil {
+		values = s.copy()
+		values[key] = val
+	}
+	return context.WithValue(ctx, metaKey{}, &metaStore{values: values})
+}
+
+// Meta returns the value WithMeta stored under key in ctx, or nil
+func Meta(ctx context.Context, key any) any {
+	s, _ := ctx.Value(metaKey{}).(*metaStore)
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values[key]
+}
+
+// callMeta returns ctx with a store of its own for an operation, holding
+// the values already set on ctx
+func callMeta(ctx context.Context) context.Context {
+	values := map[any]any{}
+	if s, _ := ctx.Value(metaKey{}).(*metaStore); s != nil {
+		values = s.copy()
+	}
+	return context.WithValue(ctx, metaKey{}, &metaStore{values: values, call: true})
+}
+
+func (s *metaStore) copy() map[any]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.values)
+}
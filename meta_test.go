@@ -0,0 +1,69 @@
+package csql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// requestKey and startKey are the Meta keys of metaHook
+type (
+	requestKey struct{}
+	startKey   struct{}
+)
+
+// metaHook records an operation number in Before, dropping the returned
+// context, and keeps what After reads back for each key
+type metaHook struct {
+	calls int
+	seen  [][2]any
+}
+
+func (h *metaHook) Before(ctx context.Context, _, _ string, _ []any) context.Context {
+	h.calls++
+	csql.WithMeta(ctx, startKey{}, h.calls)
+	return ctx
+}
+
+func (h *metaHook) After(ctx context.Context, _, _ string, _ []any, _ error) {
+	h.seen = append(h.seen, [2]any{csql.Meta(ctx, requestKey{}), csql.Meta(ctx, startKey{})})
+}
+
+// metaLog is a Logger keeping the startKey Meta of each operation
+type metaLog struct {
+	starts []any
+}
+
+func (l *metaLog) LogQuery(ctx context.Context, _ csql.QueryInfo) {
+	l.starts = append(l.starts, csql.Meta(ctx, startKey{}))
+}
+
+func TestMeta(t *testing.T) {
+	var h metaHook
+	var l metaLog
+	m := csql.NewSQLTableManager[Item](openDB(t), csql.WithHooks(&h), csql.WithLogger(&l))
+	ctx := csql.WithMeta(context.Background(), requestKey{}, "req-1")
+	if _, err := m.QueryContext(ctx, selectItems); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ExecContext(ctx, insertItem, 1, "item1"); err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]any{{"req-1", 1}, {"req-1", 2}}
+	if len(h.seen) != len(want) || h.seen[0] != want[0] || h.seen[1] != want[1] {
+		t.Fatalf("After read %v, want %v", h.seen, want)
+	}
+	if len(l.starts) != 2 || l.starts[0] != 1 || l.starts[1] != 2 {
+		t.Fatalf("logger read %v, want the value Before recorded for each call", l.starts)
+	}
+	if got := csql.Meta(ctx, startKey{}); got != nil {
+		t.Fatalf("caller's context has %v, want values recorded during a call kept inside it", got)
+	}
+	if got := csql.Meta(context.Background(), requestKey{}); got != nil {
+		t.Fatalf("Meta without WithMeta = %v, want nil", got)
+	}
+	if got := csql.Meta(csql.WithMeta(ctx, requestKey{}, "req-2"), requestKey{}); got != "req-2" || csql.Meta(ctx, requestKey{}) != "req-1" {
+		t.Fatalf("overriding WithMeta = %v, want a new value without changing the parent", got)
+	}
+}
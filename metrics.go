@@ -0,0 +1,21 @@
+package csql
+
+import (
+	"time"
+)
+
+// Metrics receives measurements of the operations run by a manager
+type Metrics interface {
+	// ObserveQuery records a finished operation. rows follows QueryInfo.Rows
+	ObserveQuery(op, query string, duration time.Duration, rows int, err error)
+	// AddInFlight adjusts the number of running operations of kind op by delta
+	AddInFlight(op string, delta int)
+}
+
+// WithMetrics reports every operation to mx
+func WithMetrics(mx Metrics) Option {
+	return func(o *options) error {
+		o.metrics = mx
+		return nil
+	}
+}
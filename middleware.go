@@ -0,0 +1,130 @@
+package csql
+
+import (
+	"context"
+	"time"
+)
+
+// Middleware decorates a SQLTable, e.g. with logging, retries, or tracing
+type Middleware[T any, R Schema[T]] func(next SQLTable[T, R]) SQLTable[T, R]
+
+// Chain wraps table in mws, the first being the outermost
+func Chain[T any, R Schema[T]](table SQLTable[T, R], mws ...Middleware[T, R]) SQLTable[T, R] {
+	for i := len(mws) - 1; i >= 0; i-- {
+		table = mws[i](table)
+	}
+	return table
+}
+
+// Call is a call to a SQLTable passing through an Around middleware
+type Call struct {
+	// Op is the operation name, e.g. OpQuery
+	Op string
+	// SQL is the statement passed to the table
+	SQL string
+	// Args holds the arguments of Query, QueryRow, and Exec
+	Args []any
+	// Rows is the number of rows passed to Transaction
+	Rows int
+}
+
+// Around returns a Middleware passing every call through fn, which runs it
+// by calling next, possibly with a derived context, and returns its error.
+// next reports the rows as QueryInfo.Rows does, -1 for Exec since SQLTable
+// does not tell. Calls without a context get context.Background()
+func Around[T any, R Schema[T]](fn func(ctx context.Context, call Call, next func(context.Context) (int64, error)) error) Middleware[T, R] {
+	return func(next SQLTable[T, R]) SQLTable[T, R] {
+		return &around[T, R]{next: next, fn: fn}
+	}
+}
+
+// LogMiddleware returns a Middleware reporting every call to l, as
+// WithLogger does for a manager. Arguments are not logged
+func LogMiddleware[T any, R Schema[T]](l Logger) Middleware[T, R] {
+	return Around[T, R](func(ctx context.Context, call Call, next func(context.Context) (int64, error)) error {
+		start := time.Now()
+		rows, err := next(ctx)
+		l.LogQuery(ctx, QueryInfo{
+			Op:       call.Op,
+			SQL:      call.SQL,
+			NumArgs:  len(call.Args),
+			Duration: time.Since(start),
+			Rows:     rows,
+			Err:      err,
+		})
+		return err
+	})
+}
+
+// RetryMiddleware returns a Middleware retrying Query and QueryRow calls
+// failing per policy, as WithRetry does for a manager. Exec and
+// Transaction are never retried, as they may have taken effect
+func RetryMiddleware[T any, R Schema[T]](policy RetryPolicy) Middleware[T, R] {
+	return Around[T, R](func(ctx context.Context, call Call, next func(context.Context) (int64, error)) error {
+		if call.Op != OpQuery && call.Op != OpQueryRow {
+			_, err := next(ctx)
+			return err
+		}
+		return retryWith(ctx, policy, func() error {
+			_, err := next(ctx)
+			return err
+		})
+	})
+}
+
+// around is the SQLTable of Around
+type around[T any, R Schema[T]] struct {
+	next SQLTable[T, R]
+	fn   func(ctx context.Context, call Call, next func(context.Context) (int64, error)) error
+}
+
+var _ SQLTable[nopSchema, *nopSchema] = (*around[nopSchema, *nopSchema])(nil)
+
+func (a *around[T, R]) Query(query string, args ...any) ([]T, error) {
+	return a.QueryContext(context.Background(), query, args...)
+}
+
+func (a *around[T, R]) QueryContext(ctx context.Context, query string, args ...any) (rows []T, err error) {
+	err = a.fn(ctx, Call{Op: OpQuery, SQL: query, Args: args}, func(ctx context.Context) (int64, error) {
+		rows, err = a.next.QueryContext(ctx, query, args...)
+		return int64(len(rows)), err
+	})
+	return rows, err
+}
+
+func (a *around[T, R]) QueryRow(query string, args ...any) (T, error) {
+	return a.QueryRowContext(context.Background(), query, args...)
+}
+
+func (a *around[T, R]) QueryRowContext(ctx context.Context, query string, args ...any) (row T, err error) {
+	err = a.fn(ctx, Call{Op: OpQueryRow, SQL: query, Args: args}, func(ctx context.Context) (int64, error) {
+		row, err = a.next.QueryRowContext(ctx, query, args...)
+		return rowsFound(err), err
+	})
+	return row, err
+}
+
+func (a *around[T, R]) Exec(query string, args ...any) error {
+	return a.ExecContext(context.Background(), query, args...)
+}
+
+func (a *around[T, R]) ExecContext(ctx context.Context, query string, args ...any) error {
+	return a.fn(ctx, Call{Op: OpExec, SQL: query, Args: args}, func(ctx context.Context) (int64, error) {
+		return -1, a.next.ExecContext(ctx, query, args...)
+	})
+}
+
+func (a *around[T, R]) Transaction(transaction string, rows []T) (bool, error) {
+	return a.TransactionContext(context.Background(), transaction, rows)
+}
+
+func (a *around[T, R]) TransactionContext(ctx context.Context, transaction string, rows []T) (ok bool, err error) {
+	err = a.fn(ctx, Call{Op: OpTransaction, SQL: transaction, Rows: len(rows)}, func(ctx context.Context) (int64, error) {
+		ok, err = a.next.TransactionContext(ctx, transaction, rows)
+		if !ok {
+			return 0, err
+		}
+		return int64(len(rows)), err
+	})
+	return ok, err
+}
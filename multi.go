@@ -0,0 +1,140 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// QueryMulti returns the rows of every result set produced by query, as
+// returned by stored procedures and batched statements on drivers that
+// support them. Each set is scanned through the Schema and WithMaxRows
+// applies to each set separately
+func (m *SQLTableManager[T, R]) QueryMulti(ctx context.Context, query string, args ...any) ([][]T, error) {
+	var sets [][]T
+	names := m.schemaColumns()
+	var scanner RowScanner
+	startSet := func(int) {
+		sets = append(sets, nil)
+		scanner = nil
+	}
+	err := m.queryEachSet(ctx, query, args, nil, startSet, func(set int, queryRows *sql.Rows) (err error) {
+		if m.opts.maxRows > 0 && len(sets[set]) == m.opts.maxRows {
+			return ErrTooManyRows
+		}
+		if scanner == nil {
+			if scanner, err = m.opts.columnScanner(queryRows, names); err != nil {
+				return err
+			}
+		}
+		box := new(T)
+		if err := m.scanRow(scanner, box); err != nil {
+			return err
+		}
+		sets[set] = append(sets[set], *box)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sets, nil
+}
+
+// TransactionQuery runs query once within a database transaction,
+// collecting the rows of every result set it produces through the Schema,
+// and commits, for statements both writing and returning rows such as
+// CALL proc() or INSERT ... RETURNING. Result sets without rows, such as
+// the status MySQL appends to a CALL, are skipped. The rows are returned
+// with whether the transaction committed; on error it is rolled back and
+// no rows are returned
+func (m *SQLTableManager[T, R]) TransactionQuery(ctx context.Context, query string, args ...any) (rows []T, ok bool, err error) {
+	defer m.opts.annotate(&err, "TransactionQuery", query)
+	defer func() { m.opts.audit(ctx, "TransactionQuery", int64(len(rows)), err) }()
+	if err := m.opts.writable(); err != nil {
+		return nil, false, err
+	}
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	query = m.opts.finalize(ctx, query, args)
+	args = m.opts.convertArgs(args)
+	if m.opts.dryRun != nil {
+		m.opts.dryRun(query, args)
+		return nil, false, nil
+	}
+	var after func(*error)
+	if ctx, after, err = m.opts.beforeExec(ctx, "TransactionQuery", query, args, 0); err != nil {
+		return nil, false, err
+	}
+	defer after(&err)
+	defer m.opts.invalidate()
+	if m.opts.observed() {
+		var start time.Time
+		ctx, start = m.opts.begin(ctx, OpTransaction, query, args)
+		defer func() {
+			m.opts.observe(ctx, OpTransaction, query, args, start, int64(len(rows)), err)
+		}()
+	}
+	err = m.opts.retryBusy(ctx, func() (err error) {
+		rows, err = m.transactQueryOnce(ctx, query, args)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return rows, true, nil
+}
+
+// transactQueryOnce makes a single attempt at the database transaction of
+// TransactionQuery
+func (m *SQLTableManager[T, R]) transactQueryOnce(ctx context.Context, query string, args []any) ([]T, error) {
+	tx, err := m.beginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	queryRows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	var rows []T
+	names := m.schemaColumns()
+	for set := 0; ; set++ {
+		var scanner RowScanner
+		for queryRows.Next() {
+			if scanner == nil {
+				if scanner, err = m.opts.columnScanner(queryRows, names); err != nil {
+					queryRows.Close()
+					return nil, rollback(tx, resultSetError(true, set, err))
+				}
+			}
+			var zero T
+			rows = append(rows, zero)
+			if err := m.scanRow(scanner, &rows[len(rows)-1]); err != nil {
+				queryRows.Close()
+				return nil, rollback(tx, resultSetError(true, set, err))
+			}
+		}
+		if err := queryRows.Err(); err != nil {
+			queryRows.Close()
+			return nil, rollback(tx, resultSetError(true, set, err))
+		}
+		if !queryRows.NextResultSet() {
+			break
+		}
+	}
+	if err := queryRows.Close(); err != nil {
+		return nil, rollback(tx, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// resultSetError identifies the result set err came from when reading several
+func resultSetError(multi bool, set int, err error) error {
+	if !multi {
+		return err
+	}
+	return fmt.Errorf("csql: result set %d: %w", set, err)
+}
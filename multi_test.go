@@ -0,0 +1,85 @@
+package csql_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// callRefill stands for a procedure writing rows and returning them
+const callRefill = "CALL refill()"
+
+func TestTransactionQuery(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	rec.canned = map[string]*cannedRows{callRefill: {
+		cols: []string{"id", "name"},
+		rows: [][]driver.Value{{int64(1), "one"}, {int64(2), "two"}},
+	}}
+	m := csql.NewSQLTableManager[Item](db)
+	rows, ok, err := m.TransactionQuery(context.Background(), callRefill)
+	if err != nil || !ok || !slices.Equal(rows, []Item{{1, "one"}, {2, "two"}}) {
+		t.Fatalf("TransactionQuery = %v, %v, %v, want both rows committed", rows, ok, err)
+	}
+	var got []string
+	for _, s := range rec.Stmts()[1:] {
+		got = append(got, s.SQL)
+	}
+	if want := []string{"BEGIN", callRefill, "COMMIT"}; !slices.Equal(got, want) {
+		t.Fatalf("statements %q, want %q", got, want)
+	}
+}
+
+func TestTransactionQueryRollsBack(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	m := csql.NewSQLTableManager[Item](db)
+	if _, ok, err := m.TransactionQuery(context.Background(), "SELECT id, name FROM missing"); err == nil || ok {
+		t.Fatalf("TransactionQuery = %v, %v, want an error", ok, err)
+	}
+	if rec.Count("ROLLBACK") != 1 || rec.Count("COMMIT") != 0 {
+		t.Fatalf("statements %v, want a rollback", rec.Stmts())
+	}
+}
+
+// callReport stands for a procedure returning several result sets
+const callReport = "CALL report()"
+
+func TestQueryMulti(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	cols := []string{"id", "name"}
+	rec.canned = map[string]*cannedRows{callReport: {
+		cols: cols,
+		rows: [][]driver.Value{{int64(1), "one"}, {int64(2), "two"}},
+		next: &cannedRows{cols: cols, next: &cannedRows{
+			cols: cols,
+			rows: [][]driver.Value{{int64(3), "three"}},
+		}},
+	}}
+	m := csql.NewSQLTableManager[Item](db)
+	sets, err := m.QueryMulti(context.Background(), callReport)
+	if err != nil || fmt.Sprint(sets) != "[[{1 one} {2 two}] [] [{3 three}]]" {
+		t.Fatalf("QueryMulti = %v, %v, want three sets", sets, err)
+	}
+	// sqlite returns a single result set
+	seedItems(t, db, 2)
+	if sets, err := m.QueryMulti(context.Background(), selectItems); err != nil || len(sets) != 1 || len(sets[0]) != 2 {
+		t.Fatalf("QueryMulti on sqlite = %v, %v, want one set", sets, err)
+	}
+}
+
+func TestQueryMultiLaterSetFails(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	rec.canned = map[string]*cannedRows{callReport: {
+		cols: []string{"id", "name"},
+		rows: [][]driver.Value{{int64(1), "one"}},
+		next: &cannedRows{cols: []string{"total"}, rows: [][]driver.Value{{int64(1)}}},
+	}}
+	m := csql.NewSQLTableManager[Item](db)
+	if sets, err := m.QueryMulti(context.Background(), callReport); err == nil || !strings.Contains(err.Error(), "result set 1") {
+		t.Fatalf("QueryMulti = %v, %v, want the failing set named", sets, err)
+	}
+}
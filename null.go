@@ -0,0 +1,150 @@
+package csql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// Null is a nullable V, replacing sql.NullString and friends for any type
+// database/sql can scan into, including sql.Scanner implementations.
+// It marshals to JSON as V, or null when not Valid
+type Null[V any] struct {
+	V     V
+	Valid bool
+}
+
+// Scan implements sql.Scanner using database/sql's conversion rules
+func (n *Null[V]) Scan(src any) error {
+	var sn sql.Null[V]
+	err := sn.Scan(src)
+	n.V, n.Valid = sn.V, sn.Valid
+	return err
+}
+
+// Value implements driver.Valuer, converting V to a driver.Value as
+// database/sql converts arguments, so Null[int] yields an int64
+func (n Null[V]) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(n.V)
+}
+
+// MarshalJSON encodes V, or null when not Valid
+func (n Null[V]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.V)
+}
+
+// UnmarshalJSON decodes into V, treating null as not Valid
+func (n *Null[V]) UnmarshalJSON(data []byte) error {
+	var zero V
+	n.V = zero
+	if string(data) == "null" {
+		n.Valid = false
+		return nil
+	}
+	n.Valid = true
+	return json.Unmarshal(data, &n.V)
+}
+
+// WithNullAsZero scans NULL into the zero value for *string, *bool, pointers
+// to the integer and float types, *time.Time, and *[]byte destinations of
+// ScanRow, rather than failing the scan. Named types ScanInto scans as their
+// underlying type are covered too. Other destinations, including sql.Scanner
+// implementations, are scanned as they are. It hides missing values, so
+// prefer Null for columns that are meant to be nullable
+func WithNullAsZero() Option {
+	return func(o *options) error {
+		o.nullAsZero = true
+		return nil
+	}
+}
+
+// nullZeroScanner is a RowScanner scanning NULL as the zero value, see WithNullAsZero
+type nullZeroScanner struct {
+	RowScanner
+}
+
+func (s nullZeroScanner) Scan(dest ...any) error {
+	return s.RowScanner.Scan(nullZeroDest(dest)...)
+}
+
+// nullZeroColumnScanner is nullZeroScanner for a ColumnScanner
+type nullZeroColumnScanner struct {
+	ColumnScanner
+}
+
+func (s nullZeroColumnScanner) Scan(dest ...any) error {
+	return s.ColumnScanner.Scan(nullZeroDest(dest)...)
+}
+
+// nullZeroScanning wraps r to scan NULL as the zero value
+func nullZeroScanning(r RowScanner) RowScanner {
+	if c, ok := r.(ColumnScanner); ok {
+		return nullZeroColumnScanner{c}
+	}
+	return nullZeroScanner{r}
+}
+
+// nullZeroDest returns dest with the destinations WithNullAsZero covers wrapped
+func nullZeroDest(dest []any) []any {
+	wrapped := make([]any, len(dest))
+	for i, d := range dest {
+		switch d := d.(type) {
+		case *string:
+			wrapped[i] = nullZero[string]{d}
+		case *int:
+			wrapped[i] = nullZero[int]{d}
+		case *int8:
+			wrapped[i] = nullZero[int8]{d}
+		case *int16:
+			wrapped[i] = nullZero[int16]{d}
+		case *int32:
+			wrapped[i] = nullZero[int32]{d}
+		case *int64:
+			wrapped[i] = nullZero[int64]{d}
+		case *uint:
+			wrapped[i] = nullZero[uint]{d}
+		case *uint8:
+			wrapped[i] = nullZero[uint8]{d}
+		case *uint16:
+			wrapped[i] = nullZero[uint16]{d}
+		case *uint32:
+			wrapped[i] = nullZero[uint32]{d}
+		case *uint64:
+			wrapped[i] = nullZero[uint64]{d}
+		case *float32:
+			wrapped[i] = nullZero[float32]{d}
+		case *float64:
+			wrapped[i] = nullZero[float64]{d}
+		case *bool:
+			wrapped[i] = nullZero[bool]{d}
+		case *time.Time:
+			wrapped[i] = nullZero[time.Time]{d}
+		case *[]byte:
+			wrapped[i] = nullZero[[]byte]{d}
+		default:
+			wrapped[i] = d
+		}
+	}
+	return wrapped
+}
+
+// nullZero scans into dest, storing the zero V for NULL
+type nullZero[V any] struct {
+	dest *V
+}
+
+func (z nullZero[V]) Scan(src any) error {
+	var n sql.Null[V]
+	if err := n.Scan(src); err != nil {
+		return err
+	}
+	*z.dest = n.V
+	return nil
+}
@@ -0,0 +1,89 @@
+package csql_test
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+type status int32
+
+func TestNullValue(t *testing.T) {
+	tests := []struct {
+		name string
+		v    driver.Valuer
+		want driver.Value
+	}{
+		{"int", csql.Null[int]{V: 7, Valid: true}, int64(7)},
+		{"int32", csql.Null[int32]{V: 7, Valid: true}, int64(7)},
+		{"named", csql.Null[status]{V: 3, Valid: true}, int64(3)},
+		{"string", csql.Null[string]{V: "a", Valid: true}, "a"},
+		{"valuer", csql.Null[csql.Null[int]]{V: csql.Null[int]{V: 2, Valid: true}, Valid: true}, int64(2)},
+		{"invalid", csql.Null[int]{V: 7}, nil},
+	}
+	for _, tt := range tests {
+		got, err := tt.v.Value()
+		if err != nil || got != tt.want {
+			t.Errorf("%s: Value() = %#v, %v, want %#v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+// nullProbe is a sql.Scanner recording whether it was handed NULL
+type nullProbe struct {
+	null bool
+}
+
+func (p *nullProbe) Scan(src any) error {
+	p.null = src == nil
+	return nil
+}
+
+// Legacy is a Schema of plain fields over columns that may hold NULL
+type Legacy struct {
+	S     string
+	I     int64
+	F     float64
+	B     bool
+	T     time.Time
+	Blob  []byte
+	Probe nullProbe
+}
+
+func (l *Legacy) ScanRow(s csql.RowScanner) error {
+	return s.Scan(&l.S, &l.I, &l.F, &l.B, &l.T, &l.Blob, &l.Probe)
+}
+
+func (l *Legacy) Fields() []any { return []any{l.S, l.I, l.F, l.B, l.T, l.Blob, nil} }
+
+func TestNullAsZero(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE legacy (s TEXT, i INTEGER, f REAL, b BOOLEAN, t DATETIME, blob BLOB, probe TEXT)")
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	mustExec(t, db, "INSERT INTO legacy VALUES ('a', 1, 1.5, 1, ?, x'ff', 'p')", at)
+	mustExec(t, db, "INSERT INTO legacy VALUES (NULL, NULL, NULL, NULL, NULL, NULL, NULL)")
+	const selectLegacy = "SELECT s, i, f, b, t, blob, probe FROM legacy ORDER BY rowid"
+
+	if _, err := csql.NewSQLTableManager[Legacy](db).Query(selectLegacy); err == nil || !strings.Contains(err.Error(), "NULL") {
+		t.Fatalf("Query without WithNullAsZero = %v, want the NULL conversion error", err)
+	}
+	rows, err := csql.NewSQLTableManager[Legacy](db, csql.WithNullAsZero()).Query(selectLegacy)
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("Query = %+v, %v", rows, err)
+	}
+	full := rows[0]
+	if full.S != "a" || full.I != 1 || full.F != 1.5 || !full.B || !full.T.Equal(at) || !bytes.Equal(full.Blob, []byte{0xff}) || full.Probe.null {
+		t.Errorf("row with values scanned as %+v", full)
+	}
+	// the Scanner is left to handle NULL itself
+	if zero := rows[1]; zero.S != "" || zero.I != 0 || zero.F != 0 || zero.B || !zero.T.IsZero() || zero.Blob != nil || !zero.Probe.null {
+		t.Errorf("row of NULLs scanned as %+v, want zero values", zero)
+	}
+	if row, err := csql.NewSQLTableManager[Legacy](db, csql.WithNullAsZero()).QueryRow(selectLegacy + " DESC"); err != nil || row.S != "" || !row.Probe.null {
+		t.Errorf("QueryRow of NULLs = %+v, %v, want zero values", row, err)
+	}
+}
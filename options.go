@@ -0,0 +1,157 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Option configures a SQLTableManager
+type Option func(*options) error
+
+type options struct {
+	dialect    Dialect
+	maxRows    int
+	table      string
+	softDelete string
+	logger     Logger
+	logArgs    bool
+	tracer     Tracer
+	metrics    Metrics
+	location   *time.Location
+	timeout    time.Duration
+	// statementTimeout is the server-side bound of WithStatementTimeout
+	statementTimeout time.Duration
+	acquireTimeout   time.Duration
+
+	dryRun      func(query string, args []any)
+	queries     *QueryStore
+	concurrency int
+
+	retryPolicy RetryPolicy
+	retryExec   bool
+	busyRetry   RetryPolicy
+
+	slowThreshold time.Duration
+	slowQuery     func(SlowQuery)
+	explainSlow   bool
+	// explainDB is the database slow queries are explained on
+	explainDB *sql.DB
+
+	errorQuery bool
+
+	rewriter func(ctx context.Context, query string) string
+
+	closeDB bool
+
+	auditHook  func(AuditEvent)
+	auditActor any
+
+	cache   *queryCache
+	flights *flightGroup
+
+	emptyFilterAll bool
+	strictAffected bool
+
+	onLeak  func(stack []byte)
+	leakAge time.Duration
+
+	progressEvery int
+	progressFn    func(done, total int)
+
+	hooks []Hook
+
+	execHooks  []ExecHook
+	execRedact func(args []any) []any
+
+	stats *statsRing
+
+	readOnly bool
+
+	nullAsZero bool
+
+	scanDiagnostics bool
+
+	optionalColumns []string
+
+	deadlineChunk int
+
+	argConverter func(any) (any, bool)
+
+	codecs map[reflect.Type]typeCodec
+
+	cipher *columnCipher
+
+	redactor Redactor
+	// redactColumns holds the lowercased columns the Schema redacts
+	redactColumns map[string]bool
+}
+
+func newOptions(opts []Option) (options, error) {
+	var o options
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return o, err
+		}
+	}
+	if o.softDelete != "" && o.table == "" {
+		return o, fmt.Errorf("csql: WithSoftDelete requires WithTable")
+	}
+	if o.retryExec && o.retryPolicy == nil {
+		return o, fmt.Errorf("csql: WithExecRetry requires WithRetry")
+	}
+	if o.leakAge > 0 && o.onLeak == nil {
+		return o, fmt.Errorf("csql: WithLeakAge requires WithLeakDetection")
+	}
+	return o, nil
+}
+
+// WithDialect sets the dialect used to rewrite query placeholders
+func WithDialect(d Dialect) Option {
+	return func(o *options) error {
+		if !d.valid() {
+			return fmt.Errorf("csql: unknown dialect %v", d)
+		}
+		o.dialect = d
+		return nil
+	}
+}
+
+// WithMaxRows caps the number of rows Query will return.
+// Queries yielding more rows fail with ErrTooManyRows
+func WithMaxRows(n int) Option {
+	return func(o *options) error {
+		if n <= 0 {
+			return fmt.Errorf("csql: max rows must be positive, got %d", n)
+		}
+		o.maxRows = n
+		return nil
+	}
+}
+
+// WithTable names the table used by generated statements such as Select and Delete
+func WithTable(name string) Option {
+	return func(o *options) error {
+		if name == "" {
+			return fmt.Errorf("csql: table name must not be empty")
+		}
+		o.table = name
+		return nil
+	}
+}
+
+// WithSoftDelete marks rows deleted by setting column rather than removing them.
+// Generated reads, such as Select, Get, and Count, skip rows where column is
+// set, see WithTrashed. Raw Query and QueryRow run their SQL as written, so
+// their queries must add column IS NULL themselves to skip deleted rows
+func WithSoftDelete(column string) Option {
+	return func(o *options) error {
+		if column == "" {
+			return fmt.Errorf("csql: soft delete column must not be empty")
+		}
+		o.softDelete = column
+		return nil
+	}
+}
@@ -0,0 +1,140 @@
+// Package otelcsql traces csql operations with OpenTelemetry spans.
+// It lives in its own module so csql itself stays dependency-free
+package otelcsql
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vtereso/csql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/vtereso/csql/otelcsql"
+
+// DefaultStatementLimit is the default maximum length of db.statement
+const DefaultStatementLimit = 1024
+
+// Option configures a Tracer
+type Option func(*Tracer)
+
+// WithStatementLimit truncates the db.statement attribute to n bytes
+func WithStatementLimit(n int) Option {
+	return func(t *Tracer) {
+		t.limit = n
+	}
+}
+
+// Tracer implements csql.Tracer, starting a client span per operation
+type Tracer struct {
+	tracer trace.Tracer
+	limit  int
+}
+
+var _ csql.Tracer = (*Tracer)(nil)
+
+// NewTracer returns a Tracer using tp, or the global provider when tp is nil.
+// Install it on a manager with csql.WithTracer
+func NewTracer(tp trace.TracerProvider, opts ...Option) *Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	t := &Tracer{
+		tracer: tp.Tracer(instrumentationName),
+		limit:  DefaultStatementLimit,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// TraceStart implements csql.Tracer, labelling the span per the
+// OpenTelemetry database conventions: db.system from the manager's dialect,
+// db.operation from the statement's leading keyword, such as SELECT,
+// db.sql.table from the manager's table when set, and db.statement. The
+// csql operation, such as Query, is recorded as csql.operation
+func (t *Tracer) TraceStart(ctx context.Context, op, query string) context.Context {
+	verb := sqlVerb(query)
+	if verb == "" {
+		verb = op
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("db.operation", verb),
+		attribute.String("db.statement", truncate(query, t.limit)),
+		attribute.String("csql.operation", op),
+	}
+	if target, ok := csql.TargetOf(ctx); ok {
+		attrs = append(attrs, attribute.String("db.system", dbSystem(target.Dialect)))
+		if target.Table != "" {
+			attrs = append(attrs, attribute.String("db.sql.table", target.Table))
+		}
+	}
+	ctx, _ = t.tracer.Start(ctx, "csql."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx
+}
+
+// dbSystem returns the db.system value of d
+func dbSystem(d csql.Dialect) string {
+	switch d {
+	case csql.Postgres:
+		return "postgresql"
+	case csql.MySQL:
+		return "mysql"
+	case csql.SQLite:
+		return "sqlite"
+	}
+	return "other_sql"
+}
+
+// sqlVerb returns the uppercased keyword query starts with, past
+// whitespace, comments, and opening parentheses, or "" when there is none
+func sqlVerb(query string) string {
+	for {
+		query = strings.TrimLeft(query, " \t\r\n(")
+		switch {
+		case strings.HasPrefix(query, "--"):
+			_, query, _ = strings.Cut(query, "\n")
+		case strings.HasPrefix(query, "/*"):
+			_, query, _ = strings.Cut(query, "*/")
+		default:
+			end := strings.IndexFunc(query, func(r rune) bool {
+				return !unicode.IsLetter(r)
+			})
+			if end < 0 {
+				end = len(query)
+			}
+			return strings.ToUpper(query[:end])
+		}
+	}
+}
+
+// TraceEnd implements csql.Tracer
+func (t *Tracer) TraceEnd(ctx context.Context, info csql.QueryInfo) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int64("db.rows", info.Rows))
+	if info.Err != nil {
+		span.RecordError(info.Err)
+		span.SetStatus(codes.Error, info.Err.Error())
+	}
+	span.End()
+}
+
+// truncate shortens s to at most n bytes without splitting a rune
+func truncate(s string, n int) string {
+	if n <= 0 || len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
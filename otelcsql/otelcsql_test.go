@@ -0,0 +1,175 @@
+package otelcsql_test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+	"github.com/vtereso/csql/otelcsql"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	_ "modernc.org/sqlite"
+)
+
+// Item is the Schema of the items table openManager creates
+type Item struct {
+	ID   int64
+	Name string
+}
+
+func (i *Item) ScanRow(s csql.RowScanner) error { return s.Scan(&i.ID, &i.Name) }
+
+func (i *Item) Fields() []any { return []any{i.ID, i.Name} }
+
+// openManager returns a manager of an in-memory sqlite items table holding
+// one item, traced into the returned recorder
+func openManager(t *testing.T, opts ...otelcsql.Option) (*csql.SQLTableManager[Item, *Item], *tracetest.SpanRecorder) {
+	t.Helper()
+	return openTraced(t, []csql.Option{csql.WithDialect(csql.SQLite), csql.WithTable("items")}, opts...)
+}
+
+// openTraced is openManager with the manager options managerOpts
+func openTraced(t *testing.T, managerOpts []csql.Option, opts ...otelcsql.Option) (*csql.SQLTableManager[Item, *Item], *tracetest.SpanRecorder) {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT); INSERT INTO items VALUES (1, 'item1')"); err != nil {
+		t.Fatal(err)
+	}
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	t.Cleanup(func() { tp.Shutdown(context.Background()) })
+	m := csql.NewSQLTableManager[Item](db, append(managerOpts, csql.WithTracer(otelcsql.NewTracer(tp, opts...)))...)
+	return m, rec
+}
+
+// attrs returns the attributes of span by key
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestTracer(t *testing.T) {
+	m, rec := openManager(t)
+	query := "/* list */ SELECT id, name FROM items"
+	if _, err := m.Query(query); err != nil {
+		t.Fatal(err)
+	}
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("%d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "csql.Query" || span.SpanKind() != trace.SpanKindClient || span.Status().Code != codes.Unset {
+		t.Fatalf("span %q of kind %v and status %v", span.Name(), span.SpanKind(), span.Status())
+	}
+	want := map[attribute.Key]attribute.Value{
+		"db.system":      attribute.StringValue("sqlite"),
+		"db.operation":   attribute.StringValue("SELECT"),
+		"db.statement":   attribute.StringValue(query),
+		"db.sql.table":   attribute.StringValue("items"),
+		"db.rows":        attribute.Int64Value(1),
+		"csql.operation": attribute.StringValue(csql.OpQuery),
+	}
+	got := attrs(span)
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k].Emit(), v.Emit())
+		}
+	}
+}
+
+func TestTracerError(t *testing.T) {
+	m, rec := openManager(t)
+	err := m.Exec("INSERT INTO items (id, name) VALUES (1, 'again')")
+	if err == nil {
+		t.Fatal("Exec of a duplicate = nil")
+	}
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("%d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Status().Code != codes.Error || len(span.Events()) != 1 || span.Events()[0].Name != "exception" {
+		t.Fatalf("span status %v with events %v, want the error recorded", span.Status(), span.Events())
+	}
+	if got := attrs(span)["db.operation"].AsString(); got != "INSERT" {
+		t.Fatalf("db.operation = %q, want INSERT", got)
+	}
+	if !strings.Contains(span.Status().Description, "UNIQUE") {
+		t.Fatalf("status %q, want the driver error", span.Status().Description)
+	}
+}
+
+func TestStatementLimit(t *testing.T) {
+	m, rec := openManager(t, otelcsql.WithStatementLimit(6))
+	if _, err := m.Query("SELECT id, name FROM items"); err != nil {
+		t.Fatal(err)
+	}
+	if got := attrs(rec.Ended()[0])["db.statement"].AsString(); got != "SELECT" {
+		t.Fatalf("db.statement = %q, want it truncated to 6 bytes", got)
+	}
+}
+
+func TestSemanticConventions(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []csql.Option
+		query     string
+		system    string
+		operation string
+		table     string
+	}{
+		{"postgres", []csql.Option{csql.WithDialect(csql.Postgres), csql.WithTable("items")}, "select id, name from items", "postgresql", "SELECT", "items"},
+		{"mysql", []csql.Option{csql.WithDialect(csql.MySQL), csql.WithTable("items")}, "-- count\n  SELECT id, name FROM items", "mysql", "SELECT", "items"},
+		{"generic without a table", nil, "WITH x AS (SELECT 1) SELECT id, name FROM items", "other_sql", "WITH", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, rec := openTraced(t, tt.opts)
+			if _, err := m.Query(tt.query); err != nil {
+				t.Fatal(err)
+			}
+			got := attrs(rec.Ended()[0])
+			if got["db.system"].AsString() != tt.system || got["db.operation"].AsString() != tt.operation || got["db.statement"].AsString() != tt.query {
+				t.Fatalf("db.system %q, db.operation %q, db.statement %q, want %q, %q, the query",
+					got["db.system"].AsString(), got["db.operation"].AsString(), got["db.statement"].AsString(), tt.system, tt.operation)
+			}
+			if table, ok := got["db.sql.table"]; ok != (tt.table != "") || table.AsString() != tt.table {
+				t.Fatalf("db.sql.table = %q, %t, want %q", table.AsString(), ok, tt.table)
+			}
+		})
+	}
+}
+
+func TestStatementLimitRunes(t *testing.T) {
+	const prefix = "SELECT id, name FROM items WHERE name <> '"
+	// the limit falls within the two bytes of é
+	m, rec := openManager(t, otelcsql.WithStatementLimit(len(prefix)+1))
+	if _, err := m.Query(prefix + "é'"); err != nil {
+		t.Fatal(err)
+	}
+	if got := attrs(rec.Ended()[0])["db.statement"].AsString(); got != prefix {
+		t.Fatalf("db.statement = %q, want it cut before the split rune", got)
+	}
+	long := prefix + strings.Repeat("x", otelcsql.DefaultStatementLimit) + "'"
+	m, rec = openManager(t)
+	if _, err := m.Query(long); err != nil {
+		t.Fatal(err)
+	}
+	if got := attrs(rec.Ended()[0])["db.statement"].AsString(); got != long[:otelcsql.DefaultStatementLimit] {
+		t.Fatalf("db.statement of %d bytes, want DefaultStatementLimit", len(got))
+	}
+}
@@ -0,0 +1,225 @@
+// Package pgarray scans and writes Postgres array columns without
+// depending on a Postgres driver
+package pgarray
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Element lists the supported array element types
+type Element interface {
+	int | int32 | int64 | float64 | string | bool | time.Time
+}
+
+// Array is a one-dimensional Postgres array of E, usable both as a Fields
+// destination and as an exec arg. A nil Array is SQL NULL, while an empty
+// one is '{}'. NULL elements and multidimensional arrays are rejected
+type Array[E Element] []E
+
+// Scan implements sql.Scanner
+func (a *Array[E]) Scan(src any) error {
+	var literal string
+	switch src := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		literal = string(src)
+	case string:
+		literal = src
+	default:
+		return fmt.Errorf("pgarray: cannot scan %T into Array", src)
+	}
+	elems, err := parse(literal)
+	if err != nil {
+		return err
+	}
+	out := make(Array[E], len(elems))
+	for i, elem := range elems {
+		if elem == nil {
+			return fmt.Errorf("pgarray: NULL element at index %d", i)
+		}
+		if err := parseElement(*elem, &out[i]); err != nil {
+			return fmt.Errorf("pgarray: element %d: %w", i, err)
+		}
+	}
+	*a = out
+	return nil
+}
+
+// Value implements driver.Valuer
+func (a Array[E]) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, elem := range a {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		formatElement(&b, elem)
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
+
+// parse splits a one-dimensional array literal into its elements, nil for NULL
+func parse(s string) ([]*string, error) {
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		if strings.HasPrefix(s, "[") {
+			return nil, fmt.Errorf("pgarray: arrays with explicit bounds are not supported")
+		}
+		return nil, fmt.Errorf("pgarray: malformed array literal %q", s)
+	}
+	body := s[1 : len(s)-1]
+	if body == "" {
+		return []*string{}, nil
+	}
+	var elems []*string
+	for i := 0; ; {
+		var elem *string
+		switch {
+		case i < len(body) && body[i] == '{':
+			return nil, fmt.Errorf("pgarray: multidimensional arrays are not supported")
+		case i < len(body) && body[i] == '"':
+			var b strings.Builder
+			i++
+			for ; i < len(body) && body[i] != '"'; i++ {
+				if body[i] == '\\' {
+					i++
+					if i == len(body) {
+						break
+					}
+				}
+				b.WriteByte(body[i])
+			}
+			if i >= len(body) {
+				return nil, fmt.Errorf("pgarray: unterminated quoted element in %q", s)
+			}
+			i++
+			v := b.String()
+			elem = &v
+		default:
+			j := strings.IndexByte(body[i:], ',')
+			if j < 0 {
+				j = len(body) - i
+			}
+			v := strings.TrimSpace(body[i : i+j])
+			i += j
+			if v == "" {
+				return nil, fmt.Errorf("pgarray: empty element in %q", s)
+			}
+			if !strings.EqualFold(v, "NULL") {
+				elem = &v
+			}
+		}
+		elems = append(elems, elem)
+		if i == len(body) {
+			return elems, nil
+		}
+		if body[i] != ',' {
+			return nil, fmt.Errorf("pgarray: expected ',' at offset %d in %q", i+1, s)
+		}
+		i++
+	}
+}
+
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02",
+}
+
+func parseElement[E Element](s string, dst *E) error {
+	switch p := any(dst).(type) {
+	case *int:
+		v, err := strconv.ParseInt(s, 10, strconv.IntSize)
+		*p = int(v)
+		return err
+	case *int32:
+		v, err := strconv.ParseInt(s, 10, 32)
+		*p = int32(v)
+		return err
+	case *int64:
+		v, err := strconv.ParseInt(s, 10, 64)
+		*p = v
+		return err
+	case *float64:
+		v, err := strconv.ParseFloat(s, 64)
+		*p = v
+		return err
+	case *string:
+		*p = s
+		return nil
+	case *bool:
+		switch s {
+		case "t", "true":
+			*p = true
+		case "f", "false":
+			*p = false
+		default:
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		return nil
+	case *time.Time:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				*p = t
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid time %q", s)
+	}
+	return fmt.Errorf("unsupported element type %T", dst)
+}
+
+func formatElement[E Element](b *strings.Builder, elem E) {
+	switch v := any(elem).(type) {
+	case int:
+		b.WriteString(strconv.Itoa(v))
+	case int32:
+		b.WriteString(strconv.FormatInt(int64(v), 10))
+	case int64:
+		b.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		switch {
+		case math.IsInf(v, 1):
+			b.WriteString("Infinity")
+		case math.IsInf(v, -1):
+			b.WriteString("-Infinity")
+		default:
+			b.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	case string:
+		quote(b, v)
+	case bool:
+		if v {
+			b.WriteByte('t')
+		} else {
+			b.WriteByte('f')
+		}
+	case time.Time:
+		quote(b, v.Format(time.RFC3339Nano))
+	}
+}
+
+// quote writes s as a double quoted element, escaping quotes and backslashes
+func quote(b *strings.Builder, s string) {
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('"')
+}
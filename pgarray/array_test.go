@@ -0,0 +1,157 @@
+package pgarray_test
+
+import (
+	"database/sql"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+	"github.com/vtereso/csql/pgarray"
+	_ "modernc.org/sqlite"
+)
+
+func TestScan(t *testing.T) {
+	var ints pgarray.Array[int64]
+	if err := ints.Scan([]byte("{1, 2,-3}")); err != nil || !slices.Equal(ints, []int64{1, 2, -3}) {
+		t.Fatalf("Scan = %v, %v", ints, err)
+	}
+	if err := ints.Scan("{}"); err != nil || ints == nil || len(ints) != 0 {
+		t.Fatalf("Scan of {} = %#v, %v, want empty, not nil", ints, err)
+	}
+	if err := ints.Scan(nil); err != nil || ints != nil {
+		t.Fatalf("Scan of NULL = %#v, %v, want nil", ints, err)
+	}
+	var strs pgarray.Array[string]
+	if err := strs.Scan(`{plain,"a,b","say \"hi\"","back\\slash",NULL}`); err == nil || !strings.Contains(err.Error(), "NULL element at index 4") {
+		t.Fatalf("Scan with a NULL element = %v, want it rejected", err)
+	}
+	if err := strs.Scan(`{plain,"a,b","say \"hi\"","back\\slash","NULL",""}`); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"plain", "a,b", `say "hi"`, `back\slash`, "NULL", ""}; !slices.Equal(strs, want) {
+		t.Fatalf("Scan = %q, want %q", strs, want)
+	}
+	var bools pgarray.Array[bool]
+	if err := bools.Scan("{t,f,true}"); err != nil || !slices.Equal(bools, []bool{true, false, true}) {
+		t.Fatalf("Scan = %v, %v", bools, err)
+	}
+	var times pgarray.Array[time.Time]
+	if err := times.Scan(`{"2024-03-01 12:00:00+00","2024-03-02"}`); err != nil || len(times) != 2 || !times[0].Equal(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Scan = %v, %v", times, err)
+	}
+}
+
+func TestScanRejects(t *testing.T) {
+	tests := []struct {
+		src  any
+		want string
+	}{
+		{"{{1,2},{3,4}}", "multidimensional"},
+		{"[1:2]={1,2}", "explicit bounds"},
+		{"1,2", "malformed"},
+		{`{"open}`, "unterminated"},
+		{"{1,,2}", "empty element"},
+		{"{1,x}", "element 1"},
+		{42, "cannot scan int"},
+	}
+	for _, tt := range tests {
+		var a pgarray.Array[int64]
+		if err := a.Scan(tt.src); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Scan(%v) = %v, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestValueRoundTrip(t *testing.T) {
+	strs := pgarray.Array[string]{"a,b", `q"uote`, `back\slash`, "NULL", ""}
+	v, err := strs.Value()
+	if want := `{"a,b","q\"uote","back\\slash","NULL",""}`; err != nil || v != want {
+		t.Fatalf("Value = %v, %v, want %s", v, err, want)
+	}
+	var back pgarray.Array[string]
+	if err := back.Scan(v); err != nil || !slices.Equal(back, strs) {
+		t.Fatalf("round trip = %q, %v, want %q", back, err, strs)
+	}
+	if v, err := (pgarray.Array[float64]{1.5, -2}).Value(); err != nil || v != "{1.5,-2}" {
+		t.Fatalf("Value = %v, %v", v, err)
+	}
+	if v, err := (pgarray.Array[int64]{}).Value(); err != nil || v != "{}" {
+		t.Fatalf("Value of empty = %v, %v, want {}", v, err)
+	}
+	if v, err := pgarray.Array[int64](nil).Value(); err != nil || v != nil {
+		t.Fatalf("Value of nil = %v, %v, want NULL", v, err)
+	}
+}
+
+func TestIntElements(t *testing.T) {
+	var ints pgarray.Array[int]
+	if err := ints.Scan("{7,-8}"); err != nil || !slices.Equal(ints, []int{7, -8}) {
+		t.Fatalf("Scan = %v, %v", ints, err)
+	}
+	if v, err := ints.Value(); err != nil || v != "{7,-8}" {
+		t.Fatalf("Value = %v, %v", v, err)
+	}
+	var small pgarray.Array[int32]
+	if err := small.Scan("{1,-2147483648}"); err != nil || !slices.Equal(small, []int32{1, math.MinInt32}) {
+		t.Fatalf("Scan = %v, %v", small, err)
+	}
+	if v, err := small.Value(); err != nil || v != "{1,-2147483648}" {
+		t.Fatalf("Value = %v, %v", v, err)
+	}
+	if err := small.Scan("{1,2147483648}"); err == nil || !strings.Contains(err.Error(), "element 1") {
+		t.Fatalf("Scan past int32 = %v, want the element rejected", err)
+	}
+	if err := ints.Scan("{1," + strconv.FormatUint(math.MaxUint64, 10) + "}"); err == nil {
+		t.Fatal("Scan past int succeeded")
+	}
+}
+
+// Post is a Schema with array columns, stored as their literals
+type Post struct {
+	ID     int64
+	Scores pgarray.Array[int]
+	Tags   pgarray.Array[string]
+}
+
+func (p *Post) ScanRow(s csql.RowScanner) error { return s.Scan(&p.ID, &p.Scores, &p.Tags) }
+
+func (p *Post) Fields() []any { return []any{p.ID, p.Scores, p.Tags} }
+
+func TestSchemaRoundTrip(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("CREATE TABLE posts (id INTEGER PRIMARY KEY, scores TEXT, tags TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	m := csql.NewSQLTableManager[Post](db)
+	posts := []Post{
+		{1, pgarray.Array[int]{3, -1, 40}, pgarray.Array[string]{"a,b", `say "hi", twice`, "{braced}", " padded "}},
+		{2, pgarray.Array[int]{}, pgarray.Array[string]{}},
+		{3, nil, nil},
+	}
+	if _, err := m.Transaction("INSERT INTO posts (id, scores, tags) VALUES (?, ?, ?)", posts); err != nil {
+		t.Fatal(err)
+	}
+	var literal string
+	if err := db.QueryRow("SELECT tags FROM posts WHERE id = 1").Scan(&literal); err != nil || literal != `{"a,b","say \"hi\", twice","{braced}"," padded "}` {
+		t.Fatalf("stored tags %s, %v", literal, err)
+	}
+	got, err := m.Query("SELECT id, scores, tags FROM posts ORDER BY id")
+	if err != nil || len(got) != len(posts) {
+		t.Fatalf("Query = %v, %v", got, err)
+	}
+	for i, p := range got {
+		want := posts[i]
+		if p.ID != want.ID || !slices.Equal(p.Scores, want.Scores) || !slices.Equal(p.Tags, want.Tags) || (p.Scores == nil) != (want.Scores == nil) || (p.Tags == nil) != (want.Tags == nil) {
+			t.Errorf("row %d = %#v, want %#v", i, p, want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package pgxtable implements csql.SQLTable on a pgx connection pool,
+// bypassing database/sql. It lives in its own module so csql itself stays
+// dependency-free
+package pgxtable
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/vtereso/csql"
+)
+
+// Table manages a Postgres table through a Schema definition, like
+// csql.SQLTableManager with the Postgres dialect. ? placeholders are
+// rewritten to $1, $2, ..., no row is reported as sql.ErrNoRows, and
+// failures are annotated with the method as csql does. It is safe for
+// concurrent use
+type Table[T any, R csql.Schema[T]] struct {
+	pool *pgxpool.Pool
+}
+
+var _ csql.SQLTable[nopSchema, *nopSchema] = (*Table[nopSchema, *nopSchema])(nil)
+
+// New returns a Table running its statements on pool
+func New[T any, R csql.Schema[T]](pool *pgxpool.Pool) *Table[T, R] {
+	return &Table[T, R]{pool: pool}
+}
+
+func (t *Table[T, R]) Query(query string, args ...any) ([]T, error) {
+	return t.QueryContext(context.Background(), query, args...)
+}
+
+func (t *Table[T, R]) QueryContext(ctx context.Context, query string, args ...any) (res []T, err error) {
+	defer annotate(&err, "Query")
+	rows, err := t.pool.Query(ctx, csql.Postgres.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var row T
+		if err := R(&row).ScanRow(rows); err != nil {
+			return nil, err
+		}
+		res = append(res, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *Table[T, R]) QueryRow(query string, args ...any) (T, error) {
+	return t.QueryRowContext(context.Background(), query, args...)
+}
+
+func (t *Table[T, R]) QueryRowContext(ctx context.Context, query string, args ...any) (row T, err error) {
+	defer annotate(&err, "QueryRow")
+	if err := R(&row).ScanRow(t.pool.QueryRow(ctx, csql.Postgres.Rebind(query), args...)); err != nil {
+		var zero T
+		return zero, err
+	}
+	return row, nil
+}
+
+func (t *Table[T, R]) Exec(query string, args ...any) error {
+	return t.ExecContext(context.Background(), query, args...)
+}
+
+func (t *Table[T, R]) ExecContext(ctx context.Context, query string, args ...any) (err error) {
+	defer annotate(&err, "Exec")
+	_, err = t.pool.Exec(ctx, csql.Postgres.Rebind(query), args...)
+	return err
+}
+
+func (t *Table[T, R]) Transaction(transaction string, rows []T) (bool, error) {
+	return t.TransactionContext(context.Background(), transaction, rows)
+}
+
+// TransactionContext prepares transaction and sends it once per row, bound
+// to the row's Fields, as a single batch within a database transaction. It
+// reports whether the transaction committed
+func (t *Table[T, R]) TransactionContext(ctx context.Context, transaction string, rows []T) (ok bool, err error) {
+	defer annotate(&err, "Transaction")
+	transaction = csql.Postgres.Rebind(transaction)
+	tx, err := t.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	// Naming the statement after its SQL lets the batch reuse it, and
+	// surfaces syntax errors even when there are no rows
+	if _, err := tx.Prepare(ctx, transaction, transaction); err != nil {
+		return false, rollback(ctx, tx, err)
+	}
+	batch := &pgx.Batch{}
+	for i := range rows {
+		batch.Queue(transaction, R(&rows[i]).Fields()...)
+	}
+	results := tx.SendBatch(ctx, batch)
+	for range rows {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return false, rollback(ctx, tx, err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		return false, rollback(ctx, tx, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CopyFrom bulk loads rows into table with the COPY protocol, sending each
+// row's Fields for columns, in order, and returns the number of rows
+// copied. table may be schema-qualified as schema.table; it and columns
+// are quoted as identifiers
+func (t *Table[T, R]) CopyFrom(ctx context.Context, table string, columns []string, rows []T) (n int64, err error) {
+	defer annotate(&err, "CopyFrom")
+	return t.pool.CopyFrom(ctx, pgx.Identifier(strings.Split(table, ".")), columns, pgx.CopyFromSlice(len(rows), func(i int) ([]any, error) {
+		fields := R(&rows[i]).Fields()
+		if len(fields) != len(columns) {
+			return nil, fmt.Errorf("row %d has %d fields for %d columns", i, len(fields), len(columns))
+		}
+		return fields, nil
+	}))
+}
+
+// rollback aborts tx, joining any rollback failure onto err
+func rollback(ctx context.Context, tx pgx.Tx, err error) error {
+	if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+		return errors.Join(err, rbErr)
+	}
+	return err
+}
+
+// annotate prefixes a failure of method as csql does, reporting a missing
+// row as sql.ErrNoRows
+func annotate(err *error, method string) {
+	if *err == nil {
+		return
+	}
+	if errors.Is(*err, pgx.ErrNoRows) {
+		*err = sql.ErrNoRows
+	}
+	*err = fmt.Errorf("csql: %s failed: %w", method, *err)
+}
+
+type nopSchema struct{}
+
+func (*nopSchema) ScanRow(csql.RowScanner) error { return nil }
+func (*nopSchema) Fields() []any                 { return nil }
@@ -0,0 +1,52 @@
+package pgxtable_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/vtereso/csql"
+	"github.com/vtereso/csql/csqltest"
+	"github.com/vtereso/csql/pgxtable"
+)
+
+// openPool connects to the database PGX_TEST_DSN names, skipping t when
+// it is unset, and creates an empty conformance table named table
+func openPool(t *testing.T, table string) *pgxpool.Pool {
+	dsn := os.Getenv("PGX_TEST_DSN")
+	if dsn == "" {
+		t.Skip("PGX_TEST_DSN is not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	if _, err := pool.Exec(ctx, "DROP TABLE IF EXISTS "+table); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, fmt.Sprintf(csqltest.ConformanceTable, table)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pool.Exec(context.Background(), "DROP TABLE "+table) })
+	return pool
+}
+
+func TestConformance(t *testing.T) {
+	pool := openPool(t, "pgxtable_conformance")
+	csqltest.RunConformance(t, pgxtable.New[csqltest.ConformanceRow](pool), "pgxtable_conformance")
+}
+
+// TestConformanceDatabaseSQL holds the database/sql manager to the suite on
+// the same database, so both backends are checked against one Postgres
+func TestConformanceDatabaseSQL(t *testing.T) {
+	pool := openPool(t, "pgxtable_conformance_sql")
+	db := stdlib.OpenDBFromPool(pool)
+	t.Cleanup(func() { db.Close() })
+	m := csql.NewSQLTableManager[csqltest.ConformanceRow](db, csql.WithDialect(csql.Postgres))
+	csqltest.RunConformance(t, m, "pgxtable_conformance_sql")
+}
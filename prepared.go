@@ -0,0 +1,94 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// PreparedTransaction is a Transaction statement prepared once and reused
+// by every Exec. It is safe for concurrent use
+type PreparedTransaction[T any, R Schema[T]] struct {
+	m           *SQLTableManager[T, R]
+	transaction string
+	// stmt is nil under WithDryRun, which never reaches the database
+	stmt *sql.Stmt
+	// release ends leak detection, see WithLeakDetection
+	release func()
+}
+
+// Prepare prepares transaction for repeated Exec calls. The statement must
+// be released with Close
+func (m *SQLTableManager[T, R]) Prepare(ctx context.Context, transaction string) (p *PreparedTransaction[T, R], err error) {
+	defer m.opts.annotate(&err, "Prepare", transaction)
+	if err := m.opts.writable(); err != nil {
+		return nil, err
+	}
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	p = &PreparedTransaction[T, R]{m: m, transaction: m.opts.finalize(ctx, transaction, nil)}
+	if m.opts.dryRun != nil {
+		return p, nil
+	}
+	if p.stmt, err = m.db.PrepareContext(ctx, p.transaction); err != nil {
+		return nil, err
+	}
+	p.release = trackLeak(&m.opts, p)
+	return p, nil
+}
+
+// Exec executes the statement once per row within its own database
+// transaction, like Transaction
+func (p *PreparedTransaction[T, R]) Exec(ctx context.Context, rows []T) (ok bool, err error) {
+	m := p.m
+	defer m.opts.annotate(&err, "Transaction", p.transaction)
+	var affected int64
+	defer func() { m.opts.audit(ctx, "Transaction", affected, err) }()
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	argsFn := convertingArgs[T, R](&m.opts, nil)
+	if m.opts.dryRun != nil {
+		for _, row := range rows {
+			m.opts.dryRun(p.transaction, bindArgs[T, R](argsFn, &row))
+		}
+		return false, nil
+	}
+	var after func(*error)
+	if ctx, after, err = m.opts.beforeExec(ctx, "Transaction", p.transaction, nil, len(rows)); err != nil {
+		return false, err
+	}
+	defer after(&err)
+	defer m.opts.invalidate()
+	var execed int
+	if m.opts.observed() {
+		var start time.Time
+		ctx, start = m.opts.begin(ctx, OpTransaction, p.transaction, nil)
+		defer func() {
+			m.opts.observe(ctx, OpTransaction, p.transaction, nil, start, int64(execed), err)
+		}()
+	}
+	tx, err := m.beginTx(ctx)
+	if err != nil {
+		return false, err
+	}
+	stmt := tx.StmtContext(ctx, p.stmt)
+	defer stmt.Close()
+	if affected, err = execRows[T, R](ctx, stmt, rows, argsFn, &execed, m.opts.progress(len(rows)), m.opts.rowFailure(m.columns())); err != nil {
+		affected = 0
+		return false, rollback(tx, err)
+	}
+	if err = tx.Commit(); err != nil {
+		affected = 0
+		return false, err
+	}
+	return true, nil
+}
+
+// Close releases the prepared statement
+func (p *PreparedTransaction[T, R]) Close() error {
+	if p.stmt == nil {
+		return nil
+	}
+	p.release()
+	return p.stmt.Close()
+}
@@ -0,0 +1,95 @@
+package csql_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// benchBatch is the number of rows each benchmarked Transaction stores
+const benchBatch = 10
+
+// nextItems returns benchBatch items with ids following *id
+func nextItems(id *int64) []Item {
+	rows := make([]Item, benchBatch)
+	for i := range rows {
+		*id++
+		rows[i] = Item{ID: *id, Name: "bench"}
+	}
+	return rows
+}
+
+const insertItem = "INSERT INTO items (id, name) VALUES (?, ?)"
+
+func BenchmarkTransaction(b *testing.B) {
+	m := csql.NewSQLTableManager[Item](openDB(b))
+	var id int64
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if ok, err := m.Transaction(insertItem, nextItems(&id)); !ok || err != nil {
+			b.Fatal(ok, err)
+		}
+	}
+}
+
+func BenchmarkPreparedTransaction(b *testing.B) {
+	ctx := context.Background()
+	m := csql.NewSQLTableManager[Item](openDB(b))
+	p, err := m.Prepare(ctx, insertItem)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer p.Close()
+	var id int64
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if ok, err := p.Exec(ctx, nextItems(&id)); !ok || err != nil {
+			b.Fatal(ok, err)
+		}
+	}
+}
+
+func TestPreparedTransaction(t *testing.T) {
+	ctx := context.Background()
+	db, rec := openRecorded(t, nil)
+	m := csql.NewSQLTableManager[Item](db)
+	p, err := m.Prepare(ctx, insertItem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var id int64
+	for i := 0; i < 2; i++ {
+		if ok, err := p.Exec(ctx, nextItems(&id)); !ok || err != nil {
+			t.Fatalf("Exec %d = %v, %v", i, ok, err)
+		}
+	}
+	if n := rec.Count("PREPARE " + insertItem); n != 1 {
+		t.Fatalf("prepared %d times, want once across Execs", n)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := m.Query("SELECT id, name FROM items")
+	if err != nil || len(rows) != 2*benchBatch {
+		t.Fatalf("Query = %d rows, %v, want %d", len(rows), err, 2*benchBatch)
+	}
+}
+
+func TestPreparedTransactionDryRun(t *testing.T) {
+	ctx := context.Background()
+	var c capture
+	m := csql.NewSQLTableManager[Badge](openDB(t), csql.WithDryRun(c.record), lowerCodes)
+	p, err := m.Prepare(ctx, "INSERT INTO badges (id, code) VALUES (?, ?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if ok, err := p.Exec(ctx, []Badge{{ID: 1, Code: "AB"}}); ok || err != nil {
+		t.Fatalf("Exec = %t, %v", ok, err)
+	}
+	if got := fmt.Sprint(c.lines); got != "[INSERT INTO badges (id, code) VALUES (?, ?) [1 ab]]" {
+		t.Fatalf("captured %s, want the args through the codec", got)
+	}
+}
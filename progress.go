@@ -0,0 +1,40 @@
+package csql
+
+import "fmt"
+
+// WithProgress calls fn with the number of rows executed so far after every
+// every rows of a Transaction, a PreparedTransaction Exec, or a
+// TransactionFrom, and once more when the last row has executed, before
+// commit. total is the number of rows given, or -1 for TransactionFrom.
+// fn runs on the transaction's goroutine, holding it open, so it must be
+// fast. A non-positive every reports every 1000 rows
+func WithProgress(every int, fn func(done, total int)) Option {
+	return func(o *options) error {
+		if fn == nil {
+			return fmt.Errorf("csql: progress callback must not be nil")
+		}
+		if every <= 0 {
+			every = 1000
+		}
+		o.progressEvery = every
+		o.progressFn = fn
+		return nil
+	}
+}
+
+// progress returns the per-row progress reporter for a transaction of total
+// rows, or nil when WithProgress is not configured
+func (o *options) progress(total int) func(done int, final bool) {
+	if o.progressFn == nil {
+		return nil
+	}
+	fn, every := o.progressFn, o.progressEvery
+	reported := -1
+	return func(done int, final bool) {
+		if final && done == reported || !final && done%every != 0 {
+			return
+		}
+		fn(done, total)
+		reported = done
+	}
+}
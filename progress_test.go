@@ -0,0 +1,61 @@
+package csql_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// progressLog is a WithProgress callback keeping every report
+type progressLog struct {
+	reports []string
+}
+
+func (l *progressLog) report(done, total int) {
+	l.reports = append(l.reports, fmt.Sprintf("%d/%d", done, total))
+}
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  int
+		calls int
+		last  string
+	}{
+		{"whole intervals", 10000, 10, "10000/10000"},
+		{"final partial interval", 10500, 11, "10500/10500"},
+		{"under one interval", 10, 1, "10/10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l progressLog
+			m := csql.NewSQLTableManager[Item](openDB(t), csql.WithProgress(1000, l.report))
+			if ok, err := m.Transaction(insertItem, items(tt.rows)); !ok || err != nil {
+				t.Fatalf("Transaction = %t, %v", ok, err)
+			}
+			if len(l.reports) != tt.calls || l.reports[len(l.reports)-1] != tt.last {
+				t.Fatalf("reported %d times ending %v, want %d ending %s", len(l.reports), l.reports[len(l.reports)-1:], tt.calls, tt.last)
+			}
+			if tt.rows >= 1000 && l.reports[0] != fmt.Sprintf("1000/%d", tt.rows) {
+				t.Fatalf("first report %s, want after 1000 rows", l.reports[0])
+			}
+		})
+	}
+}
+
+func TestProgressStreamed(t *testing.T) {
+	var l progressLog
+	m := csql.NewSQLTableManager[Item](openDB(t), csql.WithProgress(0, l.report))
+	if err := m.TransactionFrom(insertItem, generate(2500, 0, nil)); err != nil {
+		t.Fatal(err)
+	}
+	// a non-positive interval reports every 1000 rows
+	if got := strings.Join(l.reports, " "); got != "1000/-1 2000/-1 2500/-1" {
+		t.Fatalf("reported %s, want an unknown total", got)
+	}
+	if got := constructPanic(func() { csql.NewSQLTableManager[Item](openDB(t), csql.WithProgress(1, nil)) }); !strings.Contains(got, "must not be nil") {
+		t.Fatalf("NewSQLTableManager panicked with %q, want the nil callback rejected", got)
+	}
+}
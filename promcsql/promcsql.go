@@ -0,0 +1,113 @@
+// Package promcsql exports csql operation metrics to Prometheus.
+// It lives in its own module so csql itself stays dependency-free
+package promcsql
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vtereso/csql"
+)
+
+// Option configures a Metrics
+type Option func(*config)
+
+type config struct {
+	namespace string
+	buckets   []float64
+	queryName func(query string) string
+}
+
+// WithNamespace prefixes every metric name with ns
+func WithNamespace(ns string) Option {
+	return func(c *config) {
+		c.namespace = ns
+	}
+}
+
+// WithBuckets sets the duration histogram buckets, in seconds
+func WithBuckets(buckets []float64) Option {
+	return func(c *config) {
+		c.buckets = buckets
+	}
+}
+
+// WithQueryName adds a query label derived from the SQL text by fn.
+// fn must map queries onto a small set of names to bound label cardinality
+func WithQueryName(fn func(query string) string) Option {
+	return func(c *config) {
+		c.queryName = fn
+	}
+}
+
+// Metrics implements csql.Metrics with Prometheus collectors
+type Metrics struct {
+	duration  *prometheus.HistogramVec
+	rows      *prometheus.CounterVec
+	inFlight  *prometheus.GaugeVec
+	queryName func(query string) string
+}
+
+var _ csql.Metrics = (*Metrics)(nil)
+
+// New returns a Metrics whose collectors are registered with reg.
+// Install it on a manager with csql.WithMetrics
+func New(reg prometheus.Registerer, opts ...Option) (*Metrics, error) {
+	c := config{
+		namespace: "csql",
+		buckets:   prometheus.DefBuckets,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	labels := []string{"op", "status"}
+	if c.queryName != nil {
+		labels = append(labels, "query")
+	}
+	m := &Metrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: c.namespace,
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of csql operations.",
+			Buckets:   c.buckets,
+		}, labels),
+		rows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: c.namespace,
+			Name:      "rows_total",
+			Help:      "Rows returned, affected, or executed by csql operations.",
+		}, labels),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: c.namespace,
+			Name:      "operations_in_flight",
+			Help:      "Number of csql operations currently running.",
+		}, []string{"op"}),
+		queryName: c.queryName,
+	}
+	for _, collector := range []prometheus.Collector{m.duration, m.rows, m.inFlight} {
+		if err := reg.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// ObserveQuery implements csql.Metrics
+func (m *Metrics) ObserveQuery(op, query string, duration time.Duration, rows int, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	labels := []string{op, status}
+	if m.queryName != nil {
+		labels = append(labels, m.queryName(query))
+	}
+	m.duration.WithLabelValues(labels...).Observe(duration.Seconds())
+	if rows > 0 {
+		m.rows.WithLabelValues(labels...).Add(float64(rows))
+	}
+}
+
+// AddInFlight implements csql.Metrics
+func (m *Metrics) AddInFlight(op string, delta int) {
+	m.inFlight.WithLabelValues(op).Add(float64(delta))
+}
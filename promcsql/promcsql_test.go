@@ -0,0 +1,118 @@
+package promcsql_test
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vtereso/csql"
+	"github.com/vtereso/csql/promcsql"
+	_ "modernc.org/sqlite"
+)
+
+// Item is the Schema of the items table openManager creates
+type Item struct {
+	ID   int64
+	Name string
+}
+
+func (i *Item) ScanRow(s csql.RowScanner) error { return s.Scan(&i.ID, &i.Name) }
+
+func (i *Item) Fields() []any { return []any{i.ID, i.Name} }
+
+// openManager returns a manager of an in-memory items table holding two
+// items, reporting to metrics registered with reg
+func openManager(t *testing.T, reg prometheus.Registerer, opts ...promcsql.Option) *csql.SQLTableManager[Item, *Item] {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT); INSERT INTO items VALUES (1, 'item1'), (2, 'item2')"); err != nil {
+		t.Fatal(err)
+	}
+	mx, err := promcsql.New(reg, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return csql.NewSQLTableManager[Item](db, csql.WithMetrics(mx))
+}
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	m := openManager(t, reg)
+	for i := 0; i < 2; i++ {
+		if _, err := m.Query("SELECT id, name FROM items"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Exec("INSERT INTO items (id, name) VALUES (1, 'again')"); err == nil {
+		t.Fatal("Exec of a duplicate = nil")
+	}
+	want := `
+# HELP csql_rows_total Rows returned, affected, or executed by csql operations.
+# TYPE csql_rows_total counter
+csql_rows_total{op="Query",status="success"} 4
+# HELP csql_operations_in_flight Number of csql operations currently running.
+# TYPE csql_operations_in_flight gauge
+csql_operations_in_flight{op="Exec"} 0
+csql_operations_in_flight{op="Query"} 0
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "csql_rows_total", "csql_operations_in_flight"); err != nil {
+		t.Fatal(err)
+	}
+	for labels, count := range map[[2]string]uint64{{"Query", "success"}: 2, {"Exec", "error"}: 1} {
+		if got := histogramCount(t, reg, "csql_operation_duration_seconds", labels[0], labels[1]); got != count {
+			t.Errorf("%v durations observed %d times, want %d", labels, got, count)
+		}
+	}
+}
+
+func TestMetricsOptions(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	m := openManager(t, reg, promcsql.WithNamespace("app"), promcsql.WithBuckets([]float64{1}),
+		promcsql.WithQueryName(func(string) string { return "list" }))
+	if _, err := m.Query("SELECT id, name FROM items"); err != nil {
+		t.Fatal(err)
+	}
+	want := `
+# HELP app_rows_total Rows returned, affected, or executed by csql operations.
+# TYPE app_rows_total counter
+app_rows_total{op="Query",query="list",status="success"} 2
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "app_rows_total"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := promcsql.New(reg, promcsql.WithNamespace("app")); err == nil {
+		t.Fatal("New registering the same collectors again = nil")
+	}
+}
+
+// histogramCount returns the sample count of the histogram name for the
+// op and status labels, scraped from reg
+func histogramCount(t *testing.T, reg prometheus.Gatherer, name, op, status string) uint64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+		for _, metric := range f.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range metric.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["op"] == op && labels["status"] == status {
+				return metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+package csql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrEmptyFilter is returned by QueryBy and QueryByFields for a filter
+// without conditions, unless WithEmptyFilterMatchesAll is configured
+var ErrEmptyFilter = errors.New("csql: empty filter")
+
+// WithEmptyFilterMatchesAll makes QueryBy and QueryByFields return every
+// table row for a filter without conditions rather than ErrEmptyFilter
+func WithEmptyFilterMatchesAll() Option {
+	return func(o *options) error {
+		o.emptyFilterAll = true
+		return nil
+	}
+}
+
+// QueryBy returns the table rows equal to filter in each of its non-zero
+// fields. Nil pointers, zero times, false, and other zero values count as
+// unset; use QueryByFields to match them.
+// Soft-deleted rows are skipped unless the manager came from WithTrashed
+func (m *SQLTableManager[T, R]) QueryBy(ctx context.Context, filter T) ([]T, error) {
+	return m.queryBy(ctx, filter, nil)
+}
+
+// QueryByFields returns the table rows equal to filter in each of columns,
+// zero or not. A NULL field matches with IS NULL
+func (m *SQLTableManager[T, R]) QueryByFields(ctx context.Context, filter T, columns ...string) ([]T, error) {
+	if columns == nil {
+		columns = []string{}
+	}
+	return m.queryBy(ctx, filter, columns)
+}
+
+// queryBy matches the non-zero fields of filter, or the fields named by
+// mask when it is not nil
+func (m *SQLTableManager[T, R]) queryBy(ctx context.Context, filter T, mask []string) ([]T, error) {
+	if m.opts.table == "" {
+		return nil, ErrNoTable
+	}
+	cols := m.columns()
+	fields := R(&filter).Fields()
+	if len(cols) != len(fields) {
+		return nil, fmt.Errorf("csql: schema names %d columns but Fields returns %d", len(cols), len(fields))
+	}
+	var conds []string
+	var args []any
+	// argCols names the column each of args binds to
+	var argCols []string
+	if mask == nil {
+		for i, f := range fields {
+			if f != nil && !reflect.ValueOf(f).IsZero() {
+				conds = append(conds, cols[i]+" = ?")
+				args, argCols = append(args, f), append(argCols, cols[i])
+			}
+		}
+	}
+	for _, c := range mask {
+		i := indexFold(cols, c)
+		if i < 0 {
+			return nil, fmt.Errorf("%w %q", ErrUnknownColumn, c)
+		}
+		if v, err := driver.DefaultParameterConverter.ConvertValue(fields[i]); err == nil && v == nil {
+			conds = append(conds, cols[i]+" IS NULL")
+			continue
+		}
+		conds = append(conds, cols[i]+" = ?")
+		args, argCols = append(args, fields[i]), append(argCols, cols[i])
+	}
+	if len(conds) == 0 && !m.opts.emptyFilterAll {
+		return nil, ErrEmptyFilter
+	}
+	query := "SELECT " + strings.Join(cols, ", ") + " FROM " + m.opts.table + m.scope(strings.Join(conds, " AND "), !m.withTrashed)
+	return m.QueryContext(withArgColumns(ctx, argCols), query, args...)
+}
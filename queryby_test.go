@@ -0,0 +1,75 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// Member is a reflected Schema with zero values worth matching
+type Member struct {
+	ID     int64   `csql:"id"`
+	Name   string  `csql:"name"`
+	Active bool    `csql:"active"`
+	Note   *string `csql:"note"`
+}
+
+func (m *Member) ScanRow(r csql.RowScanner) error { return csql.ScanInto(r, m) }
+
+func (m *Member) Fields() []any { return csql.ReflectFields(m) }
+
+func TestQueryBy(t *testing.T) {
+	ctx := context.Background()
+	db, rec := openRecorded(t, nil)
+	mustExec(t, db, "CREATE TABLE members (id INTEGER PRIMARY KEY, name TEXT, active BOOLEAN, note TEXT)")
+	mustExec(t, db, "INSERT INTO members VALUES (1, 'ann', 1, 'x'), (2, 'bob', 0, NULL), (3, 'ann', 0, NULL)")
+	m := csql.NewSQLTableManager[Member](db, csql.WithTable("members"))
+	note := "x"
+	tests := []struct {
+		name   string
+		filter Member
+		mask   []string
+		sql    string
+		want   []int64
+	}{
+		{"non-zero fields", Member{Name: "ann"}, nil, "SELECT id, name, active, note FROM members WHERE (name = ?)", []int64{1, 3}},
+		{"pointer", Member{Name: "ann", Note: &note}, nil, "SELECT id, name, active, note FROM members WHERE (name = ? AND note = ?)", []int64{1}},
+		{"zero bool masked", Member{Name: "ann"}, []string{"name", "active"}, "SELECT id, name, active, note FROM members WHERE (name = ? AND active = ?)", []int64{3}},
+		{"nil pointer masked", Member{}, []string{"note"}, "SELECT id, name, active, note FROM members WHERE (note IS NULL)", []int64{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(rec.Stmts())
+			var rows []Member
+			var err error
+			if tt.mask == nil {
+				rows, err = m.QueryBy(ctx, tt.filter)
+			} else {
+				rows, err = m.QueryByFields(ctx, tt.filter, tt.mask...)
+			}
+			var ids []int64
+			for _, r := range rows {
+				ids = append(ids, r.ID)
+			}
+			if err != nil || !slices.Equal(ids, tt.want) {
+				t.Fatalf("matched %v, %v, want %v", ids, err, tt.want)
+			}
+			if stmts := rec.Stmts()[before:]; len(stmts) != 1 || stmts[0].SQL != tt.sql {
+				t.Fatalf("sent %+v, want %q", stmts, tt.sql)
+			}
+		})
+	}
+	if _, err := m.QueryBy(ctx, Member{}); !errors.Is(err, csql.ErrEmptyFilter) {
+		t.Fatalf("QueryBy of an empty filter = %v, want ErrEmptyFilter", err)
+	}
+	if _, err := m.QueryByFields(ctx, Member{}, "owner"); !errors.Is(err, csql.ErrUnknownColumn) {
+		t.Fatalf("QueryByFields of an unknown column = %v, want ErrUnknownColumn", err)
+	}
+	all := csql.NewSQLTableManager[Member](db, csql.WithTable("members"), csql.WithEmptyFilterMatchesAll())
+	if rows, err := all.QueryBy(ctx, Member{}); err != nil || len(rows) != 3 {
+		t.Fatalf("QueryBy of an empty filter = %v, %v, want every row", rows, err)
+	}
+}
@@ -0,0 +1,112 @@
+package csql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ErrQueryNotFound is returned when a QueryStore has no query by a name
+var ErrQueryNotFound = errors.New("csql: query not found")
+
+// QueryStore holds SQL loaded from .sql files, keyed by file name
+type QueryStore struct {
+	queries map[string]string
+}
+
+// NewQueryStore loads every .sql file under dir in fsys, typically an
+// embed.FS. Each query is named by its file name without the extension,
+// so names must be unique across subdirectories
+func NewQueryStore(fsys fs.FS, dir string) (*QueryStore, error) {
+	s := &QueryStore{queries: make(map[string]string)}
+	origin := make(map[string]string)
+	err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != ".sql" {
+			return nil
+		}
+		name := strings.TrimSuffix(d.Name(), ".sql")
+		if prev, ok := origin[name]; ok {
+			return fmt.Errorf("csql: duplicate query %q in %s and %s", name, prev, p)
+		}
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		origin[name] = p
+		s.queries[name] = strings.TrimSpace(string(b))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get returns the query named name
+func (s *QueryStore) Get(name string) (string, error) {
+	q, ok := s.queries[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrQueryNotFound, name)
+	}
+	return q, nil
+}
+
+// WithQueryStore lets the manager run queries from store by name
+func WithQueryStore(store *QueryStore) Option {
+	return func(o *options) error {
+		if store == nil {
+			return fmt.Errorf("csql: query store must not be nil")
+		}
+		o.queries = store
+		return nil
+	}
+}
+
+func (m *SQLTableManager[T, R]) named(name string) (string, error) {
+	if m.opts.queries == nil {
+		return "", fmt.Errorf("%w: %q, no query store configured", ErrQueryNotFound, name)
+	}
+	return m.opts.queries.Get(name)
+}
+
+// QueryNamed runs Query with the stored query name
+func (m *SQLTableManager[T, R]) QueryNamed(ctx context.Context, name string, args ...any) ([]T, error) {
+	query, err := m.named(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.QueryContext(ctx, query, args...)
+}
+
+// QueryRowNamed runs QueryRow with the stored query name
+func (m *SQLTableManager[T, R]) QueryRowNamed(ctx context.Context, name string, args ...any) (row T, err error) {
+	query, err := m.named(name)
+	if err != nil {
+		return row, err
+	}
+	return m.QueryRowContext(ctx, query, args...)
+}
+
+// ExecNamed runs Exec with the stored query name
+func (m *SQLTableManager[T, R]) ExecNamed(ctx context.Context, name string, args ...any) error {
+	query, err := m.named(name)
+	if err != nil {
+		return err
+	}
+	return m.ExecContext(ctx, query, args...)
+}
+
+// TransactionNamed runs Transaction with the stored query name
+func (m *SQLTableManager[T, R]) TransactionNamed(ctx context.Context, name string, rows []T) (bool, error) {
+	transaction, err := m.named(name)
+	if err != nil {
+		return false, err
+	}
+	return m.TransactionContext(ctx, transaction, rows)
+}
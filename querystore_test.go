@@ -0,0 +1,59 @@
+package csql_test
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/vtereso/csql"
+)
+
+//go:embed testdata/queries
+var queryFiles embed.FS
+
+func TestQueryStore(t *testing.T) {
+	store, err := csql.NewQueryStore(queryFiles, "testdata/queries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q, err := store.Get("select_items"); err != nil || q != selectItems {
+		t.Fatalf("Get = %q, %v, want the trimmed file", q, err)
+	}
+	if _, err := store.Get("README"); !errors.Is(err, csql.ErrQueryNotFound) {
+		t.Fatalf("Get of a non-.sql file = %v, want ErrQueryNotFound", err)
+	}
+	db := openDB(t)
+	m := csql.NewSQLTableManager[Item](db, csql.WithQueryStore(store))
+	ctx := context.Background()
+	if err := m.ExecNamed(ctx, "insert_item", 1, "item1"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := m.TransactionNamed(ctx, "insert_item", []Item{{2, "item2"}}); !ok || err != nil {
+		t.Fatalf("TransactionNamed = %t, %v", ok, err)
+	}
+	if got, err := m.QueryNamed(ctx, "select_items"); err != nil || len(got) != 2 || got[1] != (Item{2, "item2"}) {
+		t.Fatalf("QueryNamed = %v, %v", got, err)
+	}
+	if _, err := m.QueryNamed(ctx, "missing"); !errors.Is(err, csql.ErrQueryNotFound) {
+		t.Fatalf("QueryNamed of a missing query = %v, want ErrQueryNotFound", err)
+	}
+	if err := csql.NewSQLTableManager[Item](db).ExecNamed(ctx, "insert_item"); !errors.Is(err, csql.ErrQueryNotFound) {
+		t.Fatalf("ExecNamed without a store = %v, want ErrQueryNotFound", err)
+	}
+}
+
+func TestQueryStoreErrors(t *testing.T) {
+	dup := fstest.MapFS{
+		"sql/a/get.sql": {Data: []byte("SELECT 1")},
+		"sql/b/get.sql": {Data: []byte("SELECT 2")},
+	}
+	if _, err := csql.NewQueryStore(dup, "sql"); err == nil || !strings.Contains(err.Error(), `duplicate query "get"`) {
+		t.Fatalf("NewQueryStore = %v, want the duplicate named", err)
+	}
+	if _, err := csql.NewQueryStore(dup, "missing"); err == nil {
+		t.Fatal("NewQueryStore of a missing directory succeeded")
+	}
+}
@@ -0,0 +1,84 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrReadOnly is returned by the writes of a manager configured with WithReadOnly
+var ErrReadOnly = errors.New("csql: manager is read-only")
+
+// WithReadOnly makes Exec, Transaction and its variants, ExecBatch,
+// ExecScript, Prepare, CopyFrom, and the generated writes built on them
+// fail with ErrReadOnly before reaching the database, while reads run
+// normally. The SQL of a read is not inspected, so a write such as a
+// DELETE ... RETURNING run through Query is not caught by the manager;
+// reads run within read-only database transactions instead, in which
+// databases enforcing them, such as Postgres and MySQL, reject writes.
+// Managers from Bind run reads within their Tx as it is
+func WithReadOnly() Option {
+	return func(o *options) error {
+		o.readOnly = true
+		return nil
+	}
+}
+
+// writable returns ErrReadOnly under WithReadOnly
+func (o *options) writable() error {
+	if o.readOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// querier is the part of conn and *sql.Tx a read runs through
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// reader returns what a read runs through and a func ending it, a
+// read-only database transaction under WithReadOnly and a transaction
+// setting the statement timeout under WithStatementTimeout, on a
+// connection acquired under WithAcquireTimeout
+func (m *SQLTableManager[_, _]) reader(ctx context.Context) (querier, func(), error) {
+	if m.group != nil {
+		return m.db, func() {}, nil
+	}
+	c, err := m.acquire(ctx)
+	if err != nil {
+		return nil, func() {}, err
+	}
+	db, release := m.db, func() {}
+	if c != nil {
+		db, release = c, func() { c.Close() }
+	}
+	if !m.opts.readOnly && !m.opts.setsStatementTimeout() {
+		return db, release, nil
+	}
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: m.opts.readOnly})
+	if err != nil {
+		release()
+		return nil, func() {}, err
+	}
+	if err := m.opts.setStatementTimeout(ctx, tx); err != nil {
+		err = rollback(tx, err)
+		release()
+		return nil, func() {}, err
+	}
+	return tx, func() {
+		tx.Rollback()
+		release()
+	}, nil
+}
+
+// read calls fn with what a read runs through, see reader
+func (m *SQLTableManager[_, _]) read(ctx context.Context, fn func(q querier) error) error {
+	q, end, err := m.reader(ctx)
+	if err != nil {
+		return err
+	}
+	defer end()
+	return fn(q)
+}
@@ -0,0 +1,96 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestReadOnly(t *testing.T) {
+	ctx := context.Background()
+	db, rec := openRecorded(t, nil)
+	mustExec(t, db, "CREATE TABLE people (id INTEGER PRIMARY KEY, ssn TEXT)")
+	mustExec(t, db, "INSERT INTO people VALUES (1, 'a')")
+	m := csql.NewSQLTableManager[Person](db, csql.WithTable("people"), csql.WithReadOnly())
+	// COPY is Postgres only
+	pg := csql.NewSQLTableManager[Person](db, csql.WithDialect(csql.Postgres), csql.WithReadOnly())
+	const insert = "INSERT INTO people (id, ssn) VALUES (?, ?)"
+	rows := []Person{{ID: 2, SSN: "b"}}
+	cols := []string{"id", "ssn"}
+	writes := map[string]func() error{
+		"Exec": func() error { return m.Exec("DELETE FROM people") },
+		"Transaction": func() error {
+			_, err := m.Transaction(insert, rows)
+			return err
+		},
+		"TransactionFunc": func() error {
+			_, err := m.TransactionFunc(insert, rows, nil)
+			return err
+		},
+		"TransactionSorted": func() error {
+			_, err := m.TransactionSorted(ctx, insert, rows, func(a, b Person) bool { return a.ID < b.ID })
+			return err
+		},
+		"TransactionFrom": func() error {
+			return m.TransactionFrom(insert, func() (Person, bool, error) { return Person{}, false, nil })
+		},
+		"TransactionIter": func() (err error) {
+			m.TransactionIter(ctx, insert, rows)(func(_ Person, e error) bool { err = e; return true })
+			return err
+		},
+		"TransactionMulti": func() error { return m.TransactionMulti([]csql.TxOp[Person]{{Statement: insert, Rows: rows}}) },
+		"TransactionQuery": func() error {
+			_, _, err := m.TransactionQuery(ctx, "DELETE FROM people RETURNING id, ssn")
+			return err
+		},
+		"ExecBatch":  func() error { return m.ExecBatch(ctx, []csql.Stmt{{SQL: "DELETE FROM people"}}) },
+		"ExecScript": func() error { return m.ExecScript(ctx, "DELETE FROM people;") },
+		"Prepare": func() error {
+			_, err := m.Prepare(ctx, insert)
+			return err
+		},
+		"CopyFrom": func() error {
+			_, err := pg.CopyFrom(ctx, "people", cols, rows)
+			return err
+		},
+		"InsertManyReturning": func() error {
+			_, err := m.InsertManyReturning(ctx, "people", cols, rows, "id")
+			return err
+		},
+		"InsertIgnore": func() error {
+			_, err := m.InsertIgnore(ctx, "people", cols, rows[0])
+			return err
+		},
+		"Update":      func() error { return m.Update(ctx, Person{ID: 1, SSN: "z"}) },
+		"Delete":      func() error { return m.Delete("id = ?", 1) },
+		"ForceDelete": func() error { return m.ForceDelete("id = ?", 1) },
+	}
+	before := len(rec.Stmts())
+	for name, write := range writes {
+		if err := write(); !errors.Is(err, csql.ErrReadOnly) {
+			t.Errorf("%s = %v, want ErrReadOnly", name, err)
+		}
+	}
+	if stmts := rec.Stmts()[before:]; len(stmts) != 0 {
+		t.Fatalf("writes sent %+v, want nothing to reach the database", stmts)
+	}
+
+	if got, err := m.Query("SELECT id, ssn FROM people"); err != nil || len(got) != 1 {
+		t.Fatalf("Query = %v, %v", got, err)
+	}
+	if got, err := m.QueryRow("SELECT id, ssn FROM people"); err != nil || got.ID != 1 {
+		t.Fatalf("QueryRow = %v, %v", got, err)
+	}
+	if ok, err := m.Exists(ctx, "id = ?", 1); err != nil || !ok {
+		t.Fatalf("Exists = %t, %v", ok, err)
+	}
+	if n, err := m.Count(ctx, ""); err != nil || n != 1 {
+		t.Fatalf("Count = %d, %v", n, err)
+	}
+	// reads run within read-only transactions
+	if n := rec.Count("BEGIN"); n == 0 {
+		t.Fatal("reads began no transaction")
+	}
+}
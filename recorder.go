@@ -0,0 +1,155 @@
+package csql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Entry is a single operation captured by a Recorder
+type Entry struct {
+	// Time is when the operation started
+	Time time.Time
+	// Op is the operation name, e.g. OpExec
+	Op string
+	// SQL is the statement passed to the table
+	SQL string
+	// Args holds the arguments, after redaction
+	Args []any
+	// Rows is the number of rows passed to Transaction
+	Rows int
+	// Err is the error the operation returned
+	Err error
+}
+
+// Recorder is a SQLTable that forwards every call to another table and
+// keeps the most recent operations in a fixed-size ring. It is safe for
+// concurrent use
+type Recorder[T any, R Schema[T]] struct {
+	next   SQLTable[T, R]
+	redact func([]any) []any
+
+	mu      sync.Mutex
+	entries []Entry
+	head    int
+	full    bool
+}
+
+var _ SQLTable[nopSchema, *nopSchema] = (*Recorder[nopSchema, *nopSchema])(nil)
+
+// NewRecorder returns a Recorder wrapping next that keeps up to capacity
+// entries. redact, when not nil, rewrites the args of each entry before
+// it is stored, e.g. to mask personal data; the call itself gets the
+// original args. It panics if capacity is not positive
+func NewRecorder[T any, R Schema[T]](next SQLTable[T, R], capacity int, redact func([]any) []any) *Recorder[T, R] {
+	if capacity <= 0 {
+		panic(fmt.Sprintf("csql: recorder capacity must be positive, got %d", capacity))
+	}
+	return &Recorder[T, R]{
+		next:    next,
+		redact:  redact,
+		entries: make([]Entry, capacity),
+	}
+}
+
+// Entries returns the recorded entries, oldest first
+func (r *Recorder[T, R]) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]Entry(nil), r.entries[:r.head]...)
+	}
+	return append(append([]Entry(nil), r.entries[r.head:]...), r.entries[:r.head]...)
+}
+
+// Reset discards all recorded entries
+func (r *Recorder[T, R]) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.entries {
+		r.entries[i] = Entry{}
+	}
+	r.head, r.full = 0, false
+}
+
+func (r *Recorder[T, R]) record(e Entry) {
+	if len(e.Args) > 0 {
+		e.Args = append([]any(nil), e.Args...)
+		if r.redact != nil {
+			e.Args = r.redact(e.Args)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.head] = e
+	r.head++
+	if r.head == len(r.entries) {
+		r.head, r.full = 0, true
+	}
+}
+
+func (r *Recorder[T, R]) Query(query string, args ...any) ([]T, error) {
+	return r.QueryContext(context.Background(), query, args...)
+}
+
+func (r *Recorder[T, R]) QueryContext(ctx context.Context, query string, args ...any) ([]T, error) {
+	start := time.Now()
+	rows, err := r.next.QueryContext(ctx, query, args...)
+	r.record(Entry{Time: start, Op: OpQuery, SQL: query, Args: args, Err: err})
+	return rows, err
+}
+
+func (r *Recorder[T, R]) QueryRow(query string, args ...any) (T, error) {
+	return r.QueryRowContext(context.Background(), query, args...)
+}
+
+func (r *Recorder[T, R]) QueryRowContext(ctx context.Context, query string, args ...any) (T, error) {
+	start := time.Now()
+	row, err := r.next.QueryRowContext(ctx, query, args...)
+	r.record(Entry{Time: start, Op: OpQueryRow, SQL: query, Args: args, Err: err})
+	return row, err
+}
+
+func (r *Recorder[T, R]) Exec(query string, args ...any) error {
+	return r.ExecContext(context.Background(), query, args...)
+}
+
+func (r *Recorder[T, R]) ExecContext(ctx context.Context, query string, args ...any) error {
+	start := time.Now()
+	err := r.next.ExecContext(ctx, query, args...)
+	r.record(Entry{Time: start, Op: OpExec, SQL: query, Args: args, Err: err})
+	return err
+}
+
+func (r *Recorder[T, R]) Transaction(transaction string, rows []T) (bool, error) {
+	return r.TransactionContext(context.Background(), transaction, rows)
+}
+
+func (r *Recorder[T, R]) TransactionContext(ctx context.Context, transaction string, rows []T) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.TransactionContext(ctx, transaction, rows)
+	r.record(Entry{Time: start, Op: OpTransaction, SQL: transaction, Rows: len(rows), Err: err})
+	return ok, err
+}
+
+// Replay re-executes the successful Exec entries against table, in order,
+// stopping at the first failure. Redacted args are replayed as redacted
+func Replay[T any, R Schema[T]](ctx context.Context, table SQLTable[T, R], entries []Entry) error {
+	for i, e := range entries {
+		if e.Op != OpExec || e.Err != nil {
+			continue
+		}
+		if err := table.ExecContext(ctx, e.SQL, e.Args...); err != nil {
+			return fmt.Errorf("csql: replay entry %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// nopSchema instantiates interface assertions
+type nopSchema struct{}
+
+func (*nopSchema) ScanRow(RowScanner) error { return nil }
+
+func (*nopSchema) Fields() []any { return nil }
@@ -0,0 +1,113 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces the values of redacted columns
+const Redacted = "[redacted]"
+
+// Redactor rewrites value, bound to column, before the manager shows it
+// outside the driver. column is the name of a sql.NamedArg, the column a
+// positional argument binds to in the statements the manager builds, such
+// as those of Update, the inserts and upserts, QueryBy, and the rows of
+// Transaction, or "" for the other positional arguments, as of Exec
+type Redactor func(column string, value any) any
+
+// RedactedColumner is implemented by Schemas naming the columns whose
+// values are never shown, as an alternative to the csql tag option redact
+type RedactedColumner interface {
+	RedactedColumns() []string
+}
+
+// WithRedactor passes bound arguments through fn wherever the manager
+// externalizes them: QueryInfo.Args under WithLogArgs, Hook and ExecHook
+// arguments, and RowError.Args. Arguments bound to the Schema's redacted
+// columns are shown as Redacted regardless. The driver always receives
+// the original values
+func WithRedactor(fn Redactor) Option {
+	return func(o *options) error {
+		o.redactor = fn
+		return nil
+	}
+}
+
+// redactedColumns returns the lowercased columns the Schema redacts, from
+// RedactedColumns when implemented and its csql tags otherwise
+func redactedColumns[T any, R Schema[T]]() map[string]bool {
+	var names []string
+	if r, ok := any(R(new(T))).(RedactedColumner); ok {
+		names = r.RedactedColumns()
+	} else if t := reflect.TypeOf((*T)(nil)).Elem(); t.Kind() == reflect.Struct {
+		for _, f := range planOf(t) {
+			if f.redact {
+				names = append(names, f.name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	columns := make(map[string]bool, len(names))
+	for _, name := range names {
+		columns[strings.ToLower(name)] = true
+	}
+	return columns
+}
+
+// argColumnsKey is the context key of the columns set by withArgColumns
+type argColumnsKey struct{}
+
+// withArgColumns returns ctx naming the columns the positional args of the
+// statement it runs bind to, in order, for their redaction
+func withArgColumns(ctx context.Context, columns []string) context.Context {
+	return context.WithValue(ctx, argColumnsKey{}, columns)
+}
+
+// redactArgs returns the args of the statement run with ctx as they may be
+// shown. Columns set by withArgColumns for a different number of args, as
+// by an outer statement, are ignored
+func (o *options) redactArgs(ctx context.Context, args []any) []any {
+	columns, _ := ctx.Value(argColumnsKey{}).([]string)
+	if len(columns) != len(args) {
+		columns = nil
+	}
+	return o.redactBound(columns, args)
+}
+
+// redactBound returns args, bound in order to columns when not nil, as
+// they may be shown, a copy when any is rewritten. NamedArg values are
+// redacted in place of the whole argument
+func (o *options) redactBound(columns []string, args []any) []any {
+	if o.redactor == nil && o.redactColumns == nil || len(args) == 0 {
+		return args
+	}
+	shown := make([]any, len(args))
+	for i, arg := range args {
+		if named, ok := arg.(sql.NamedArg); ok {
+			named.Value = o.redactValue(named.Name, named.Value)
+			shown[i] = named
+			continue
+		}
+		column := ""
+		if i < len(columns) {
+			column = columns[i]
+		}
+		shown[i] = o.redactValue(column, arg)
+	}
+	return shown
+}
+
+// redactValue returns value, bound to column, as it may be shown
+func (o *options) redactValue(column string, value any) any {
+	if o.redactColumns[strings.ToLower(column)] {
+		return Redacted
+	}
+	if o.redactor != nil {
+		return o.redactor(column, value)
+	}
+	return value
+}
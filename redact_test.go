@@ -0,0 +1,119 @@
+package csql_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// Person is a Schema with a redacted column
+type Person struct {
+	ID  int64
+	SSN string `csql:"ssn,redact"`
+}
+
+func (p *Person) ScanRow(s csql.RowScanner) error { return s.Scan(&p.ID, &p.SSN) }
+
+func (p *Person) Fields() []any { return []any{p.ID, p.SSN} }
+
+func (p *Person) KeyColumn() string { return "id" }
+
+const ssn = "123-45-6789"
+
+// openPeople returns openDB with an empty people (id, ssn) table
+func openPeople(t *testing.T) *sql.DB {
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE people (id INTEGER PRIMARY KEY, ssn TEXT)")
+	return db
+}
+
+// storedSSN returns the ssn the database holds for the person id
+func storedSSN(t *testing.T, db *sql.DB, id int64) string {
+	t.Helper()
+	var got string
+	if err := db.QueryRow("SELECT ssn FROM people WHERE id = ?", id).Scan(&got); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestRedactRowError(t *testing.T) {
+	db := openPeople(t)
+	m := csql.NewSQLTableManager[Person](db, csql.WithErrorIncludeQuery(true))
+	insert := "INSERT INTO people (id, ssn) VALUES (?, ?)"
+	if _, err := m.Transaction(insert, []Person{{ID: 1, SSN: ssn}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedSSN(t, db, 1); got != ssn {
+		t.Fatalf("stored %q, want the real value %q", got, ssn)
+	}
+	_, err := m.Transaction(insert, []Person{{ID: 2, SSN: ssn}, {ID: 1, SSN: ssn}})
+	var re *csql.RowError
+	if !errors.As(err, &re) || re.Row != 1 {
+		t.Fatalf("Transaction = %v, want a *RowError for row 1", err)
+	}
+	if want := fmt.Sprint([]any{int64(1), csql.Redacted}); fmt.Sprint(re.Args) != want {
+		t.Fatalf("RowError.Args = %v, want %v", re.Args, want)
+	}
+	if msg := err.Error(); strings.Contains(msg, ssn) || !strings.Contains(msg, csql.Redacted) {
+		t.Fatalf("error %q shows the ssn", msg)
+	}
+}
+
+func TestRedactRowErrorArgsOptIn(t *testing.T) {
+	m := csql.NewSQLTableManager[Person](openPeople(t))
+	insert := "INSERT INTO people (id, ssn) VALUES (?, ?)"
+	_, err := m.Transaction(insert, []Person{{ID: 1, SSN: ssn}, {ID: 1, SSN: ssn}})
+	var re *csql.RowError
+	if !errors.As(err, &re) || re.Args != nil {
+		t.Fatalf("Transaction = %v, want a *RowError without args", err)
+	}
+}
+
+// argLogger records the args of every logged operation
+type argLogger struct {
+	mu   sync.Mutex
+	args [][]any
+}
+
+func (l *argLogger) LogQuery(_ context.Context, info csql.QueryInfo) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.args = append(l.args, info.Args)
+}
+
+func TestRedactLogger(t *testing.T) {
+	ctx := context.Background()
+	db := openPeople(t)
+	mustExec(t, db, "INSERT INTO people (id, ssn) VALUES (1, 'unset')")
+	var l argLogger
+	var columns []string
+	m := csql.NewSQLTableManager[Person](db, csql.WithTable("people"), csql.WithLogger(&l), csql.WithLogArgs(),
+		csql.WithRedactor(func(column string, value any) any {
+			columns = append(columns, column)
+			return value
+		}))
+	if err := m.Update(ctx, Person{ID: 1, SSN: ssn}); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedSSN(t, db, 1); got != ssn {
+		t.Fatalf("stored %q, want the real value %q", got, ssn)
+	}
+	rows, err := m.QueryBy(ctx, Person{SSN: ssn})
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("QueryBy = %v, %v, want the row matched by its real ssn", rows, err)
+	}
+	want := fmt.Sprint([][]any{{csql.Redacted, int64(1)}, {csql.Redacted}})
+	if got := fmt.Sprint(l.args); got != want {
+		t.Fatalf("logged args %v, want %v", got, want)
+	}
+	if got := strings.Join(columns, ","); !strings.EqualFold(got, "id") {
+		t.Fatalf("redactor saw columns %q, want the unredacted id alone", got)
+	}
+}
@@ -0,0 +1,258 @@
+package csql
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// field locates a column within a struct
+type field struct {
+	index []int
+	name  string
+	// redact is set by the tag option redact, see RedactedColumner
+	redact bool
+}
+
+var plans sync.Map // reflect.Type -> []field
+
+// ReflectFields returns the exported field values of the struct pointed to
+// by v in declaration order, flattening embedded structs. Fields of a nil
+// embedded pointer are returned as zero values. It suits Schema.Fields
+func ReflectFields(v any) []any {
+	rv := structValue(v, "ReflectFields")
+	plan := planOf(rv.Type())
+	fields := make([]any, len(plan))
+	for i, f := range plan {
+		fv, err := rv.FieldByIndexErr(f.index)
+		if err != nil {
+			fv = reflect.Zero(rv.Type().FieldByIndex(f.index).Type)
+		}
+		fields[i] = fv.Interface()
+	}
+	return fields
+}
+
+// ScanInto scans a row into the exported fields of the struct pointed to
+// by v in declaration order, flattening embedded structs and allocating
+// nil embedded pointers. Fields of named string, bool, and numeric types,
+// such as type Status string, are scanned as their underlying type unless
+// a codec is registered for them with WithTypeCodec. It suits
+// Schema.ScanRow. Like ReflectFields, it panics on an embedded struct
+// cycle or two fields mapping to one column, which NewSQLTableManager
+// reports on construction
+func ScanInto(r RowScanner, v any) error {
+	rv := structValue(v, "ScanInto")
+	plan := planOf(rv.Type())
+	dest := make([]any, len(plan))
+	// named holds the fields scanned through a temporary of their basic type
+	var named []reflect.Value
+	for i, f := range plan {
+		fv := allocField(rv, f.index)
+		if basic := basicType(fv.Type()); basic != nil && !scansWithCodec(r, fv.Type()) {
+			tmp := reflect.New(basic)
+			dest[i] = tmp.Interface()
+			named = append(named, fv, tmp.Elem())
+			continue
+		}
+		dest[i] = fv.Addr().Interface()
+	}
+	if err := r.Scan(dest...); err != nil {
+		return err
+	}
+	for i := 0; i < len(named); i += 2 {
+		named[i].Set(named[i+1].Convert(named[i].Type()))
+	}
+	return nil
+}
+
+// basicTypes maps the kinds of named types ScanInto scans as their
+// underlying type to that type
+var basicTypes = map[reflect.Kind]reflect.Type{
+	reflect.String:  reflect.TypeOf(""),
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+}
+
+// basicType returns the underlying type ScanInto scans a field of type t
+// as, or nil when t is scanned as it is
+func basicType(t reflect.Type) reflect.Type {
+	basic := basicTypes[t.Kind()]
+	if basic == nil || t == basic || reflect.PointerTo(t).Implements(scannerType) {
+		return nil
+	}
+	return basic
+}
+
+// allocField is FieldByIndex, allocating nil embedded pointers on the way
+func allocField(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+func structValue(v any, fn string) reflect.Value {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		panic("csql: " + fn + " requires a pointer to a struct, got " + rv.Type().String())
+	}
+	return rv.Elem()
+}
+
+// planOf returns the cached plan of t, panicking if t cannot be planned
+func planOf(t reflect.Type) []field {
+	if plan, ok := plans.Load(t); ok {
+		return plan.([]field)
+	}
+	plan, err := newPlan(t)
+	if err != nil {
+		panic(err)
+	}
+	cached, _ := plans.LoadOrStore(t, plan)
+	return cached.([]field)
+}
+
+// checkSchema reports the error planOf would panic with for the Schema R,
+// so a manager rejects it on construction rather than on its first read.
+// Schemas implementing Columner name their columns and are not planned
+func checkSchema[T any, R Schema[T]]() error {
+	if _, ok := any(R(new(T))).(Columner); ok {
+		return nil
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if _, ok := plans.Load(t); ok {
+		return nil
+	}
+	_, err := newPlan(t)
+	return err
+}
+
+// newPlan builds the plan of t, rejecting two fields mapping to one column
+func newPlan(t reflect.Type) ([]field, error) {
+	plan, err := buildPlan(t, nil, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]field, len(plan))
+	for _, f := range plan {
+		key := strings.ToLower(f.name)
+		if prev, ok := seen[key]; ok {
+			return nil, fmt.Errorf("csql: fields %s and %s of %s both map to column %q", fieldPath(t, prev.index), fieldPath(t, f.index), t, f.name)
+		}
+		seen[key] = f
+	}
+	return plan, nil
+}
+
+// fieldPath names the field of t at index as a selector, e.g. Base.ID
+func fieldPath(t reflect.Type, index []int) string {
+	names := make([]string, len(index))
+	for i, x := range index {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		sf := t.Field(x)
+		names[i] = sf.Name
+		t = sf.Type
+	}
+	return strings.Join(names, ".")
+}
+
+// buildPlan lists the columns of t, descending into embedded structs and
+// pointers to structs. A column is named by its csql tag, or the field name
+// otherwise, and the tag "-" skips a field or a whole embedded struct.
+// The tag option redact, as in "ssn,redact", marks the column redacted.
+// An embedded struct tagged "prefix:p" prefixes its column names with p.
+// It fails on an embedding cycle, which would otherwise never end
+func buildPlan(t reflect.Type, index []int, prefix string, path []reflect.Type) (plan []field, err error) {
+	for _, seen := range path {
+		if seen == t {
+			return nil, fmt.Errorf("csql: embedded struct cycle through %s", t)
+		}
+	}
+	path = append(path, t)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("csql")
+		if tag == "-" {
+			continue
+		}
+		at := append(index[:len(index):len(index)], i)
+		if sf.Anonymous {
+			if et := embedded(sf); et != nil {
+				inner, _ := strings.CutPrefix(tag, "prefix:")
+				if inner == tag {
+					inner = ""
+				}
+				fields, err := buildPlan(et, at, prefix+inner, path)
+				if err != nil {
+					return nil, err
+				}
+				plan = append(plan, fields...)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		plan = append(plan, field{index: at, name: prefix + name, redact: slices.Contains(strings.Split(opts, ","), "redact")})
+	}
+	return plan, nil
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// flatten reports whether an embedded field of type t contributes its own
+// fields rather than being a single column
+func flatten(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PointerTo(t).Implements(scannerType)
+}
+
+// embedded returns the struct type whose fields the embedded field sf
+// contributes, or nil if sf is a single column. Exported pointers are
+// looked through, since ScanInto must be able to allocate them
+func embedded(sf reflect.StructField) reflect.Type {
+	t := sf.Type
+	if t.Kind() == reflect.Pointer {
+		if !sf.IsExported() {
+			return nil
+		}
+		t = t.Elem()
+	}
+	if flatten(t) {
+		return t
+	}
+	return nil
+}
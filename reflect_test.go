@@ -0,0 +1,169 @@
+package csql_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// Node embeds itself, so its columns never end
+type Node struct {
+	ID int64
+	*Node
+}
+
+func (n *Node) ScanRow(s csql.RowScanner) error { return csql.ScanInto(s, n) }
+
+func (n *Node) Fields() []any { return csql.ReflectFields(n) }
+
+type Audited struct {
+	ID      int64
+	Updated string
+}
+
+// Shadowed promotes Audited.ID onto its own ID column
+type Shadowed struct {
+	ID   int64
+	Name string
+	Audited
+}
+
+func (s *Shadowed) ScanRow(r csql.RowScanner) error { return csql.ScanInto(r, s) }
+
+func (s *Shadowed) Fields() []any { return csql.ReflectFields(s) }
+
+// Prefixed maps Audited under distinct column names
+type Prefixed struct {
+	ID      int64
+	Audited `csql:"prefix:audit_"`
+}
+
+func (p *Prefixed) ScanRow(r csql.RowScanner) error { return csql.ScanInto(r, p) }
+
+func (p *Prefixed) Fields() []any { return csql.ReflectFields(p) }
+
+// constructPanic returns what constructing a manager panics with
+func constructPanic(fn func()) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg = fmt.Sprint(r)
+		}
+	}()
+	fn()
+	return ""
+}
+
+func TestReflectedSchemaChecked(t *testing.T) {
+	db := openDB(t)
+	tests := []struct {
+		name string
+		new  func()
+		want string
+	}{
+		{"cycle", func() { csql.NewSQLTableManager[Node](db) }, "embedded struct cycle through csql_test.Node"},
+		{"duplicate", func() { csql.NewSQLTableManager[Shadowed](db) }, `fields ID and Audited.ID of csql_test.Shadowed both map to column "ID"`},
+		{"prefixed", func() { csql.NewSQLTableManager[Prefixed](db) }, ""},
+	}
+	for _, tt := range tests {
+		got := constructPanic(tt.new)
+		if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+			t.Errorf("%s: NewSQLTableManager panicked with %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if _, err := csql.NewSQLTableManagerWithRetry[Node](context.Background(), db, 1, 0); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("NewSQLTableManagerWithRetry = %v, want the cycle reported", err)
+	}
+}
+
+type Stamps struct {
+	CreatedAt string
+	UpdatedAt string
+}
+
+type BaseModel struct {
+	ID int64
+	Stamps
+}
+
+// Hidden is embedded in Article under the tag "-"
+type Hidden struct {
+	Secret string
+}
+
+// Article embeds its columns two levels deep
+type Article struct {
+	BaseModel
+	Title  string
+	Hidden `csql:"-"`
+}
+
+func (a *Article) ScanRow(r csql.RowScanner) error { return csql.ScanInto(r, a) }
+
+func (a *Article) Fields() []any { return csql.ReflectFields(a) }
+
+func TestReflectEmbedded(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE articles (ID INTEGER, CreatedAt TEXT, UpdatedAt TEXT, Title TEXT)")
+	m := csql.NewSQLTableManager[Article](db, csql.WithTable("articles"))
+	if got, want := m.SelectColumns(), "ID, CreatedAt, UpdatedAt, Title"; got != want {
+		t.Fatalf("SelectColumns = %q, want %q", got, want)
+	}
+	a := Article{BaseModel: BaseModel{ID: 1, Stamps: Stamps{"mon", "tue"}}, Title: "hello", Hidden: Hidden{"x"}}
+	if got := fmt.Sprint(csql.ReflectFields(&a)); got != "[1 mon tue hello]" {
+		t.Fatalf("ReflectFields = %s", got)
+	}
+	if _, err := m.Transaction("INSERT INTO articles VALUES (?, ?, ?, ?)", []Article{a}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.QueryProjected(context.Background(), "")
+	a.Hidden = Hidden{}
+	if err != nil || len(got) != 1 || got[0] != a {
+		t.Fatalf("QueryProjected = %+v, %v, want %+v", got, err, a)
+	}
+}
+
+// Status is an enum stored as text
+type Status string
+
+// Priority is an enum stored as an integer
+type Priority int
+
+// Ticket is a reflected Schema of named basic types
+type Ticket struct {
+	ID       int64
+	Status   Status
+	Priority Priority
+	Weight   float32
+	Urgent   Flag
+}
+
+// Flag is a named bool
+type Flag bool
+
+func (k *Ticket) ScanRow(r csql.RowScanner) error { return csql.ScanInto(r, k) }
+
+func (k *Ticket) Fields() []any { return csql.ReflectFields(k) }
+
+func TestReflectNamedTypes(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE tickets (ID INTEGER, Status TEXT, Priority INTEGER, Weight REAL, Urgent BOOLEAN)")
+	m := csql.NewSQLTableManager[Ticket](db, csql.WithTable("tickets"))
+	want := Ticket{1, "open", 3, 0.5, true}
+	if _, err := m.Transaction("INSERT INTO tickets VALUES (?, ?, ?, ?, ?)", []Ticket{want}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := m.SelectRow("ID = ?", 1); err != nil || got != want {
+		t.Fatalf("SelectRow = %+v, %v, want %+v", got, err, want)
+	}
+	mustExec(t, db, "INSERT INTO tickets VALUES (2, NULL, NULL, NULL, NULL)")
+	if _, err := m.SelectRow("ID = ?", 2); err == nil {
+		t.Fatal("SelectRow of NULLs into named types succeeded")
+	}
+	zero := csql.NewSQLTableManager[Ticket](db, csql.WithTable("tickets"), csql.WithNullAsZero())
+	if got, err := zero.SelectRow("ID = ?", 2); err != nil || got != (Ticket{ID: 2}) {
+		t.Fatalf("SelectRow with WithNullAsZero = %+v, %v, want zero named fields", got, err)
+	}
+}
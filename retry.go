@@ -0,0 +1,147 @@
+package csql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy decides whether a failed operation should be attempted again
+type RetryPolicy interface {
+	// Retry is called after attempt (starting at 1) fails with err and
+	// returns how long to wait before the next attempt, if any
+	Retry(attempt int, err error) (wait time.Duration, retry bool)
+}
+
+// Backoff is a RetryPolicy retrying with exponential backoff
+type Backoff struct {
+	// Attempts is the maximum number of attempts, including the first
+	Attempts int
+	// Base is the wait before the first retry, doubled for each one after
+	Base time.Duration
+	// Max caps the wait between attempts when positive
+	Max time.Duration
+	// Retryable classifies errors, IsTransient by default
+	Retryable func(error) bool
+}
+
+// Retry implements RetryPolicy
+func (b Backoff) Retry(attempt int, err error) (time.Duration, bool) {
+	retryable := b.Retryable
+	if retryable == nil {
+		retryable = IsTransient
+	}
+	if attempt >= b.Attempts || !retryable(err) {
+		return 0, false
+	}
+	wait := b.Base
+	// doubling stops short of overflowing into a negative wait
+	for i := 1; i < attempt && wait <= math.MaxInt64/2; i++ {
+		wait *= 2
+	}
+	if b.Max > 0 && wait > b.Max {
+		wait = b.Max
+	}
+	return wait, true
+}
+
+// IsTransient reports whether err looks like a lost or refused connection
+// that database/sql did not already retry, such as during a failover
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// WithRetry retries Query and QueryRow statements failing per policy.
+// Exec is retried only with WithExecRetry and Transaction never is
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) error {
+		if policy == nil {
+			return fmt.Errorf("csql: retry policy must not be nil")
+		}
+		o.retryPolicy = policy
+		return nil
+	}
+}
+
+// WithSQLiteBusyRetry retries Exec and Transaction up to maxAttempts times
+// in all while SQLite reports the database busy or locked, as IsBusy
+// tells. Waits start at a millisecond and double up to maxWait. A failed
+// Transaction is retried from the start of a new database transaction,
+// since SQLite has already rolled it back
+func WithSQLiteBusyRetry(maxAttempts int, maxWait time.Duration) Option {
+	return func(o *options) error {
+		if maxAttempts <= 0 {
+			return fmt.Errorf("csql: busy retry attempts must be positive, got %d", maxAttempts)
+		}
+		if maxWait <= 0 {
+			return fmt.Errorf("csql: busy retry wait must be positive, got %v", maxWait)
+		}
+		o.busyRetry = Backoff{Attempts: maxAttempts, Base: time.Millisecond, Max: maxWait, Retryable: IsBusy}
+		return nil
+	}
+}
+
+// WithExecRetry extends WithRetry to Exec, for callers whose statements are idempotent
+func WithExecRetry() Option {
+	return func(o *options) error {
+		o.retryExec = true
+		return nil
+	}
+}
+
+// retry runs fn until it succeeds or the policy gives up. Waits end early
+// when ctx is done and are skipped when they would outlast its deadline
+func (o *options) retry(ctx context.Context, write bool, fn func() error) error {
+	if o.retryPolicy == nil || (write && !o.retryExec) {
+		return fn()
+	}
+	return retryWith(ctx, o.retryPolicy, fn)
+}
+
+// retryBusy retries fn per WithSQLiteBusyRetry, if configured
+func (o *options) retryBusy(ctx context.Context, fn func() error) error {
+	if o.busyRetry == nil {
+		return fn()
+	}
+	return retryWith(ctx, o.busyRetry, fn)
+}
+
+func retryWith(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		wait, ok := policy.Retry(attempt, err)
+		if !ok || ctx.Err() != nil {
+			return err
+		}
+		if dl, ok := ctx.Deadline(); ok && time.Until(dl) < wait {
+			return err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
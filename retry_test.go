@@ -0,0 +1,151 @@
+package csql_test
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+func TestBackoff(t *testing.T) {
+	b := csql.Backoff{Attempts: 5, Base: time.Millisecond, Max: 5 * time.Millisecond}
+	for attempt, want := range map[int]time.Duration{1: time.Millisecond, 2: 2 * time.Millisecond, 3: 4 * time.Millisecond, 4: 5 * time.Millisecond} {
+		if wait, ok := b.Retry(attempt, io.ErrUnexpectedEOF); !ok || wait != want {
+			t.Errorf("Retry(%d) = %v, %t, want %v", attempt, wait, ok, want)
+		}
+	}
+	if _, ok := b.Retry(5, io.ErrUnexpectedEOF); ok {
+		t.Error("Retry of the last attempt = true")
+	}
+	if _, ok := b.Retry(1, errors.New("syntax error")); ok {
+		t.Error("Retry of a permanent error = true")
+	}
+}
+
+func TestBackoffUncapped(t *testing.T) {
+	b := csql.Backoff{Attempts: 1000, Base: time.Second}
+	last := time.Duration(0)
+	for attempt := 1; attempt < b.Attempts; attempt++ {
+		wait, ok := b.Retry(attempt, io.ErrUnexpectedEOF)
+		if !ok || wait < last {
+			t.Fatalf("Retry(%d) = %v, %t after %v, want waits that never shrink", attempt, wait, ok, last)
+		}
+		last = wait
+	}
+	if last < time.Duration(1<<62) {
+		t.Fatalf("last wait %v, want it held near the longest Duration", last)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	retry := csql.WithRetry(csql.Backoff{Attempts: 3})
+	tests := []struct {
+		name     string
+		opts     []csql.Option
+		run      func(m *csql.SQLTableManager[Item, *Item]) error
+		prefix   string
+		failures int
+		attempts int
+		wantErr  bool
+	}{
+		{"query", []csql.Option{retry}, func(m *csql.SQLTableManager[Item, *Item]) error {
+			_, err := m.Query(selectItems)
+			return err
+		}, "SELECT", 2, 3, false},
+		{"query gives up", []csql.Option{retry}, func(m *csql.SQLTableManager[Item, *Item]) error {
+			_, err := m.Query(selectItems)
+			return err
+		}, "SELECT", 3, 3, true},
+		{"exec", []csql.Option{retry}, func(m *csql.SQLTableManager[Item, *Item]) error {
+			return m.Exec("DELETE FROM items")
+		}, "DELETE", 1, 1, true},
+		{"exec retry", []csql.Option{retry, csql.WithExecRetry()}, func(m *csql.SQLTableManager[Item, *Item]) error {
+			return m.Exec("DELETE FROM items")
+		}, "DELETE", 1, 2, false},
+		{"transaction", []csql.Option{retry, csql.WithExecRetry()}, func(m *csql.SQLTableManager[Item, *Item]) error {
+			_, err := m.Transaction(insertItem, items(1))
+			return err
+		}, "PREPARE INSERT", 1, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := openRecorded(t, nil)
+			rec.fail = failFirst(tt.prefix, tt.failures, io.ErrUnexpectedEOF)
+			m := csql.NewSQLTableManager[Item](db, tt.opts...)
+			if err := tt.run(m); (err != nil) != tt.wantErr || tt.wantErr && !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Fatalf("err = %v, want error %t", err, tt.wantErr)
+			}
+			if n := rec.Count(tt.prefix); n != tt.attempts {
+				t.Fatalf("%d attempts, want %d", n, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestExecRetryRequiresRetry(t *testing.T) {
+	if got := constructPanic(func() { csql.NewSQLTableManager[Item](openDB(t), csql.WithExecRetry()) }); got == "" {
+		t.Fatal("WithExecRetry without WithRetry did not panic")
+	}
+}
+
+// lockItems opens a second connection to the sqlite file dsn and holds its
+// write lock until the returned release is called
+func lockItems(t *testing.T, dsn string) (release func()) {
+	t.Helper()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("INSERT INTO items (id, name) SELECT COALESCE(MAX(id), 0) + 1000, 'locker' FROM items"); err != nil {
+		t.Fatal(err)
+	}
+	return func() { tx.Commit() }
+}
+
+func TestSQLiteBusyRetry(t *testing.T) {
+	dsn := "file:" + filepath.Join(t.TempDir(), "busy.db")
+	rec := &recorder{}
+	db := sql.OpenDB(recConnector{dsn: dsn, rec: rec})
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	mustExec(t, db, "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)")
+
+	release := lockItems(t, dsn)
+	if err := csql.NewSQLTableManager[Item](db).Exec(insertItem, 1, "item1"); !csql.IsBusy(err) {
+		t.Fatalf("Exec against the lock = %v, want it busy without retries", err)
+	}
+	m := csql.NewSQLTableManager[Item](db, csql.WithSQLiteBusyRetry(100, 10*time.Millisecond))
+	time.AfterFunc(50*time.Millisecond, release)
+	if err := m.Exec(insertItem, 1, "item1"); err != nil {
+		t.Fatalf("Exec retried past the lock = %v", err)
+	}
+
+	release = lockItems(t, dsn)
+	time.AfterFunc(50*time.Millisecond, release)
+	begins := rec.Count("BEGIN")
+	if ok, err := m.Transaction(insertItem, []Item{{2, "item2"}, {3, "item3"}}); !ok || err != nil {
+		t.Fatalf("Transaction retried past the lock = %t, %v", ok, err)
+	}
+	if n := rec.Count("BEGIN") - begins; n < 2 {
+		t.Fatalf("Transaction began %d times, want it retried from a new transaction", n)
+	}
+	if n := countItems(t, m); n != 5 {
+		t.Fatalf("%d rows, want the three inserts and both lockers'", n)
+	}
+
+	release = lockItems(t, dsn)
+	defer release()
+	short := csql.NewSQLTableManager[Item](db, csql.WithSQLiteBusyRetry(3, time.Millisecond))
+	if err := short.Exec(insertItem, 9, "item9"); !csql.IsBusy(err) {
+		t.Fatalf("Exec out of retries = %v, want it busy", err)
+	}
+}
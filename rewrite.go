@@ -0,0 +1,39 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// WithQueryRewriter passes every statement through fn just before it is sent
+// to the database, after placeholders are rewritten for the dialect and
+// the WithStatementTimeout hint is added, so fn sees the final SQL. It
+// suits tagging queries with comments or hints
+func WithQueryRewriter(fn func(ctx context.Context, query string) string) Option {
+	return func(o *options) error {
+		o.rewriter = fn
+		return nil
+	}
+}
+
+// finalize turns query into the SQL sent to the database. Placeholders are
+// left alone when args holds a sql.NamedArg, which the driver binds itself
+func (o *options) finalize(ctx context.Context, query string, args []any) string {
+	if !hasNamed(args) {
+		query = o.dialect.rebind(query)
+	}
+	query = o.hintStatementTimeout(query)
+	if o.rewriter != nil {
+		query = o.rewriter(ctx, query)
+	}
+	return query
+}
+
+func hasNamed(args []any) bool {
+	for _, arg := range args {
+		if _, ok := arg.(sql.NamedArg); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package csql_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+type serviceKey struct{}
+
+func TestQueryRewriter(t *testing.T) {
+	// the tagged statements are stubbed, since sqlite keeps Postgres placeholders apart
+	db, rec := openRecorded(t, func(_ context.Context, query string) bool {
+		return strings.Contains(query, "/* service:")
+	})
+	var seen []string
+	m := csql.NewSQLTableManager[Item](db, csql.WithDialect(csql.Postgres),
+		csql.WithQueryRewriter(func(ctx context.Context, query string) string {
+			seen = append(seen, query)
+			service, _ := ctx.Value(serviceKey{}).(string)
+			return "/* service:" + service + " */ " + query
+		}))
+	ctx := context.WithValue(context.Background(), serviceKey{}, "orders")
+	if _, err := m.QueryContext(ctx, "SELECT id, name FROM items WHERE id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ExecContext(ctx, "DELETE FROM items WHERE id = ? OR id = ?", 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.TransactionContext(ctx, insertItem, items(1)); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"SELECT id, name FROM items WHERE id = $1",
+		"DELETE FROM items WHERE id = $1 OR id = $2",
+		"INSERT INTO items (id, name) VALUES ($1, $2)",
+	}
+	if !slices.Equal(seen, want) {
+		t.Fatalf("rewriter saw %q, want the rebound SQL %q", seen, want)
+	}
+	var sent []string
+	for _, s := range statementsAfterSetup(rec) {
+		if s.SQL != "BEGIN" && s.SQL != "COMMIT" {
+			sent = append(sent, s.SQL)
+		}
+	}
+	if want := []string{
+		"/* service:orders */ " + want[0],
+		"/* service:orders */ " + want[1],
+		"PREPARE /* service:orders */ " + want[2],
+		"/* service:orders */ " + want[2],
+	}; !slices.Equal(sent, want) {
+		t.Fatalf("driver received %q, want %q", sent, want)
+	}
+}
@@ -0,0 +1,220 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is returned when a lookup matches nothing.
+// It matches sql.ErrNoRows under errors.Is
+var ErrNotFound error = notFoundError{}
+
+type notFoundError struct{}
+
+func (notFoundError) Error() string { return "csql: not found" }
+
+func (notFoundError) Is(target error) bool { return target == sql.ErrNoRows }
+
+// Aggregate returns the value of the aggregate expr, such as AVG(price),
+// over the table rows matching where. It returns the zero V and ErrNotFound
+// when the aggregate is NULL, as for SUM over no rows
+func Aggregate[V any, T any, R Schema[T]](ctx context.Context, m *SQLTableManager[T, R], expr, where string, args ...any) (V, error) {
+	v, present, err := AggregateOpt[V](ctx, m, expr, where, args...)
+	if err == nil && !present {
+		err = ErrNotFound
+	}
+	return v, err
+}
+
+// AggregateOpt is Aggregate, reporting present false rather than an error
+// when the aggregate is NULL, so a SUM of zero is told apart from a SUM
+// over no rows
+func AggregateOpt[V any, T any, R Schema[T]](ctx context.Context, m *SQLTableManager[T, R], expr, where string, args ...any) (v V, present bool, err error) {
+	if m.opts.table == "" {
+		return v, false, ErrNoTable
+	}
+	var p *V
+	err = m.queryScalar(ctx, "SELECT "+expr+" FROM "+m.opts.table+m.scope(where, !m.withTrashed), args, &p)
+	if err != nil || p == nil {
+		return v, false, err
+	}
+	return *p, true, nil
+}
+
+// MinMax returns the least and greatest values of column over the table
+// rows matching where, in a single query. It returns the zero Vs and
+// ErrNotFound when no row has a non-NULL value. column must belong to the Schema
+func MinMax[V any, T any, R Schema[T]](ctx context.Context, m *SQLTableManager[T, R], column, where string, args ...any) (lo, hi V, err error) {
+	if m.opts.table == "" {
+		return lo, hi, ErrNoTable
+	}
+	if err = m.checkColumn(column); err != nil {
+		return lo, hi, err
+	}
+	var pLo, pHi *V
+	err = m.queryScalar(ctx, "SELECT MIN("+column+"), MAX("+column+") FROM "+m.opts.table+m.scope(where, !m.withTrashed), args, &pLo, &pHi)
+	if err != nil {
+		return lo, hi, err
+	}
+	if pLo == nil || pHi == nil {
+		return lo, hi, ErrNotFound
+	}
+	return *pLo, *pHi, nil
+}
+
+// Distinct returns the distinct non-NULL values of column over the table
+// rows matching where, in ascending order. column must belong to the Schema
+func Distinct[V any, T any, R Schema[T]](ctx context.Context, m *SQLTableManager[T, R], column, where string, args ...any) (values []V, err error) {
+	if m.opts.table == "" {
+		return nil, ErrNoTable
+	}
+	if err = m.checkColumn(column); err != nil {
+		return nil, err
+	}
+	query := "SELECT DISTINCT " + column + " FROM " + m.opts.table + m.scope(where, !m.withTrashed) + " ORDER BY " + column
+	err = m.queryEach(ctx, query, args, func(rows *sql.Rows) error {
+		var p *V
+		if err := rows.Scan(&p); err != nil {
+			return err
+		}
+		if p != nil {
+			values = append(values, *p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// QueryRowScan runs query and scans its first row into dest, for ad-hoc
+// destinations no Schema fits, like sql.Row.Scan with the manager's
+// logging, hooks, and error annotation. It returns ErrNotFound when no row
+// matches
+func (m *SQLTableManager[T, R]) QueryRowScan(ctx context.Context, query string, args []any, dest ...any) error {
+	err := m.queryScalar(ctx, query, args, dest...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// queryScalar scans a single row of query into dest
+func (m *SQLTableManager[T, R]) queryScalar(ctx context.Context, query string, args []any, dest ...any) (err error) {
+	defer m.opts.annotate(&err, "QueryRow", query)
+	if m.group != nil {
+		// the *sql.Row of a bound manager cannot carry ErrTxEnded
+		if err := m.group.check(); err != nil {
+			return err
+		}
+	}
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	query = m.opts.finalize(ctx, query, args)
+	args = m.opts.convertArgs(args)
+	ctx, start := m.opts.begin(ctx, OpQueryRow, query, args)
+	err = m.opts.retry(ctx, false, func() error {
+		return m.read(ctx, func(q querier) error {
+			return q.QueryRowContext(ctx, query, args...).Scan(dest...)
+		})
+	})
+	if m.opts.observed() {
+		m.opts.observe(ctx, OpQueryRow, query, args, start, rowsFound(err), err)
+	}
+	return err
+}
+
+// Pluck returns the table rows matching where as a map from their keyCol
+// to their valCol value. When keys repeat, the last row read wins. Both
+// columns must belong to the Schema
+func Pluck[K comparable, V any, T any, R Schema[T]](ctx context.Context, m *SQLTableManager[T, R], keyCol, valCol, where string, args ...any) (map[K]V, error) {
+	if m.opts.table == "" {
+		return nil, ErrNoTable
+	}
+	for _, c := range []string{keyCol, valCol} {
+		if err := m.checkColumn(c); err != nil {
+			return nil, err
+		}
+	}
+	values := make(map[K]V)
+	query := "SELECT " + keyCol + ", " + valCol + " FROM " + m.opts.table + m.scope(where, !m.withTrashed)
+	err := m.queryEach(ctx, query, args, func(rows *sql.Rows) error {
+		var k K
+		var v V
+		if err := rows.Scan(&k, &v); err != nil {
+			return err
+		}
+		values[k] = v
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// ExistingKeys reports which of keys are present in column of the table,
+// with a single IN query, as a set of those found. Soft-deleted rows count
+// as present, since they still hold their keys. No keys run no query.
+// column must belong to the Schema
+func ExistingKeys[K comparable, T any, R Schema[T]](ctx context.Context, m *SQLTableManager[T, R], column string, keys []K) (map[K]bool, error) {
+	if m.opts.table == "" {
+		return nil, ErrNoTable
+	}
+	if err := m.checkColumn(column); err != nil {
+		return nil, err
+	}
+	found := make(map[K]bool)
+	if len(keys) == 0 {
+		return found, nil
+	}
+	args := make([]any, len(keys))
+	for i, k := range keys {
+		args[i] = k
+	}
+	var in Where
+	clause, args := in.In(column, args...).Build(Generic)
+	query := "SELECT " + column + " FROM " + m.opts.table + m.scope(clause, false)
+	err := m.queryEach(ctx, query, args, func(rows *sql.Rows) error {
+		var k K
+		if err := rows.Scan(&k); err != nil {
+			return err
+		}
+		found[k] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// QueryColumn runs query, which must select exactly one column, and returns
+// that column of every row. Use a pointer or sql.Null V for a column that
+// may be NULL
+func QueryColumn[V any, T any, R Schema[T]](ctx context.Context, m *SQLTableManager[T, R], query string, args ...any) (values []V, err error) {
+	err = m.queryEachChecked(ctx, query, args, func(rows *sql.Rows) error {
+		cols, err := rows.Columns()
+		if err != nil {
+			return err
+		}
+		if len(cols) != 1 {
+			return fmt.Errorf("csql: QueryColumn expects 1 column, query returned %d", len(cols))
+		}
+		return nil
+	}, func(rows *sql.Rows) error {
+		var v V
+		if err := rows.Scan(&v); err != nil {
+			return err
+		}
+		values = append(values, v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,188 @@
+package csql_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestQueryColumn(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 3)
+	m := csql.NewSQLTableManager[Item](db)
+	ctx := context.Background()
+	names, err := csql.QueryColumn[string](ctx, m, "SELECT name FROM items ORDER BY id")
+	if err != nil || !slices.Equal(names, []string{"item1", "item2", "item3"}) {
+		t.Fatalf("QueryColumn = %q, %v", names, err)
+	}
+	for _, query := range []string{selectItems, "SELECT id, name FROM items WHERE id > 3"} {
+		if got, err := csql.QueryColumn[string](ctx, m, query); err == nil || !strings.Contains(err.Error(), "expects 1 column, query returned 2") {
+			t.Fatalf("QueryColumn(%q) = %q, %v, want the column count rejected", query, got, err)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	db := openDB(t)
+	m := csql.NewSQLTableManager[Item](db, csql.WithTable("items"))
+	ctx := context.Background()
+	sum, err := csql.Aggregate[int64](ctx, m, "SUM(id)", "")
+	if sum != 0 || !errors.Is(err, csql.ErrNotFound) || !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("SUM over no rows = %d, %v, want ErrNotFound matching sql.ErrNoRows", sum, err)
+	}
+	if _, present, err := csql.AggregateOpt[int64](ctx, m, "SUM(id)", ""); present || err != nil {
+		t.Fatalf("AggregateOpt over no rows = %t, %v, want not present", present, err)
+	}
+	seedItems(t, db, 4)
+	if sum, err := csql.Aggregate[int64](ctx, m, "SUM(id)", ""); sum != 10 || err != nil {
+		t.Fatalf("SUM = %d, %v, want 10", sum, err)
+	}
+	if avg, err := csql.Aggregate[float64](ctx, m, "AVG(id)", "id > ?", 2); avg != 3.5 || err != nil {
+		t.Fatalf("AVG = %v, %v, want 3.5", avg, err)
+	}
+	if _, err := csql.Aggregate[int64](ctx, csql.NewSQLTableManager[Item](db), "SUM(id)", ""); !errors.Is(err, csql.ErrNoTable) {
+		t.Fatalf("Aggregate without a table = %v, want ErrNoTable", err)
+	}
+}
+
+func TestAggregateOpt(t *testing.T) {
+	db := openDB(t)
+	m := csql.NewSQLTableManager[Item](db, csql.WithTable("items"))
+	ctx := context.Background()
+	if avg, present, err := csql.AggregateOpt[float64](ctx, m, "AVG(id)", ""); avg != 0 || present || err != nil {
+		t.Fatalf("AVG over no rows = %v, %t, %v, want not present", avg, present, err)
+	}
+	seedItems(t, db, 3)
+	// a SUM of zero is present, unlike a SUM over no rows
+	if sum, present, err := csql.AggregateOpt[int64](ctx, m, "SUM(id - id)", ""); sum != 0 || !present || err != nil {
+		t.Fatalf("SUM of zeros = %d, %t, %v, want 0 present", sum, present, err)
+	}
+	if sum, present, err := csql.AggregateOpt[int64](ctx, m, "SUM(id)", "id > ?", 3); sum != 0 || present || err != nil {
+		t.Fatalf("SUM matching no rows = %d, %t, %v, want not present", sum, present, err)
+	}
+	if avg, present, err := csql.AggregateOpt[float64](ctx, m, "AVG(id)", "id > ?", 1); avg != 2.5 || !present || err != nil {
+		t.Fatalf("AVG = %v, %t, %v, want 2.5 present", avg, present, err)
+	}
+	if _, present, err := csql.AggregateOpt[int64](ctx, csql.NewSQLTableManager[Item](db), "SUM(id)", ""); present || !errors.Is(err, csql.ErrNoTable) {
+		t.Fatalf("AggregateOpt without a table = %t, %v, want ErrNoTable", present, err)
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	db := openDB(t)
+	for i, name := range []any{"b", "a", "b", nil, "c", "a"} {
+		mustExec(t, db, insertItem, i+1, name)
+	}
+	m := csql.NewSQLTableManager[NamedItem](db, csql.WithTable("items"))
+	ctx := context.Background()
+	names, err := csql.Distinct[string](ctx, m, "name", "")
+	if err != nil || !slices.Equal(names, []string{"a", "b", "c"}) {
+		t.Fatalf("Distinct = %q, %v, want sorted unique non-NULL names", names, err)
+	}
+	if names, err := csql.Distinct[string](ctx, m, "name", "id > ?", 4); err != nil || !slices.Equal(names, []string{"a", "c"}) {
+		t.Fatalf("Distinct where id > 4 = %q, %v", names, err)
+	}
+	if _, err := csql.Distinct[string](ctx, m, "name; DROP TABLE items", ""); !errors.Is(err, csql.ErrUnknownColumn) {
+		t.Fatalf("Distinct of an unknown column = %v, want ErrUnknownColumn", err)
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	db := openDB(t)
+	m := csql.NewSQLTableManager[NamedItem](db, csql.WithTable("items"))
+	ctx := context.Background()
+	if lo, hi, err := csql.MinMax[int64](ctx, m, "id", ""); lo != 0 || hi != 0 || !errors.Is(err, csql.ErrNotFound) {
+		t.Fatalf("MinMax over no rows = %d, %d, %v, want ErrNotFound", lo, hi, err)
+	}
+	for _, id := range []int{5, 2, 9, 7} {
+		mustExec(t, db, insertItem, id, "item")
+	}
+	if lo, hi, err := csql.MinMax[int64](ctx, m, "id", ""); lo != 2 || hi != 9 || err != nil {
+		t.Fatalf("MinMax = %d, %d, %v, want 2, 9", lo, hi, err)
+	}
+	if lo, hi, err := csql.MinMax[int64](ctx, m, "id", "id BETWEEN ? AND ?", 3, 8); lo != 5 || hi != 7 || err != nil {
+		t.Fatalf("MinMax between 3 and 8 = %d, %d, %v, want 5, 7", lo, hi, err)
+	}
+	if _, _, err := csql.MinMax[int64](ctx, m, "id", "id > ?", 9); !errors.Is(err, csql.ErrNotFound) {
+		t.Fatalf("MinMax matching no rows = %v, want ErrNotFound", err)
+	}
+	if _, _, err := csql.MinMax[int64](ctx, m, "price", ""); !errors.Is(err, csql.ErrUnknownColumn) {
+		t.Fatalf("MinMax of an unknown column = %v, want ErrUnknownColumn", err)
+	}
+}
+
+func TestPluck(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 3)
+	mustExec(t, db, insertItem, 4, "item1")
+	m := csql.NewSQLTableManager[NamedItem](db, csql.WithTable("items"))
+	ctx := context.Background()
+	names, err := csql.Pluck[int64, string](ctx, m, "id", "name", "id < ?", 4)
+	if err != nil || !maps.Equal(names, map[int64]string{1: "item1", 2: "item2", 3: "item3"}) {
+		t.Fatalf("Pluck = %v, %v", names, err)
+	}
+	// sqlite reads in id order, so the later item1 wins
+	ids, err := csql.Pluck[string, int64](ctx, m, "name", "id", "")
+	if err != nil || !maps.Equal(ids, map[string]int64{"item1": 4, "item2": 2, "item3": 3}) {
+		t.Fatalf("Pluck with a repeated key = %v, %v, want the last row", ids, err)
+	}
+	if got, err := csql.Pluck[int64, string](ctx, m, "id", "name", "id > ?", 9); err != nil || got == nil || len(got) != 0 {
+		t.Fatalf("Pluck of no rows = %v, %v, want an empty map", got, err)
+	}
+	if _, err := csql.Pluck[int64, string](ctx, m, "id", "secret", ""); !errors.Is(err, csql.ErrUnknownColumn) {
+		t.Fatalf("Pluck of an unknown column = %v, want ErrUnknownColumn", err)
+	}
+}
+
+func TestExistingKeys(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	seedItems(t, db, 5)
+	m := csql.NewSQLTableManager[NamedItem](db, csql.WithTable("items"))
+	ctx := context.Background()
+	before := rec.Count("SELECT")
+	found, err := csql.ExistingKeys(ctx, m, "id", []int64{2, 4, 7, 9, 4})
+	if err != nil || !maps.Equal(found, map[int64]bool{2: true, 4: true}) {
+		t.Fatalf("ExistingKeys = %v, %v, want only the present keys", found, err)
+	}
+	if n := rec.Count("SELECT") - before; n != 1 {
+		t.Fatalf("ran %d queries, want a single IN query", n)
+	}
+	if found, err := csql.ExistingKeys(ctx, m, "name", []string{"item1", "item6"}); err != nil || !maps.Equal(found, map[string]bool{"item1": true}) {
+		t.Fatalf("ExistingKeys of names = %v, %v", found, err)
+	}
+	before = rec.Count("SELECT")
+	if found, err := csql.ExistingKeys[int64](ctx, m, "id", nil); err != nil || found == nil || len(found) != 0 || rec.Count("SELECT") != before {
+		t.Fatalf("ExistingKeys of no keys = %v, %v, want an empty set without a query", found, err)
+	}
+	if _, err := csql.ExistingKeys(ctx, m, "secret", []int64{1}); !errors.Is(err, csql.ErrUnknownColumn) {
+		t.Fatalf("ExistingKeys of an unknown column = %v, want ErrUnknownColumn", err)
+	}
+}
+
+func TestQueryRowScan(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 3)
+	var l infoLog
+	m := csql.NewSQLTableManager[Item](db, csql.WithLogger(&l))
+	ctx := context.Background()
+	var n int64
+	var last string
+	if err := m.QueryRowScan(ctx, "SELECT COUNT(*), MAX(name) FROM items WHERE id > ?", []any{1}, &n, &last); err != nil || n != 2 || last != "item3" {
+		t.Fatalf("QueryRowScan = %d, %q, %v", n, last, err)
+	}
+	if err := m.QueryRowScan(ctx, "SELECT id FROM items WHERE id = ?", []any{9}, &n); !errors.Is(err, csql.ErrNotFound) {
+		t.Fatalf("QueryRowScan of no row = %v, want ErrNotFound", err)
+	}
+	if err := m.QueryRowScan(ctx, "SELECT id, name FROM items", nil, &n); err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Fatalf("QueryRowScan into too few destinations = %v, want the annotated error", err)
+	}
+	if len(l.infos) != 3 {
+		t.Fatalf("logged %d operations, want each QueryRowScan", len(l.infos))
+	}
+}
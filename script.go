@@ -0,0 +1,133 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ExecScript splits script into statements at semicolons and executes them
+// in order within a single database transaction, like ExecBatch. Semicolons
+// within quotes, comments, and dollar-quoted blocks such as function bodies
+// do not split. The statements take no arguments, so placeholders are not
+// rewritten. MySQL commits most DDL implicitly, so a failing script there
+// cannot be rolled back in full
+func (m *SQLTableManager[_, _]) ExecScript(ctx context.Context, script string) error {
+	parts := splitScript(script, m.opts.dialect == MySQL)
+	if len(parts) == 0 {
+		return nil
+	}
+	stmts := make([]Stmt, len(parts))
+	queries := make([]string, len(parts))
+	for i, part := range parts {
+		stmts[i] = Stmt{SQL: part}
+		queries[i] = part
+		if m.opts.rewriter != nil {
+			queries[i] = m.opts.rewriter(ctx, part)
+		}
+	}
+	return m.execBatch(ctx, "ExecScript", stmts, queries)
+}
+
+// ExecMulti executes statements in order, each on its own outside any
+// database transaction, and returns the result of each. It stops at the
+// first failing statement, returning the results of those before it with
+// the error, for maintenance scripts reporting their progress. Under
+// WithDryRun every statement is captured and no results are returned, as
+// nothing ran
+func (m *SQLTableManager[_, _]) ExecMulti(ctx context.Context, statements []string) ([]sql.Result, error) {
+	results := make([]sql.Result, 0, len(statements))
+	for i, stmt := range statements {
+		res, err := m.execAudited(ctx, "ExecMulti", stmt, nil)
+		if err != nil {
+			return results, fmt.Errorf("csql: statement %d: %w", i, err)
+		}
+		// res is nil under WithDryRun
+		if res != nil {
+			results = append(results, res)
+		}
+	}
+	return results, nil
+}
+
+// splitScript returns the statements of script, trimmed, skipping those
+// holding nothing but comments. backslash escapes quotes within literals
+func splitScript(script string, backslash bool) []string {
+	var stmts []string
+	start := 0
+	// code is set once the current statement has SQL beyond comments
+	code := false
+	for i := 0; i < len(script); i++ {
+		switch c := script[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = closeQuote(script, i, backslash)
+			code = true
+		case strings.HasPrefix(script[i:], "--"):
+			if end := strings.IndexByte(script[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(script)
+			}
+		case strings.HasPrefix(script[i:], "/*"):
+			if end := strings.Index(script[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(script)
+			}
+		case c == '$':
+			if tag := dollarTag(script[i:]); tag != "" {
+				if end := strings.Index(script[i+len(tag):], tag); end >= 0 {
+					i += len(tag) + end + len(tag) - 1
+				} else {
+					i = len(script)
+				}
+			}
+			code = true
+		case c == ';':
+			if code {
+				stmts = append(stmts, strings.TrimSpace(script[start:i]))
+			}
+			start, code = i+1, false
+		case c != ' ' && c != '\t' && c != '\n' && c != '\r':
+			code = true
+		}
+	}
+	if code && start < len(script) {
+		stmts = append(stmts, strings.TrimSpace(script[start:]))
+	}
+	return stmts
+}
+
+// closeQuote returns the index of the quote closing the one at script[open],
+// or the end of script when it is unterminated. A doubled quote is read as
+// two adjacent literals, which splits the same way
+func closeQuote(script string, open int, backslash bool) int {
+	quote := script[open]
+	for i := open + 1; i < len(script); i++ {
+		switch script[i] {
+		case '\\':
+			if backslash {
+				i++
+			}
+		case quote:
+			return i
+		}
+	}
+	return len(script)
+}
+
+// dollarTag returns the Postgres dollar quote, such as $$ or $body$, that
+// s starts with, or "" when s starts with something else like $1
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 1 && c >= '0' && c <= '9':
+		default:
+			return ""
+		}
+	}
+	return ""
+}
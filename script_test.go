@@ -0,0 +1,82 @@
+package csql_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestExecScript(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	m := csql.NewSQLTableManager[Item](db)
+	script := `
+		-- seed the items; the comment holds a semicolon
+		INSERT INTO items (id, name) VALUES (1, 'one');
+		INSERT INTO items (id, name) VALUES (2, 'two; and a half');
+		/* trailing; */
+	`
+	if err := m.ExecScript(ctx, script); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := m.Query("SELECT id, name FROM items ORDER BY id")
+	if err != nil || len(rows) != 2 || rows[1].Name != "two; and a half" {
+		t.Fatalf("Query = %v, %v, want both rows, the literal whole", rows, err)
+	}
+}
+
+func TestExecScriptRollsBack(t *testing.T) {
+	m := csql.NewSQLTableManager[Item](openDB(t))
+	script := "INSERT INTO items (id, name) VALUES (1, 'one'); INSERT INTO missing VALUES (1)"
+	if err := m.ExecScript(context.Background(), script); err == nil || !strings.Contains(err.Error(), "statement 1:") {
+		t.Fatalf("ExecScript = %v, want the second statement to fail", err)
+	}
+	if n := countItems(t, m); n != 0 {
+		t.Fatalf("stored %d rows, want the script rolled back", n)
+	}
+}
+
+// TestExecScriptDollarQuoted splits a Postgres function body, which sqlite
+// cannot run, so the function is stubbed out and only the split is checked
+func TestExecScriptDollarQuoted(t *testing.T) {
+	fn := `CREATE FUNCTION touch() RETURNS trigger AS $body$
+BEGIN
+	NEW.name := 'touched; again';
+	RETURN NEW;
+END;
+$body$ LANGUAGE plpgsql`
+	db, rec := openRecorded(t, func(_ context.Context, query string) bool {
+		return strings.HasPrefix(query, "CREATE FUNCTION")
+	})
+	m := csql.NewSQLTableManager[Item](db)
+	if err := m.ExecScript(context.Background(), fn+";\nINSERT INTO items (id, name) VALUES (1, 'one');"); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range rec.Stmts() {
+		if strings.HasPrefix(s.SQL, "CREATE FUNCTION") || strings.HasPrefix(s.SQL, "INSERT") {
+			got = append(got, s.SQL)
+		}
+	}
+	want := []string{fn, "INSERT INTO items (id, name) VALUES (1, 'one')"}
+	if strings.Join(got, "\n--\n") != strings.Join(want, "\n--\n") {
+		t.Fatalf("executed %q, want %q", got, want)
+	}
+}
+
+func TestExecMultiDryRun(t *testing.T) {
+	var captured []string
+	m := csql.NewSQLTableManager[Item](openDB(t), csql.WithDryRun(func(query string, _ []any) {
+		captured = append(captured, query)
+	}))
+	statements := []string{"DELETE FROM items", "VACUUM"}
+	results, err := m.ExecMulti(context.Background(), statements)
+	if err != nil || results == nil || len(results) != 0 {
+		t.Fatalf("ExecMulti = %v, %v, want no results", results, err)
+	}
+	if strings.Join(captured, "; ") != strings.Join(statements, "; ") {
+		t.Fatalf("captured %q, want %q", captured, statements)
+	}
+}
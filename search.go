@@ -0,0 +1,75 @@
+package csql
+
+import (
+	"context"
+	"strings"
+)
+
+// SearchMode selects where a Search term may appear in a column
+type SearchMode int
+
+const (
+	// Contains matches the term anywhere in the column
+	Contains SearchMode = iota
+	// Prefix matches columns starting with the term
+	Prefix
+	// Suffix matches columns ending with the term
+	Suffix
+)
+
+// Search returns the table rows whose column matches term per mode.
+// LIKE wildcards within term match literally
+func (m *SQLTableManager[T, R]) Search(ctx context.Context, column, term string, mode SearchMode) ([]T, error) {
+	if m.opts.table == "" {
+		return nil, ErrNoTable
+	}
+	if err := m.checkColumn(column); err != nil {
+		return nil, err
+	}
+	pattern := EscapeLike(term, '\\')
+	switch mode {
+	case Prefix:
+		pattern += "%"
+	case Suffix:
+		pattern = "%" + pattern
+	default:
+		pattern = "%" + pattern + "%"
+	}
+	where := column + " LIKE ?" + m.opts.dialect.LikeEscape()
+	return m.QueryContext(ctx, "SELECT * FROM "+m.opts.table+m.scope(where, !m.withTrashed), pattern)
+}
+
+// EscapeLike escapes the LIKE wildcards % and _ and esc itself within s, so
+// s matches literally in a LIKE or ILIKE pattern whose ESCAPE clause names
+// esc. esc must be an ASCII character
+func EscapeLike(s string, esc byte) string {
+	if !strings.ContainsAny(s, "%_"+string(esc)) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '%' || c == '_' || c == esc {
+			b.WriteByte(esc)
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// Like returns s escaped with backslash and wrapped in %, matching columns
+// containing s. Follow the pattern with the dialect's LikeEscape clause,
+// as in "name LIKE ?" + d.LikeEscape()
+func Like(s string) string {
+	return "%" + EscapeLike(s, '\\') + "%"
+}
+
+// LikeEscape returns the ESCAPE clause naming backslash as the LIKE escape
+// character. MySQL string literals treat backslash as an escape, so its
+// literal is doubled
+func (d Dialect) LikeEscape() string {
+	if d == MySQL {
+		return ` ESCAPE '\\'`
+	}
+	return ` ESCAPE '\'`
+}
@@ -0,0 +1,90 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestSearch(t *testing.T) {
+	db := openDB(t)
+	for i, name := range []string{"100% cotton", "1000 cotton", "cotton_blend", "cottonXblend", `back\slash`, "plain"} {
+		mustExec(t, db, insertItem, i+1, name)
+	}
+	m := csql.NewSQLTableManager[NamedItem](db, csql.WithTable("items"))
+	tests := []struct {
+		term string
+		mode csql.SearchMode
+		want []int64
+	}{
+		{"0%", csql.Contains, []int64{1}},
+		{"100%", csql.Prefix, []int64{1}},
+		{"_blend", csql.Suffix, []int64{3}},
+		{"cotton", csql.Prefix, []int64{3, 4}},
+		{"cotton", csql.Suffix, []int64{1, 2}},
+		{`k\s`, csql.Contains, []int64{5}},
+		{"%", csql.Contains, []int64{1}},
+	}
+	for _, tt := range tests {
+		got, err := m.Search(context.Background(), "name", tt.term, tt.mode)
+		if err != nil {
+			t.Fatalf("Search(%q) = %v", tt.term, err)
+		}
+		var ids []int64
+		for _, row := range got {
+			ids = append(ids, row.ID)
+		}
+		slices.Sort(ids)
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("Search(%q, %d) matched %v, want %v", tt.term, tt.mode, ids, tt.want)
+		}
+	}
+	if _, err := m.Search(context.Background(), "nope", "x", csql.Contains); !errors.Is(err, csql.ErrUnknownColumn) {
+		t.Fatalf("Search of an unknown column = %v, want ErrUnknownColumn", err)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in   string
+		esc  byte
+		want string
+	}{
+		{"plain", '\\', "plain"},
+		{`50%_off\`, '\\', `50\%\_off\\`},
+		{"a!b%", '!', "a!!b!%"},
+		{"a\\b", '!', "a\\b"},
+		{"日本_語%", '\\', `日本\_語\%`},
+		{"", '\\', ""},
+	}
+	for _, tt := range tests {
+		if got := csql.EscapeLike(tt.in, tt.esc); got != tt.want {
+			t.Errorf("EscapeLike(%q, %q) = %q, want %q", tt.in, tt.esc, got, tt.want)
+		}
+	}
+}
+
+func TestLike(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, `INSERT INTO items (id, name) VALUES (1, '50% off'), (2, '500 off'), (3, 'a_b'), (4, 'axb'), (5, 'c:\dir'), (6, '日本_語')`)
+	m := csql.NewSQLTableManager[Item](db)
+	for term, want := range map[string][]int64{"0%": {1}, "_": {3, 6}, `\`: {5}, "本_": {6}} {
+		got, err := m.Query("SELECT id, name FROM items WHERE name LIKE ?"+csql.SQLite.LikeEscape()+" ORDER BY id", csql.Like(term))
+		var ids []int64
+		for _, row := range got {
+			ids = append(ids, row.ID)
+		}
+		if err != nil || !slices.Equal(ids, want) {
+			t.Errorf("Like(%q) matched %v, %v, want %v", term, ids, err, want)
+		}
+	}
+	if got, want := csql.MySQL.LikeEscape(), ` ESCAPE '\\'`; got != want {
+		t.Fatalf("MySQL LikeEscape = %q, want %q", got, want)
+	}
+	if got, want := csql.Postgres.LikeEscape(), ` ESCAPE '\'`; got != want {
+		t.Fatalf("Postgres LikeEscape = %q, want %q", got, want)
+	}
+}
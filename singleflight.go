@@ -0,0 +1,113 @@
+package csql
+
+import (
+	"context"
+	"sync"
+)
+
+// WithSingleflight makes concurrent Query and QueryRow calls with the same
+// query text and arguments share one database round trip. Every caller
+// receives its own copy of the rows, made through the Schema. The shared
+// query runs without the callers' cancellation and is only cancelled once
+// every caller waiting on it has given up
+func WithSingleflight() Option {
+	return func(o *options) error {
+		o.flights = &flightGroup{calls: make(map[string]*flight)}
+		return nil
+	}
+}
+
+// share runs fn to append rows to dst. Under WithSingleflight concurrent
+// calls for key share a single run of fn and each append copies of its rows
+func (m *SQLTableManager[T, R]) share(ctx context.Context, key string, keyed bool, dst []T, fn func(context.Context, []T) ([]T, error)) ([]T, error) {
+	g := m.opts.flights
+	if g == nil || !keyed {
+		return fn(ctx, dst)
+	}
+	shared, err := g.do(ctx, key, func(ctx context.Context) (any, error) {
+		return fn(ctx, nil)
+	})
+	if err != nil {
+		return dst, err
+	}
+	rows := shared.([]T)
+	for i := range rows {
+		dst = append(dst, m.copyRow(&rows[i]))
+	}
+	return dst, nil
+}
+
+// copyRow copies row through the Schema, or shallowly when its Fields
+// have no driver values
+func (m *SQLTableManager[T, R]) copyRow(row *T) T {
+	var c T
+	if v, ok := driverValues(R(row).Fields()); ok && R(&c).ScanRow(valueScanner(v)) == nil {
+		return c
+	}
+	return *row
+}
+
+// flightGroup tracks the shared calls in flight by key
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+
+	val      any
+	err      error
+	panicked any
+}
+
+// do returns the result of fn, joining the call in flight for key if any.
+// fn runs detached from ctx, and is cancelled when its last waiter leaves
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) (any, error)) (any, error) {
+	g.mu.Lock()
+	f, ok := g.calls[key]
+	if !ok {
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = f
+		go g.run(fctx, key, f, fn)
+	}
+	f.waiters++
+	g.mu.Unlock()
+	select {
+	case <-f.done:
+		if f.panicked != nil {
+			panic(f.panicked)
+		}
+		return f.val, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		if f.waiters--; f.waiters == 0 {
+			f.cancel()
+			g.forget(key, f)
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (g *flightGroup) run(ctx context.Context, key string, f *flight, fn func(context.Context) (any, error)) {
+	defer func() {
+		f.panicked = recover()
+		g.mu.Lock()
+		g.forget(key, f)
+		g.mu.Unlock()
+		f.cancel()
+		close(f.done)
+	}()
+	f.val, f.err = fn(ctx)
+}
+
+// forget removes f from the calls in flight unless a new call replaced it
+func (g *flightGroup) forget(key string, f *flight) {
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+}
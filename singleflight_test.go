@@ -0,0 +1,103 @@
+package csql_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+// slowQueries holds every statement selecting from items for a while, so
+// concurrent callers overlap
+func slowQueries(_ context.Context, query string) bool {
+	if strings.HasPrefix(query, "SELECT") {
+		time.Sleep(50 * time.Millisecond)
+	}
+	return false
+}
+
+func TestSingleflight(t *testing.T) {
+	db, rec := openRecorded(t, slowQueries)
+	seedItems(t, db, 2)
+	m := csql.NewSQLTableManager[Item](db, csql.WithSingleflight())
+	const callers = 20
+	results := make([][]Item, callers)
+	errs := make([]error, callers)
+	var start, wg sync.WaitGroup
+	start.Add(1)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start.Wait()
+			results[i], errs[i] = m.Query(selectItems)
+		}(i)
+	}
+	start.Done()
+	wg.Wait()
+	for i := range results {
+		if errs[i] != nil || len(results[i]) != 2 {
+			t.Fatalf("caller %d: Query = %v, %v", i, results[i], errs[i])
+		}
+	}
+	results[0][0].Name = "modified"
+	if results[1][0].Name != "item1" {
+		t.Fatal("callers share result rows")
+	}
+	if n := rec.Count(selectItems); n != 1 {
+		t.Fatalf("database saw the query %d times, want 1", n)
+	}
+}
+
+func TestSingleflightKeysArgs(t *testing.T) {
+	db, rec := openRecorded(t, slowQueries)
+	seedItems(t, db, 2)
+	m := csql.NewSQLTableManager[Item](db, csql.WithSingleflight())
+	const byID = "SELECT id, name FROM items WHERE id = ?"
+	var wg sync.WaitGroup
+	rows := make([]Item, 2)
+	for i := range rows {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rows[i], _ = m.QueryRow(byID, i+1)
+		}(i)
+	}
+	wg.Wait()
+	if rows[0].ID != 1 || rows[1].ID != 2 {
+		t.Fatalf("QueryRow = %v, want each caller its own row", rows)
+	}
+	if n := rec.Count(byID); n != 2 {
+		t.Fatalf("database saw the query %d times, want 2", n)
+	}
+}
+
+func TestSingleflightCanceledWaiter(t *testing.T) {
+	db, _ := openRecorded(t, slowQueries)
+	seedItems(t, db, 2)
+	m := csql.NewSQLTableManager[Item](db, csql.WithSingleflight())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.QueryContext(ctx, selectItems)
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	var rows []Item
+	var err error
+	waited := make(chan struct{})
+	go func() {
+		rows, err = m.QueryContext(context.Background(), selectItems)
+		close(waited)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	<-done
+	<-waited
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("remaining waiter got %v, %v, want the shared rows", rows, err)
+	}
+}
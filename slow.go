@@ -0,0 +1,40 @@
+package csql
+
+import (
+	"fmt"
+	"time"
+)
+
+// SlowQuery describes an operation that exceeded the slow query threshold
+type SlowQuery struct {
+	// Op is the operation name, e.g. OpQuery
+	Op string
+	// SQL is the statement as sent to the driver
+	SQL string
+	// Duration is the wall time of the operation, including row iteration
+	Duration time.Duration
+	// Rows follows QueryInfo.Rows
+	Rows int64
+	// Plan is the query plan under WithSlowQueryExplain, when available
+	Plan []string
+}
+
+// WithSlowQueryThreshold calls fn, on the calling goroutine once the
+// operation completes, for every operation taking longer than d.
+// Transaction is measured as a whole. A zero d disables detection
+func WithSlowQueryThreshold(d time.Duration, fn func(SlowQuery)) Option {
+	return func(o *options) error {
+		if d < 0 {
+			return fmt.Errorf("csql: slow query threshold must not be negative, got %v", d)
+		}
+		if d > 0 && fn == nil {
+			return fmt.Errorf("csql: slow query callback must not be nil")
+		}
+		o.slowThreshold = d
+		o.slowQuery = fn
+		if d == 0 {
+			o.slowQuery = nil
+		}
+		return nil
+	}
+}
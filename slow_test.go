@@ -0,0 +1,69 @@
+package csql_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+// scanDelay is how long each SlowItem takes to scan
+const scanDelay = 10 * time.Millisecond
+
+// SlowItem is an Item slow to scan
+type SlowItem struct {
+	Item
+}
+
+func (i *SlowItem) ScanRow(s csql.RowScanner) error {
+	time.Sleep(scanDelay)
+	return i.Item.ScanRow(s)
+}
+
+func TestSlowQueryThreshold(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 3)
+	var slow []csql.SlowQuery
+	threshold := csql.WithSlowQueryThreshold(25*time.Millisecond, func(q csql.SlowQuery) { slow = append(slow, q) })
+	m := csql.NewSQLTableManager[SlowItem](db, threshold)
+	if _, err := m.Query("SELECT id, name FROM items WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(slow) != 0 {
+		t.Fatalf("fast query reported slow: %+v", slow)
+	}
+	// each row is fetched quickly, but scanning all three exceeds the threshold
+	if _, err := m.Query(selectItems); err != nil {
+		t.Fatal(err)
+	}
+	if len(slow) != 1 || slow[0].Op != csql.OpQuery || slow[0].SQL != selectItems || slow[0].Rows != 3 || slow[0].Duration < 3*scanDelay {
+		t.Fatalf("slow queries = %+v, want the three row query", slow)
+	}
+
+	slow = nil
+	items := csql.NewSQLTableManager[Item](db, threshold)
+	_, err := items.TransactionFunc(insertItem, []Item{{4, "a"}, {5, "b"}, {6, "c"}}, func(i *Item) []any {
+		time.Sleep(scanDelay)
+		return []any{i.ID, i.Name}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(slow) != 1 || slow[0].Op != csql.OpTransaction || slow[0].Rows != 3 {
+		t.Fatalf("slow queries = %+v, want the transaction as a whole", slow)
+	}
+}
+
+func TestSlowQueryThresholdDisabled(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 3)
+	m := csql.NewSQLTableManager[SlowItem](db, csql.WithSlowQueryThreshold(0, func(q csql.SlowQuery) {
+		t.Errorf("reported %+v with a zero threshold", q)
+	}))
+	if _, err := m.Query(selectItems); err != nil {
+		t.Fatal(err)
+	}
+	if constructPanic(func() { csql.NewSQLTableManager[Item](db, csql.WithSlowQueryThreshold(time.Second, nil)) }) == "" {
+		t.Error("a nil callback was accepted")
+	}
+}
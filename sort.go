@@ -0,0 +1,51 @@
+package csql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownSortKey is returned by QueryOrdered for a sort key it does not allow
+var ErrUnknownSortKey = errors.New("csql: unknown sort key")
+
+// SortField is a requested ordering term, as parsed from an API sort parameter
+type SortField struct {
+	// Key names the term, mapped to a column by the allow-list of QueryOrdered
+	Key string
+	// Desc sorts in descending order
+	Desc bool
+}
+
+// QueryOrdered runs baseQuery ordered by sort, like QueryContext. Each key
+// is mapped to the SQL it sorts by through allowed, so only its values are
+// spliced into the statement, and a key missing from allowed fails with
+// ErrUnknownSortKey before anything runs. baseQuery must not end in an
+// ORDER BY, LIMIT, or other trailing clause. No sort runs baseQuery as it is
+func (m *SQLTableManager[T, R]) QueryOrdered(ctx context.Context, baseQuery string, sort []SortField, allowed map[string]string, args ...any) ([]T, error) {
+	query, err := orderBy(baseQuery, sort, allowed)
+	if err != nil {
+		return nil, err
+	}
+	return m.QueryContext(ctx, query, args...)
+}
+
+// orderBy appends the ORDER BY clause of sort to query
+func orderBy(query string, sort []SortField, allowed map[string]string) (string, error) {
+	if len(sort) == 0 {
+		return query, nil
+	}
+	terms := make([]string, len(sort))
+	for i, f := range sort {
+		column, ok := allowed[f.Key]
+		if !ok || column == "" {
+			return "", fmt.Errorf("%w %q", ErrUnknownSortKey, f.Key)
+		}
+		terms[i] = column + " ASC"
+		if f.Desc {
+			terms[i] = column + " DESC"
+		}
+	}
+	return strings.TrimRight(query, "; \t\r\n") + " ORDER BY " + strings.Join(terms, ", "), nil
+}
@@ -0,0 +1,48 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// itemSorts is the allow-list of the sort keys an API exposes for items
+var itemSorts = map[string]string{"name": "name", "key": "id"}
+
+func TestQueryOrdered(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	mustExec(t, db, "INSERT INTO items (id, name) VALUES (1, 'b'), (2, 'a'), (3, 'b')")
+	m := csql.NewSQLTableManager[Item](db)
+	sort := []csql.SortField{{Key: "name"}, {Key: "key", Desc: true}}
+	rows, err := m.QueryOrdered(context.Background(), "SELECT id, name FROM items WHERE id > ?", sort, itemSorts, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, r := range rows {
+		ids = append(ids, r.ID)
+	}
+	if !slices.Equal(ids, []int64{2, 3, 1}) {
+		t.Fatalf("ids %v, want name ascending then id descending", ids)
+	}
+	stmts := rec.Stmts()
+	if want := "SELECT id, name FROM items WHERE id > ? ORDER BY name ASC, id DESC"; stmts[len(stmts)-1].SQL != want {
+		t.Fatalf("ran %q, want %q", stmts[len(stmts)-1].SQL, want)
+	}
+}
+
+func TestQueryOrderedUnknownKey(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	m := csql.NewSQLTableManager[Item](db)
+	before := len(rec.Stmts())
+	sort := []csql.SortField{{Key: "name"}, {Key: "id; DROP TABLE items"}}
+	if _, err := m.QueryOrdered(context.Background(), "SELECT id, name FROM items", sort, itemSorts); !errors.Is(err, csql.ErrUnknownSortKey) {
+		t.Fatalf("QueryOrdered = %v, want ErrUnknownSortKey", err)
+	}
+	if n := len(rec.Stmts()) - before; n != 0 {
+		t.Fatalf("ran %d statements for a rejected sort, want none", n)
+	}
+}
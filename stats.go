@@ -0,0 +1,88 @@
+package csql
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// OpStats describes a finished operation recorded under WithStatsRecording
+type OpStats struct {
+	// Operation is the operation name, e.g. OpQuery
+	Operation string
+	// Query is the statement as sent to the driver
+	Query string
+	// Rows is the number of rows read by a query, or executed by a Transaction
+	Rows int
+	// Duration is how long the operation took
+	Duration time.Duration
+	// Err is the error the operation failed with
+	Err error
+}
+
+// WithStatsRecording keeps the last n operations in a ring, for LastStats
+// and RecentStats. Without it nothing is recorded
+func WithStatsRecording(n int) Option {
+	return func(o *options) error {
+		if n <= 0 {
+			return fmt.Errorf("csql: stats recording size must be positive, got %d", n)
+		}
+		o.stats = &statsRing{entries: make([]OpStats, n)}
+		return nil
+	}
+}
+
+// LastStats returns the most recently finished operation, reporting false
+// when none was recorded
+func (m *SQLTableManager[_, _]) LastStats() (OpStats, bool) {
+	recent := m.RecentStats(1)
+	if len(recent) == 0 {
+		return OpStats{}, false
+	}
+	return recent[0], true
+}
+
+// RecentStats returns up to n of the most recently finished operations,
+// oldest first
+func (m *SQLTableManager[_, _]) RecentStats(n int) []OpStats {
+	if m.opts.stats == nil {
+		return nil
+	}
+	return m.opts.stats.recent(n)
+}
+
+// statsRing is a fixed-size ring of OpStats, safe for concurrent use
+type statsRing struct {
+	mu      sync.Mutex
+	entries []OpStats
+	head    int
+	full    bool
+}
+
+func (r *statsRing) record(s OpStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.head] = s
+	r.head++
+	if r.head == len(r.entries) {
+		r.head, r.full = 0, true
+	}
+}
+
+func (r *statsRing) recent(n int) []OpStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	size := r.head
+	if r.full {
+		size = len(r.entries)
+	}
+	n = min(n, size)
+	if n <= 0 {
+		return nil
+	}
+	out := make([]OpStats, n)
+	for i := range out {
+		out[i] = r.entries[(r.head-n+i+len(r.entries))%len(r.entries)]
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package csql_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestStatsRingWraps(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 5)
+	m := csql.NewSQLTableManager[Item](db, csql.WithStatsRecording(3))
+	if _, ok := m.LastStats(); ok {
+		t.Fatal("LastStats reported an operation before any ran")
+	}
+	query := func(i int) string { return fmt.Sprintf("SELECT id, name FROM items WHERE id <= %d", i) }
+	for i := 1; i <= 5; i++ {
+		if _, err := m.Query(query(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recent := m.RecentStats(10)
+	if len(recent) != 3 {
+		t.Fatalf("RecentStats(10) returned %d operations, want the ring's 3", len(recent))
+	}
+	for i, s := range recent {
+		if want := i + 3; s.Operation != csql.OpQuery || s.Query != query(want) || s.Rows != want || s.Err != nil {
+			t.Fatalf("RecentStats()[%d] = %+v, want query %d", i, s, want)
+		}
+	}
+	if last, ok := m.LastStats(); !ok || last.Query != query(5) {
+		t.Fatalf("LastStats = %+v, %v, want the last query", last, ok)
+	}
+	if recent := m.RecentStats(2); len(recent) != 2 || recent[0].Query != query(4) {
+		t.Fatalf("RecentStats(2) = %+v, want the last two, oldest first", recent)
+	}
+}
+
+func TestStatsDisabledAllocs(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 1)
+	disabled := csql.NewSQLTableManager[Item](db)
+	enabled := csql.NewSQLTableManager[Item](db, csql.WithStatsRecording(4))
+	queryRow := func(m *csql.SQLTableManager[Item, *Item]) func() {
+		return func() {
+			if _, err := m.QueryRow("SELECT id, name FROM items"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if allocs := testing.AllocsPerRun(100, func() {
+		disabled.LastStats()
+		disabled.RecentStats(4)
+	}); allocs != 0 {
+		t.Fatalf("reading disabled stats allocated %v times, want 0", allocs)
+	}
+	off, on := testing.AllocsPerRun(100, queryRow(disabled)), testing.AllocsPerRun(100, queryRow(enabled))
+	if off >= on {
+		t.Fatalf("QueryRow allocated %v times with stats disabled and %v enabled, want recording skipped when disabled", off, on)
+	}
+}
@@ -0,0 +1,303 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrDeadlineApproaching matches the *DeadlineError of WithCommitOnDeadline
+var ErrDeadlineApproaching = errors.New("csql: deadline approaching")
+
+// DeadlineError is returned by TransactionFrom under WithCommitOnDeadline
+// when it committed early because the context deadline was near
+type DeadlineError struct {
+	// Committed is the number of rows committed
+	Committed int
+}
+
+func (e *DeadlineError) Error() string {
+	return fmt.Sprintf("csql: deadline approaching, committed %d rows", e.Committed)
+}
+
+func (e *DeadlineError) Is(target error) bool { return target == ErrDeadlineApproaching }
+
+// WithCommitOnDeadline makes TransactionFrom commit the rows executed so
+// far and return a *DeadlineError when the context deadline is nearer than
+// the time another chunk of rows is expected to take, estimated from a
+// moving average of the chunks executed. Calling TransactionFrom again with
+// the same source resumes with the rows not yet pulled. Transaction and its
+// other variants stay all-or-nothing
+func WithCommitOnDeadline(chunk int) Option {
+	return func(o *options) error {
+		if chunk < 1 {
+			return fmt.Errorf("csql: commit on deadline chunk must be positive, got %d", chunk)
+		}
+		o.deadlineChunk = chunk
+		return nil
+	}
+}
+
+// commitOnDeadline wraps next to report the source exhausted, setting
+// *stopped, before a chunk of rows that is not expected to finish by the
+// deadline of ctx
+func commitOnDeadline[T any](ctx context.Context, chunk int, next func() (T, bool, error), stopped *bool) func() (T, bool, error) {
+	deadline, ok := ctx.Deadline()
+	if chunk <= 0 || !ok {
+		return next
+	}
+	n := 0
+	last := time.Now()
+	// avg is the exponentially weighted moving average of chunk durations
+	var avg time.Duration
+	return func() (T, bool, error) {
+		if n > 0 && n%chunk == 0 {
+			now := time.Now()
+			if avg == 0 {
+				avg = now.Sub(last)
+			} else {
+				avg = (4*avg + now.Sub(last)) / 5
+			}
+			last = now
+			if deadline.Sub(now) < avg {
+				*stopped = true
+				var zero T
+				return zero, false, nil
+			}
+		}
+		n++
+		return next()
+	}
+}
+
+// SourceError is returned by TransactionFrom when the row source fails,
+// distinguishing it from a failure of the statement
+type SourceError struct {
+	// Row is the index of the row the source failed to produce
+	Row int
+	Err error
+}
+
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("csql: row source failed at row %d: %v", e.Row, e.Err)
+}
+
+func (e *SourceError) Unwrap() error { return e.Err }
+
+// RowError is returned by Transaction and its variants when the statement
+// fails for a row
+type RowError struct {
+	// Row is the index of the row the statement failed for
+	Row int
+	// Args holds the row's arguments, as WithRedactor shows them, under
+	// WithErrorIncludeQuery and is nil otherwise
+	Args []any
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	if e.Args == nil {
+		return fmt.Sprintf("csql: row %d: %v", e.Row, e.Err)
+	}
+	return fmt.Sprintf("csql: row %d (args %v): %v", e.Row, e.Args, e.Err)
+}
+
+func (e *RowError) Unwrap() error { return e.Err }
+
+// rowFailure returns the func turning the failure of a row bound to args,
+// which bind in order to columns when not nil, into a *RowError
+func (o *options) rowFailure(columns []string) func(row int, args []any, err error) error {
+	return func(row int, args []any, err error) error {
+		e := &RowError{Row: row, Err: err}
+		if o.errorQuery {
+			if len(columns) != len(args) {
+				columns = nil
+			}
+			e.Args = o.redactBound(columns, args)
+		}
+		return e
+	}
+}
+
+// TransactionFrom is Transaction over rows pulled one at a time from next,
+// which reports false once exhausted, so the rows are never held in memory
+// together. An error from next rolls back and is returned as a *SourceError
+func (m *SQLTableManager[T, R]) TransactionFrom(transaction string, next func() (T, bool, error)) error {
+	return m.TransactionFromContext(context.Background(), transaction, next)
+}
+
+// TransactionFromContext is TransactionFrom bound to ctx
+func (m *SQLTableManager[T, R]) TransactionFromContext(ctx context.Context, transaction string, next func() (T, bool, error)) (err error) {
+	defer m.opts.annotate(&err, "Transaction", transaction)
+	var affected int64
+	defer func() { m.opts.audit(ctx, "Transaction", affected, err) }()
+	if err := m.opts.writable(); err != nil {
+		return err
+	}
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	transaction = m.opts.finalize(ctx, transaction, nil)
+	argsFn := convertingArgs[T, R](&m.opts, nil)
+	if m.opts.dryRun != nil {
+		for n := 0; ; n++ {
+			row, ok, err := next()
+			if err != nil {
+				return &SourceError{Row: n, Err: err}
+			}
+			if !ok {
+				return nil
+			}
+			m.opts.dryRun(transaction, bindArgs[T, R](argsFn, &row))
+		}
+	}
+	var after func(*error)
+	if ctx, after, err = m.opts.beforeExec(ctx, "Transaction", transaction, nil, -1); err != nil {
+		return err
+	}
+	defer after(&err)
+	defer m.opts.invalidate()
+	var execed int
+	if m.opts.observed() {
+		var start time.Time
+		ctx, start = m.opts.begin(ctx, OpTransaction, transaction, nil)
+		defer func() {
+			m.opts.observe(ctx, OpTransaction, transaction, nil, start, int64(execed), err)
+		}()
+	}
+	tx, err := m.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, transaction)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	defer stmt.Close()
+	var stopped bool
+	next = commitOnDeadline(ctx, m.opts.deadlineChunk, next, &stopped)
+	if affected, err = execFrom[T, R](ctx, stmt, next, argsFn, &execed, m.opts.progress(-1), m.opts.rowFailure(m.columns())); err != nil {
+		affected = 0
+		return rollback(tx, err)
+	}
+	if err = tx.Commit(); err != nil {
+		affected = 0
+		return err
+	}
+	if stopped {
+		return &DeadlineError{Committed: execed}
+	}
+	return nil
+}
+
+// errStopped ends a TransactionIter whose consumer stopped early
+var errStopped = errors.New("csql: iteration stopped")
+
+// TransactionIter is Transaction for a statement with a RETURNING clause,
+// yielding the rows it returns, scanned through the Schema, as each row of
+// rows is executed. The transaction commits once every row is executed
+// and yielded, and rolls back when the consumer stops early. A failure is
+// yielded last, with the zero T, after rolling back. The result is an
+// iter.Seq2[T, error], spelled out while the module supports Go 1.22
+func (m *SQLTableManager[T, R]) TransactionIter(ctx context.Context, transaction string, rows []T) func(yield func(T, error) bool) {
+	return trackedIter(&m.opts, func(yield func(T, error) bool) {
+		if err := m.transactIter(ctx, transaction, rows, yield); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	})
+}
+
+// transactIter runs TransactionIter. A consumer stopping early rolls
+// back, but is no failure to report to hooks or the caller
+func (m *SQLTableManager[T, R]) transactIter(ctx context.Context, transaction string, rows []T, yield func(T, error) bool) (err error) {
+	defer m.opts.annotate(&err, "Transaction", transaction)
+	var returned int64
+	defer func() { m.opts.audit(ctx, "Transaction", returned, err) }()
+	if err := m.opts.writable(); err != nil {
+		return err
+	}
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	transaction = m.opts.finalize(ctx, transaction, nil)
+	argsFn := convertingArgs[T, R](&m.opts, nil)
+	if m.opts.dryRun != nil {
+		for _, row := range rows {
+			m.opts.dryRun(transaction, bindArgs[T, R](argsFn, &row))
+		}
+		return nil
+	}
+	var after func(*error)
+	if ctx, after, err = m.opts.beforeExec(ctx, "Transaction", transaction, nil, len(rows)); err != nil {
+		return err
+	}
+	defer after(&err)
+	defer m.opts.invalidate()
+	var execed int
+	if m.opts.observed() {
+		var start time.Time
+		ctx, start = m.opts.begin(ctx, OpTransaction, transaction, nil)
+		defer func() {
+			m.opts.observe(ctx, OpTransaction, transaction, nil, start, int64(execed), err)
+		}()
+	}
+	tx, err := m.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	// a consumer panicking out of its loop must not leave tx open
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+	stmt, err := tx.PrepareContext(ctx, transaction)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	defer stmt.Close()
+	names := m.schemaColumns()
+	for i := range rows {
+		err := m.queryStmt(ctx, stmt, bindArgs[T, R](argsFn, &rows[i]), names, func(row T) error {
+			returned++
+			if !yield(row, nil) {
+				return errStopped
+			}
+			return nil
+		})
+		if errors.Is(err, errStopped) {
+			tx.Rollback()
+			return nil
+		}
+		if err != nil {
+			return rollback(tx, err)
+		}
+		execed++
+	}
+	return tx.Commit()
+}
+
+// queryStmt runs stmt with args and calls fn with each row it returns
+func (m *SQLTableManager[T, R]) queryStmt(ctx context.Context, stmt *sql.Stmt, args []any, names []string, fn func(T) error) error {
+	queryRows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return err
+	}
+	defer queryRows.Close()
+	scanner, err := m.opts.columnScanner(queryRows, names)
+	if err != nil {
+		return err
+	}
+	for queryRows.Next() {
+		var row T
+		if err := m.scanRow(scanner, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return queryRows.Err()
+}
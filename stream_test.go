@@ -0,0 +1,206 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+// insertReturning inserts an item, returning it as stored
+const insertReturning = "INSERT INTO items (id, name) VALUES (?, upper(?)) RETURNING id, name"
+
+func TestTransactionIter(t *testing.T) {
+	m := csql.NewSQLTableManager[Item](openDB(t))
+	var got []Item
+	m.TransactionIter(context.Background(), insertReturning, items(3))(func(row Item, err error) bool {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, row)
+		return true
+	})
+	if len(got) != 3 || got[2] != (Item{ID: 3, Name: "ITEM3"}) {
+		t.Fatalf("yielded %v, want the 3 rows as returned", got)
+	}
+	if n := countItems(t, m); n != 3 {
+		t.Fatalf("stored %d rows, want 3 committed", n)
+	}
+}
+
+func TestTransactionIterBreakRollsBack(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	m := csql.NewSQLTableManager[Item](db)
+	yielded := 0
+	m.TransactionIter(context.Background(), insertReturning, items(3))(func(_ Item, err error) bool {
+		if err != nil {
+			t.Fatal(err)
+		}
+		yielded++
+		return false
+	})
+	if yielded != 1 {
+		t.Fatalf("yielded %d rows after the consumer broke, want 1", yielded)
+	}
+	if rec.Count("ROLLBACK") != 1 || rec.Count("COMMIT") != 0 {
+		t.Fatalf("statements %v, want a rollback", rec.Stmts())
+	}
+	if n := countItems(t, m); n != 0 {
+		t.Fatalf("stored %d rows, want the iteration rolled back", n)
+	}
+}
+
+// source returns a TransactionFrom source pulling rows in order
+func source(rows []Item) func() (Item, bool, error) {
+	return func() (Item, bool, error) {
+		if len(rows) == 0 {
+			return Item{}, false, nil
+		}
+		row := rows[0]
+		rows = rows[1:]
+		return row, true, nil
+	}
+}
+
+func TestTransactionFromDryRun(t *testing.T) {
+	var captured []string
+	m := csql.NewSQLTableManager[Item](openDB(t),
+		csql.WithArgConverter(func(v any) (any, bool) {
+			s, ok := v.(string)
+			return strings.ToUpper(s), ok
+		}),
+		csql.WithDryRun(func(_ string, args []any) {
+			captured = append(captured, fmt.Sprint(args))
+		}))
+	if _, err := m.Transaction(insertItem, items(2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.TransactionFrom(insertItem, source(items(2))); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"[1 ITEM1]", "[2 ITEM2]", "[1 ITEM1]", "[2 ITEM2]"}
+	if strings.Join(captured, " ") != strings.Join(want, " ") {
+		t.Fatalf("captured %q, want %q, converted as when run", captured, want)
+	}
+}
+
+func TestTransactionIterBreakIsNoFailure(t *testing.T) {
+	var lines []string
+	var audited []error
+	m := csql.NewSQLTableManager[Item](openDB(t),
+		csql.WithExecHooks(execLog{name: "hook", lines: &lines}),
+		csql.WithAuditHook(func(e csql.AuditEvent) { audited = append(audited, e.Err) }))
+	m.TransactionIter(context.Background(), insertReturning, items(3))(func(_ Item, err error) bool {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return false
+	})
+	if len(lines) != 2 || lines[1] != "hook after "+insertReturning+": <nil>" {
+		t.Fatalf("hook saw %q, want the early stop reported as success", lines)
+	}
+	if len(audited) != 1 || audited[0] != nil {
+		t.Fatalf("audited %v, want one success", audited)
+	}
+}
+
+// generate returns a source of n items generated as pulled, failing with
+// err instead of the item numbered fail when fail is positive
+func generate(n, fail int, err error) func() (Item, bool, error) {
+	i := 0
+	return func() (Item, bool, error) {
+		if i == n {
+			return Item{}, false, nil
+		}
+		i++
+		if i == fail {
+			return Item{}, false, err
+		}
+		return Item{ID: int64(i), Name: "streamed"}, true, nil
+	}
+}
+
+func TestTransactionFrom(t *testing.T) {
+	db := openDB(t)
+	m := csql.NewSQLTableManager[Item](db)
+	const n = 10000
+	if err := m.TransactionFrom(insertItem, generate(n, 0, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got := countItems(t, m); got != n {
+		t.Fatalf("%d rows stored, want %d", got, n)
+	}
+
+	mustExec(t, db, "DELETE FROM items")
+	boom := errors.New("bad CSV line")
+	err := m.TransactionFrom(insertItem, generate(n, 500, boom))
+	var srcErr *csql.SourceError
+	if !errors.As(err, &srcErr) || srcErr.Row != 499 || !errors.Is(err, boom) {
+		t.Fatalf("TransactionFrom = %v, want a SourceError after 499 rows", err)
+	}
+	if got := countItems(t, m); got != 0 {
+		t.Fatalf("%d rows stored after the source failed, want none", got)
+	}
+
+	// a failing statement is a RowError, not a SourceError
+	mustExec(t, db, "INSERT INTO items (id, name) VALUES (3, 'taken')")
+	err = m.TransactionFrom(insertItem, generate(5, 0, nil))
+	var rowErr *csql.RowError
+	if !errors.As(err, &rowErr) || errors.As(err, &srcErr) || rowErr.Row != 2 {
+		t.Fatalf("TransactionFrom = %v, want a RowError for the third row", err)
+	}
+}
+
+// slowly returns next, sleeping d before each row
+func slowly(d time.Duration, next func() (Item, bool, error)) func() (Item, bool, error) {
+	return func() (Item, bool, error) {
+		time.Sleep(d)
+		return next()
+	}
+}
+
+func TestCommitOnDeadline(t *testing.T) {
+	const rows, chunk = 100, 5
+	db := openDB(t)
+	m := csql.NewSQLTableManager[Item](db, csql.WithCommitOnDeadline(chunk))
+	source := slowly(2*time.Millisecond, generate(rows, 0, nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Millisecond)
+	defer cancel()
+	err := m.TransactionFromContext(ctx, insertItem, source)
+	var de *csql.DeadlineError
+	if !errors.Is(err, csql.ErrDeadlineApproaching) || !errors.As(err, &de) {
+		t.Fatalf("TransactionFrom = %v, want a *DeadlineError", err)
+	}
+	if de.Committed == 0 || de.Committed == rows || de.Committed%chunk != 0 {
+		t.Fatalf("committed %d rows, want some whole chunks of %d", de.Committed, chunk)
+	}
+	if n := countItems(t, m); n != de.Committed {
+		t.Fatalf("%d rows stored, want the %d committed", n, de.Committed)
+	}
+	// resuming with the same source stores the rest
+	if err := m.TransactionFrom(insertItem, source); err != nil {
+		t.Fatal(err)
+	}
+	if n := countItems(t, m); n != rows {
+		t.Fatalf("%d rows stored after resuming, want %d", n, rows)
+	}
+
+	// Transaction stays all-or-nothing
+	mustExec(t, db, "DELETE FROM items")
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	slowArgs := func(i *Item) []any {
+		time.Sleep(time.Millisecond)
+		return []any{i.ID, i.Name}
+	}
+	if _, err := m.TransactionFuncContext(ctx, insertItem, items(rows), slowArgs); err == nil || errors.Is(err, csql.ErrDeadlineApproaching) {
+		t.Fatalf("Transaction past the deadline = %v, want it to fail", err)
+	}
+	if n := countItems(t, m); n != 0 {
+		t.Fatalf("%d rows stored, want the Transaction rolled back", n)
+	}
+}
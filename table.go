@@ -0,0 +1,411 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNoTable is returned by generated statements when the manager has no table
+	ErrNoTable = errors.New("csql: no table configured")
+	// ErrUnknownColumn is returned when a column is not part of the Schema
+	ErrUnknownColumn = errors.New("csql: unknown column")
+)
+
+// Columner is implemented by Schemas that name their columns, in Fields order.
+// Query and QueryRow reject results whose column count differs from it, and
+// scan results selecting the same columns in another order by name
+type Columner interface {
+	Columns() []string
+}
+
+// WithTrashed returns a view of the manager whose generated reads include soft-deleted rows
+func (m *SQLTableManager[T, R]) WithTrashed() *SQLTableManager[T, R] {
+	c := *m
+	c.withTrashed = true
+	return &c
+}
+
+// Select returns the table rows matching where, or every row when where is empty.
+// Soft-deleted rows are skipped unless the manager came from WithTrashed
+func (m *SQLTableManager[T, R]) Select(where string, args ...any) ([]T, error) {
+	if m.opts.table == "" {
+		return nil, ErrNoTable
+	}
+	return m.Query("SELECT * FROM "+m.opts.table+m.scope(where, !m.withTrashed), args...)
+}
+
+// SelectRow returns the first table row matching where.
+// Soft-deleted rows are skipped unless the manager came from WithTrashed
+func (m *SQLTableManager[T, R]) SelectRow(where string, args ...any) (row T, err error) {
+	if m.opts.table == "" {
+		return row, ErrNoTable
+	}
+	return m.QueryRow("SELECT * FROM "+m.opts.table+m.scope(where, !m.withTrashed), args...)
+}
+
+// SelectColumns returns the Schema's columns as a SELECT list, in Fields
+// order, or * when the Schema does not name them
+func (m *SQLTableManager[T, R]) SelectColumns() string {
+	if c := m.columns(); len(c) > 0 {
+		return strings.Join(c, ", ")
+	}
+	return "*"
+}
+
+// QueryProjected is Select bound to ctx, listing the SelectColumns rather
+// than SELECT * so the result stays in ScanRow order across migrations
+func (m *SQLTableManager[T, R]) QueryProjected(ctx context.Context, where string, args ...any) ([]T, error) {
+	if m.opts.table == "" {
+		return nil, ErrNoTable
+	}
+	return m.QueryContext(ctx, "SELECT "+m.SelectColumns()+" FROM "+m.opts.table+m.scope(where, !m.withTrashed), args...)
+}
+
+// QueryAllPaged yields every table row in keyColumn order, fetching
+// pageSize rows at a time by keyset pagination, each page starting after
+// the last key of the one before, so neither OFFSET nor the whole table is
+// needed. keyColumn must be unique and is read from the rows' Fields. The
+// rows are selected by SelectColumns, as by QueryProjected. Soft-deleted rows are skipped unless the manager came from WithTrashed.
+// A failure is yielded last, with the zero T. The result is an
+// iter.Seq2[T, error], spelled out while the module supports Go 1.22
+func (m *SQLTableManager[T, R]) QueryAllPaged(ctx context.Context, keyColumn string, pageSize int) func(yield func(T, error) bool) {
+	return trackedIter(&m.opts, func(yield func(T, error) bool) {
+		var zero T
+		if err := m.queryAllPaged(ctx, keyColumn, pageSize, yield); err != nil {
+			yield(zero, err)
+		}
+	})
+}
+
+// queryAllPaged runs QueryAllPaged, returning nil once yield stops it
+func (m *SQLTableManager[T, R]) queryAllPaged(ctx context.Context, keyColumn string, pageSize int, yield func(T, error) bool) error {
+	if m.opts.table == "" {
+		return ErrNoTable
+	}
+	if pageSize <= 0 {
+		return fmt.Errorf("csql: page size must be positive, got %d", pageSize)
+	}
+	key := indexFold(m.columns(), keyColumn)
+	if key < 0 {
+		return fmt.Errorf("%w %q", ErrUnknownColumn, keyColumn)
+	}
+	order := " ORDER BY " + keyColumn + " LIMIT " + strconv.Itoa(pageSize)
+	selectFrom := "SELECT " + m.SelectColumns() + " FROM " + m.opts.table
+	query := selectFrom + m.scope("", !m.withTrashed) + order
+	var args []any
+	for {
+		page, err := m.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		for _, row := range page {
+			if !yield(row, nil) {
+				return nil
+			}
+		}
+		if len(page) < pageSize {
+			return nil
+		}
+		fields := R(&page[len(page)-1]).Fields()
+		if key >= len(fields) {
+			return fmt.Errorf("csql: Fields returns %d values, no key column %q", len(fields), keyColumn)
+		}
+		query = selectFrom + m.scope(keyColumn+" > ?", !m.withTrashed) + order
+		args = []any{fields[key]}
+	}
+}
+
+// Get returns the table row whose key column, from Keyed, equals key.
+// Soft-deleted rows are skipped unless the manager came from WithTrashed
+func (m *SQLTableManager[T, R]) Get(ctx context.Context, key any) (row T, err error) {
+	if m.opts.table == "" {
+		return row, ErrNoTable
+	}
+	keyed, ok := any(R(&row)).(Keyed)
+	if !ok {
+		return row, ErrNoKey
+	}
+	return m.FindByKeys(ctx, map[string]any{keyed.KeyColumn(): key})
+}
+
+// FindByKeys returns the table row whose columns hold the values of keys,
+// for tables with composite keys. Soft-deleted rows are skipped unless the
+// manager came from WithTrashed
+func (m *SQLTableManager[T, R]) FindByKeys(ctx context.Context, keys map[string]any) (row T, err error) {
+	if m.opts.table == "" {
+		return row, ErrNoTable
+	}
+	where, args, err := m.keyWhere(keys)
+	if err != nil {
+		return row, err
+	}
+	return m.QueryRowContext(ctx, "SELECT * FROM "+m.opts.table+m.scope(where, !m.withTrashed), args...)
+}
+
+// Refresh reloads row in place from the table row holding its keyColumn
+// value, read from its Fields, for rows changed elsewhere, as after
+// ErrStaleRow. It returns ErrNotFound when that row no longer exists.
+// Soft-deleted rows are skipped unless the manager came from WithTrashed
+func (m *SQLTableManager[T, R]) Refresh(ctx context.Context, row *T, keyColumn string) error {
+	if m.opts.table == "" {
+		return ErrNoTable
+	}
+	key := indexFold(m.columns(), keyColumn)
+	if key < 0 {
+		return fmt.Errorf("%w %q", ErrUnknownColumn, keyColumn)
+	}
+	fields := R(row).Fields()
+	if key >= len(fields) {
+		return fmt.Errorf("csql: Fields returns %d values, no key column %q", len(fields), keyColumn)
+	}
+	return m.QueryRowInto(ctx, row, "SELECT * FROM "+m.opts.table+m.scope(keyColumn+" = ?", !m.withTrashed), fields[key])
+}
+
+// GetIncludingDeleted is Get, including soft-deleted rows
+func (m *SQLTableManager[T, R]) GetIncludingDeleted(ctx context.Context, key any) (T, error) {
+	return m.WithTrashed().Get(ctx, key)
+}
+
+// Count returns the number of table rows matching where.
+// Soft-deleted rows are skipped unless the manager came from WithTrashed
+func (m *SQLTableManager[T, R]) Count(ctx context.Context, where string, args ...any) (n int64, err error) {
+	if m.opts.table == "" {
+		return 0, ErrNoTable
+	}
+	err = m.queryScalar(ctx, "SELECT COUNT(*) FROM "+m.opts.table+m.scope(where, !m.withTrashed), args, &n)
+	return n, err
+}
+
+// Exists reports whether any table row matches where.
+// Soft-deleted rows are skipped unless the manager came from WithTrashed
+func (m *SQLTableManager[T, R]) Exists(ctx context.Context, where string, args ...any) (bool, error) {
+	if m.opts.table == "" {
+		return false, ErrNoTable
+	}
+	var one int
+	err := m.queryScalar(ctx, "SELECT 1 FROM "+m.opts.table+m.scope(where, !m.withTrashed)+" LIMIT 1", args, &one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete removes the table rows matching where. When WithSoftDelete is
+// configured or the Schema is a SoftDeleter, the rows are marked deleted
+// instead, see ForceDelete. Under WithStrictAffected it returns
+// ErrNotFound when no row matches
+func (m *SQLTableManager[T, R]) Delete(where string, args ...any) error {
+	if m.opts.table == "" {
+		return ErrNoTable
+	}
+	column := m.softDeleteColumn()
+	if column == "" {
+		return m.ForceDelete(where, args...)
+	}
+	res, err := m.execAudited(context.Background(), "Delete", "UPDATE "+m.opts.table+" SET "+column+" = CURRENT_TIMESTAMP"+m.scope(where, true), args)
+	if err != nil || res == nil {
+		return err
+	}
+	return m.opts.checkAffected(res)
+}
+
+// ForceDelete removes the table rows matching where, ignoring soft deletion
+func (m *SQLTableManager[T, R]) ForceDelete(where string, args ...any) error {
+	if m.opts.table == "" {
+		return ErrNoTable
+	}
+	res, err := m.execAudited(context.Background(), "Delete", "DELETE FROM "+m.opts.table+m.scope(where, false), args)
+	if err != nil || res == nil {
+		return err
+	}
+	return m.opts.checkAffected(res)
+}
+
+// DeleteByKeys removes the table rows whose column holds one of keys, like
+// Delete, and returns the number of rows affected. The keys are split
+// across as many statements as the dialect's placeholder limit requires,
+// each running on its own, so a failure leaves the rows of earlier
+// statements deleted and counted. No keys is a no-op
+func (m *SQLTableManager[T, R]) DeleteByKeys(ctx context.Context, column string, keys []any) (deleted int64, err error) {
+	if m.opts.table == "" {
+		return 0, ErrNoTable
+	}
+	if err := m.checkColumn(column); err != nil {
+		return 0, err
+	}
+	size := m.opts.dialect.maxArgs()
+	for len(keys) > 0 {
+		chunk := keys[:min(size, len(keys))]
+		keys = keys[len(chunk):]
+		var in Where
+		clause, args := in.In(column, chunk...).Build(Generic)
+		res, err := m.execAudited(ctx, "Delete", m.deleteQuery(clause), args)
+		if err != nil {
+			return deleted, err
+		}
+		deleted = addAffected(deleted, rowsAffected(res, nil))
+	}
+	return deleted, nil
+}
+
+// DeleteByCompositeKeys is DeleteByKeys for tables with composite keys,
+// removing the table rows whose columns hold the values of one of keys.
+// Every map in keys must name the same columns
+func (m *SQLTableManager[T, R]) DeleteByCompositeKeys(ctx context.Context, keys []map[string]any) (deleted int64, err error) {
+	if m.opts.table == "" {
+		return 0, ErrNoTable
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	columns, err := m.keyColumns(keys[0])
+	if err != nil {
+		return 0, err
+	}
+	for i, k := range keys[1:] {
+		if len(k) != len(columns) {
+			return 0, fmt.Errorf("csql: key %d names %d columns, want %d", i+1, len(k), len(columns))
+		}
+	}
+	size := max(m.opts.dialect.maxArgs()/len(columns), 1)
+	for len(keys) > 0 {
+		chunk := keys[:min(size, len(keys))]
+		keys = keys[len(chunk):]
+		conds := make([]string, len(chunk))
+		args := make([]any, 0, len(chunk)*len(columns))
+		for i, k := range chunk {
+			for _, c := range columns {
+				v, ok := k[c]
+				if !ok {
+					return deleted, fmt.Errorf("csql: key is missing column %q", c)
+				}
+				args = append(args, v)
+			}
+			conds[i] = keyClause(columns)
+		}
+		res, err := m.execAudited(ctx, "Delete", m.deleteQuery("("+strings.Join(conds, ") OR (")+")"), args)
+		if err != nil {
+			return deleted, err
+		}
+		deleted = addAffected(deleted, rowsAffected(res, nil))
+	}
+	return deleted, nil
+}
+
+// deleteQuery returns the statement deleting the table rows matching
+// where, soft-deleting them when configured
+func (m *SQLTableManager[T, R]) deleteQuery(where string) string {
+	if column := m.softDeleteColumn(); column != "" {
+		return "UPDATE " + m.opts.table + " SET " + column + " = CURRENT_TIMESTAMP" + m.scope(where, true)
+	}
+	return "DELETE FROM " + m.opts.table + m.scope(where, false)
+}
+
+// keyWhere returns the condition matching the columns of keys to their values
+func (m *SQLTableManager[T, R]) keyWhere(keys map[string]any) (string, []any, error) {
+	columns, err := m.keyColumns(keys)
+	if err != nil {
+		return "", nil, err
+	}
+	args := make([]any, len(columns))
+	for i, c := range columns {
+		args[i] = keys[c]
+	}
+	return keyClause(columns), args, nil
+}
+
+// keyColumns returns the columns of keys in sorted order, so the generated
+// SQL does not vary with map iteration, after checking each belongs to the Schema
+func (m *SQLTableManager[T, R]) keyColumns(keys map[string]any) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("csql: no key columns")
+	}
+	columns := make([]string, 0, len(keys))
+	for c := range keys {
+		if err := m.checkColumn(c); err != nil {
+			return nil, err
+		}
+		columns = append(columns, c)
+	}
+	slices.Sort(columns)
+	return columns, nil
+}
+
+// keyClause returns col1 = ? AND col2 = ? for columns
+func keyClause(columns []string) string {
+	return strings.Join(columns, " = ? AND ") + " = ?"
+}
+
+// SoftDeleter is implemented by Schemas whose rows are soft-deleted by
+// setting a timestamp column, as an alternative to WithSoftDelete
+type SoftDeleter interface {
+	SoftDeleteColumn() string
+}
+
+// softDeleteColumn returns the WithSoftDelete column, or else the Schema's
+// SoftDeleteColumn, or "" when rows are deleted outright
+func (m *SQLTableManager[T, R]) softDeleteColumn() string {
+	if m.opts.softDelete != "" {
+		return m.opts.softDelete
+	}
+	if s, ok := any(R(new(T))).(SoftDeleter); ok {
+		return s.SoftDeleteColumn()
+	}
+	return ""
+}
+
+// scope builds the WHERE clause of a generated statement, excluding
+// soft-deleted rows when live is set
+func (m *SQLTableManager[T, R]) scope(where string, live bool) string {
+	var conds []string
+	if where != "" {
+		conds = append(conds, "("+where+")")
+	}
+	if column := m.softDeleteColumn(); live && column != "" {
+		conds = append(conds, column+" IS NULL")
+	}
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// columns returns the Schema's column names, from Columns when implemented
+// and from the reflection plan of T otherwise
+func (m *SQLTableManager[T, R]) columns() []string {
+	return columnsOf[T, R]()
+}
+
+// columnsOf is columns for the Schema R
+func columnsOf[T any, R Schema[T]]() []string {
+	if c, ok := any(R(new(T))).(Columner); ok {
+		return c.Columns()
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	plan := planOf(t)
+	names := make([]string, len(plan))
+	for i, f := range plan {
+		names[i] = f.name
+	}
+	return names
+}
+
+// checkColumn ensures column belongs to the Schema before it is spliced into SQL
+func (m *SQLTableManager[T, R]) checkColumn(column string) error {
+	for _, c := range m.columns() {
+		if strings.EqualFold(c, column) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %q", ErrUnknownColumn, column)
+}
@@ -0,0 +1,270 @@
+package csql_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestDeleteByKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    int
+		deletes int
+	}{
+		{"empty", 0, 0},
+		{"single chunk", 10, 1},
+		{"multi chunk", 2500, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := openRecorded(t, nil)
+			seedItems(t, db, 5000)
+			m := csql.NewSQLTableManager[Item](db, csql.WithTable("items"))
+			// every other key is missing, so only half are deleted
+			keys := make([]any, tt.keys)
+			for i := range keys {
+				keys[i] = 2 * (i + 1)
+				if i%2 == 1 {
+					keys[i] = -i
+				}
+			}
+			n, err := m.DeleteByKeys(context.Background(), "id", keys)
+			if want := int64(tt.keys - tt.keys/2); err != nil || n != want {
+				t.Fatalf("DeleteByKeys = %d, %v, want %d", n, err, want)
+			}
+			if got := rec.Count("DELETE"); got != tt.deletes {
+				t.Fatalf("issued %d deletes, want %d", got, tt.deletes)
+			}
+			if got, want := countItems(t, m), 5000-int(n); got != want {
+				t.Fatalf("%d rows left, want %d", got, want)
+			}
+		})
+	}
+}
+
+// Grant is a Schema keyed by two columns
+type Grant struct {
+	UserID int64  `csql:"user_id"`
+	Role   string `csql:"role"`
+	Note   string `csql:"note"`
+}
+
+func (g *Grant) ScanRow(s csql.RowScanner) error { return s.Scan(&g.UserID, &g.Role, &g.Note) }
+
+func (g *Grant) Fields() []any { return []any{g.UserID, g.Role, g.Note} }
+
+func TestFindByKeys(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE grants (user_id INTEGER, role TEXT, note TEXT, PRIMARY KEY (user_id, role))")
+	mustExec(t, db, "INSERT INTO grants VALUES (1, 'admin', 'a'), (1, 'viewer', 'b'), (2, 'admin', 'c')")
+	m := csql.NewSQLTableManager[Grant](db, csql.WithTable("grants"))
+	got, err := m.FindByKeys(ctx, map[string]any{"user_id": 1, "role": "viewer"})
+	if err != nil || got != (Grant{UserID: 1, Role: "viewer", Note: "b"}) {
+		t.Fatalf("FindByKeys = %+v, %v, want the row matching both columns", got, err)
+	}
+	if _, err := m.FindByKeys(ctx, map[string]any{"user_id": 2, "role": "viewer"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("FindByKeys of a row matching one column = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := m.FindByKeys(ctx, map[string]any{"user_id": 1, "owner": "x"}); !errors.Is(err, csql.ErrUnknownColumn) {
+		t.Fatalf("FindByKeys of an unknown column = %v, want ErrUnknownColumn", err)
+	}
+}
+
+func TestQueryAllPaged(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	seedItems(t, db, 250)
+	m := csql.NewSQLTableManager[Item](db, csql.WithTable("items"))
+	n := 0
+	m.QueryAllPaged(context.Background(), "id", 100)(func(row Item, err error) bool {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n++; row.ID != int64(n) {
+			t.Fatalf("row %d has id %d, want the rows in key order", n, row.ID)
+		}
+		return true
+	})
+	if n != 250 {
+		t.Fatalf("yielded %d rows, want 250", n)
+	}
+	if got := rec.Count("SELECT"); got != 3 {
+		t.Fatalf("fetched %d pages, want 3", got)
+	}
+}
+
+// Account is a Schema whose rows are soft-deleted through deleted_at
+type Account struct {
+	ID      int64
+	Name    string
+	Deleted sql.NullString
+}
+
+func (a *Account) ScanRow(s csql.RowScanner) error { return s.Scan(&a.ID, &a.Name, &a.Deleted) }
+
+func (a *Account) Fields() []any { return []any{a.ID, a.Name, a.Deleted} }
+
+// openAccounts returns openDB holding accounts 1 to 3 and their manager
+func openAccounts(t *testing.T, opts ...csql.Option) *csql.SQLTableManager[Account, *Account] {
+	t.Helper()
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE accounts (id INTEGER PRIMARY KEY, name TEXT, deleted_at TEXT)")
+	mustExec(t, db, "INSERT INTO accounts (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c')")
+	return csql.NewSQLTableManager[Account](db, append([]csql.Option{csql.WithTable("accounts")}, opts...)...)
+}
+
+// ids returns the ids of accounts
+func ids(accounts []Account) []int64 {
+	var ids []int64
+	for _, a := range accounts {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+func TestSoftDelete(t *testing.T) {
+	m := openAccounts(t, csql.WithSoftDelete("deleted_at"))
+	if err := m.Delete("id = ?", 2); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := m.Select(""); err != nil || !slices.Equal(ids(got), []int64{1, 3}) {
+		t.Fatalf("Select = %v, %v, want the deleted row skipped", got, err)
+	}
+	if _, err := m.SelectRow("id = ?", 2); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("SelectRow of the deleted row = %v, want sql.ErrNoRows", err)
+	}
+	if n, err := m.Count(context.Background(), "id > ?", 1); err != nil || n != 1 {
+		t.Fatalf("Count = %d, %v, want 1", n, err)
+	}
+	got, err := m.WithTrashed().Select("id = ?", 2)
+	if err != nil || len(got) != 1 || !got[0].Deleted.Valid {
+		t.Fatalf("WithTrashed().Select = %v, %v, want the row marked deleted", got, err)
+	}
+	if got, err := m.Query("SELECT * FROM accounts"); err != nil || len(got) != 3 {
+		t.Fatalf("Query = %v, %v, want raw SQL unscoped", got, err)
+	}
+	if err := m.ForceDelete("id = ?", 2); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := m.WithTrashed().Select(""); err != nil || !slices.Equal(ids(got), []int64{1, 3}) {
+		t.Fatalf("WithTrashed().Select after ForceDelete = %v, %v, want the row removed", got, err)
+	}
+}
+
+func TestSoftDeleteRequiresTable(t *testing.T) {
+	db := openDB(t)
+	if got := constructPanic(func() { csql.NewSQLTableManager[Item](db, csql.WithSoftDelete("deleted_at")) }); !strings.Contains(got, "requires WithTable") {
+		t.Fatalf("NewSQLTableManager panicked with %q, want WithTable required", got)
+	}
+}
+
+// SoftAccount is an Account naming its own key and soft-delete columns
+type SoftAccount struct{ Account }
+
+func (*SoftAccount) KeyColumn() string { return "id" }
+
+func (*SoftAccount) SoftDeleteColumn() string { return "deleted_at" }
+
+func TestSoftDeleter(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE accounts (id INTEGER PRIMARY KEY, name TEXT, deleted_at TEXT)")
+	mustExec(t, db, "INSERT INTO accounts (id, name) VALUES (1, 'a'), (2, 'b')")
+	m := csql.NewSQLTableManager[SoftAccount](db, csql.WithTable("accounts"))
+	if err := m.Delete("id = ?", 2); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := m.Get(ctx, 1); err != nil || got.Name != "a" {
+		t.Fatalf("Get = %+v, %v", got, err)
+	}
+	if _, err := m.Get(ctx, 2); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Get of the deleted row = %v, want sql.ErrNoRows", err)
+	}
+	got, err := m.GetIncludingDeleted(ctx, 2)
+	if err != nil || got.Name != "b" || !got.Deleted.Valid {
+		t.Fatalf("GetIncludingDeleted = %+v, %v, want the row marked deleted", got, err)
+	}
+	if ok, err := m.Exists(ctx, "id = ?", 2); err != nil || ok {
+		t.Fatalf("Exists = %t, %v, want the deleted row hidden", ok, err)
+	}
+	if ok, err := m.WithTrashed().Exists(ctx, "id = ?", 2); err != nil || !ok {
+		t.Fatalf("WithTrashed().Exists = %t, %v, want the deleted row", ok, err)
+	}
+	if n, err := m.Count(ctx, ""); err != nil || n != 1 {
+		t.Fatalf("Count = %d, %v, want 1", n, err)
+	}
+	if got, err := m.Query("SELECT * FROM accounts"); err != nil || len(got) != 2 {
+		t.Fatalf("Query = %v, %v, want raw SQL unscoped", got, err)
+	}
+	if _, err := csql.NewSQLTableManager[Account](db, csql.WithTable("accounts")).Get(ctx, 1); !errors.Is(err, csql.ErrNoKey) {
+		t.Fatalf("Get without a key = %v, want ErrNoKey", err)
+	}
+}
+
+// NameFirst is a Columner scanning its columns in the reverse of the table's order
+type NameFirst struct {
+	Name string
+	ID   int64
+}
+
+func (n *NameFirst) ScanRow(s csql.RowScanner) error { return s.Scan(&n.Name, &n.ID) }
+
+func (n *NameFirst) Fields() []any { return []any{n.Name, n.ID} }
+
+func (n *NameFirst) Columns() []string { return []string{"name", "id"} }
+
+func TestQueryProjected(t *testing.T) {
+	db, rec := openRecorded(t, nil)
+	seedItems(t, db, 3)
+	m := csql.NewSQLTableManager[NameFirst](db, csql.WithTable("items"))
+	if got, want := m.SelectColumns(), "name, id"; got != want {
+		t.Fatalf("SelectColumns = %q, want %q", got, want)
+	}
+	before := len(rec.Stmts())
+	got, err := m.QueryProjected(context.Background(), "id > ?", 1)
+	if err != nil || !slices.Equal(got, []NameFirst{{"item2", 2}, {"item3", 3}}) {
+		t.Fatalf("QueryProjected = %v, %v", got, err)
+	}
+	if stmts := rec.Stmts()[before:]; len(stmts) != 1 || stmts[0].SQL != "SELECT name, id FROM items WHERE (id > ?)" {
+		t.Fatalf("sent %+v, want the columns listed in Schema order", stmts)
+	}
+	if _, err := csql.NewSQLTableManager[NameFirst](db).QueryProjected(context.Background(), ""); !errors.Is(err, csql.ErrNoTable) {
+		t.Fatalf("QueryProjected without a table = %v, want ErrNoTable", err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	m := openAccounts(t, csql.WithSoftDelete("deleted_at"))
+	ctx := context.Background()
+	row, err := m.SelectRow("id = ?", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Exec("UPDATE accounts SET name = 'renamed' WHERE id = 2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Refresh(ctx, &row, "ID"); err != nil || row.ID != 2 || row.Name != "renamed" {
+		t.Fatalf("Refresh = %v, %v, want the out-of-band change picked up", row, err)
+	}
+	if err := m.Delete("id = ?", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Refresh(ctx, &row, "id"); !errors.Is(err, csql.ErrNotFound) {
+		t.Fatalf("Refresh of a soft-deleted row = %v, want ErrNotFound", err)
+	}
+	if err := m.WithTrashed().Refresh(ctx, &row, "id"); err != nil || !row.Deleted.Valid {
+		t.Fatalf("WithTrashed().Refresh = %v, %v, want the row marked deleted", row, err)
+	}
+	if err := m.Refresh(ctx, &row, "email"); !errors.Is(err, csql.ErrUnknownColumn) {
+		t.Fatalf("Refresh by an unknown column = %v, want ErrUnknownColumn", err)
+	}
+	if err := csql.NewSQLTableManager[Account](openDB(t)).Refresh(ctx, &row, "id"); !errors.Is(err, csql.ErrNoTable) {
+		t.Fatalf("Refresh without a table = %v, want ErrNoTable", err)
+	}
+}
@@ -0,0 +1,2 @@
+-- stores one item
+INSERT INTO items (id, name) VALUES (?, ?)
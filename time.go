@@ -0,0 +1,67 @@
+package csql
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// WithTimeLocation converts the time.Time fields of scanned rows to loc,
+// since drivers disagree on the location of the times they return
+func WithTimeLocation(loc *time.Location) Option {
+	return func(o *options) error {
+		if loc == nil {
+			return fmt.Errorf("csql: time location must not be nil")
+		}
+		o.location = loc
+		return nil
+	}
+}
+
+var timePlans sync.Map // reflect.Type -> []field
+
+var nullTimeType = reflect.TypeOf(Null[time.Time]{})
+
+// localize converts the time.Time, *time.Time, and Null[time.Time] fields
+// of the struct pointed to by v to loc
+func localize(v any, loc *time.Location) {
+	rv := reflect.ValueOf(v).Elem()
+	if rv.Kind() != reflect.Struct {
+		return
+	}
+	for _, f := range timePlanOf(rv.Type()) {
+		fv, err := rv.FieldByIndexErr(f.index)
+		if err != nil {
+			continue
+		}
+		switch t := fv.Addr().Interface().(type) {
+		case *time.Time:
+			*t = t.In(loc)
+		case **time.Time:
+			if *t != nil {
+				in := (*t).In(loc)
+				*t = &in
+			}
+		case *Null[time.Time]:
+			if t.Valid {
+				t.V = t.V.In(loc)
+			}
+		}
+	}
+}
+
+func timePlanOf(t reflect.Type) []field {
+	if plan, ok := timePlans.Load(t); ok {
+		return plan.([]field)
+	}
+	var plan []field
+	for _, f := range planOf(t) {
+		ft := t.FieldByIndex(f.index).Type
+		if ft == timeType || ft == reflect.PointerTo(timeType) || ft == nullTimeType {
+			plan = append(plan, f)
+		}
+	}
+	stored, _ := timePlans.LoadOrStore(t, plan)
+	return stored.([]field)
+}
@@ -0,0 +1,73 @@
+package csql_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+// Event holds each kind of time field WithTimeLocation converts
+type Event struct {
+	ID    int64
+	At    time.Time
+	Ended *time.Time
+	Seen  csql.Null[time.Time]
+}
+
+func (e *Event) ScanRow(r csql.RowScanner) error { return csql.ScanInto(r, e) }
+
+func (e *Event) Fields() []any { return csql.ReflectFields(e) }
+
+func TestTimeLocation(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE events (ID INTEGER, At DATETIME, Ended DATETIME, Seen DATETIME)")
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	mustExec(t, db, "INSERT INTO events VALUES (1, ?, ?, ?)", at, at, at)
+	mustExec(t, db, "INSERT INTO events VALUES (2, ?, NULL, NULL)", at)
+	loc := time.FixedZone("UTC+5", 5*3600)
+	m := csql.NewSQLTableManager[Event](db, csql.WithTimeLocation(loc))
+	got, err := m.Query("SELECT ID, At, Ended, Seen FROM events ORDER BY ID")
+	if err != nil || len(got) != 2 {
+		t.Fatalf("Query = %+v, %v", got, err)
+	}
+	e := got[0]
+	if e.At.Location() != loc || e.Ended.Location() != loc || e.Seen.V.Location() != loc {
+		t.Errorf("scanned locations %v, %v, %v, want %v", e.At.Location(), e.Ended.Location(), e.Seen.V.Location(), loc)
+	}
+	if !e.At.Equal(at) || !e.Ended.Equal(at) || !e.Seen.V.Equal(at) {
+		t.Errorf("scanned %+v, want the instant %v", e, at)
+	}
+	if got[1].Ended != nil || got[1].Seen.Valid || got[1].At.Location() != loc {
+		t.Errorf("NULL times scanned as %+v", got[1])
+	}
+	if constructPanic(func() { csql.NewSQLTableManager[Event](db, csql.WithTimeLocation(nil)) }) == "" {
+		t.Error("WithTimeLocation(nil) was accepted")
+	}
+}
+
+func TestTimeLocationDefault(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE events (ID INTEGER, At DATETIME, Ended DATETIME, Seen DATETIME)")
+	// the instant is stored in a zone other than both the driver's and the manager's
+	at := time.Date(2024, 3, 1, 7, 0, 0, 0, time.FixedZone("UTC-5", -5*3600))
+	stored := at.Format(time.RFC3339)
+	mustExec(t, db, "INSERT INTO events VALUES (1, ?, ?, ?)", stored, stored, stored)
+	const query = "SELECT ID, At, Ended, Seen FROM events"
+	plain, err := csql.NewSQLTableManager[Event](db).Query(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	driverLoc := plain[0].Seen.V.Location()
+	if plain[0].At.Location() != driverLoc || plain[0].Ended.Location() != driverLoc {
+		t.Fatalf("without WithTimeLocation scanned %+v, want the driver's locations untouched", plain[0])
+	}
+	loc := time.FixedZone("UTC+9", 9*3600)
+	got, err := csql.NewSQLTableManager[Event](db, csql.WithTimeLocation(loc)).Query(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seen := got[0].Seen; !seen.Valid || seen.V.Location() != loc || !seen.V.Equal(at) || seen.V.Hour() != 21 {
+		t.Fatalf("Null[time.Time] scanned as %v, want %v in %v", seen.V, at, loc)
+	}
+}
@@ -0,0 +1,21 @@
+package csql
+
+import (
+	"context"
+	"time"
+)
+
+// QueryTimed is QueryContext also returning the time spent on the query,
+// including row iteration and scanning
+func (m *SQLTableManager[T, R]) QueryTimed(ctx context.Context, query string, args ...any) ([]T, time.Duration, error) {
+	start := time.Now()
+	rows, err := m.QueryContext(ctx, query, args...)
+	return rows, time.Since(start), err
+}
+
+// ExecTimed is ExecContext also returning the time spent on the statement
+func (m *SQLTableManager[T, R]) ExecTimed(ctx context.Context, query string, args ...any) (time.Duration, error) {
+	start := time.Now()
+	err := m.ExecContext(ctx, query, args...)
+	return time.Since(start), err
+}
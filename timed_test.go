@@ -0,0 +1,36 @@
+package csql_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+func TestTimed(t *testing.T) {
+	const delay = 30 * time.Millisecond
+	db, _ := openRecorded(t, func(_ context.Context, query string) bool {
+		if strings.HasPrefix(query, "SELECT") || strings.HasPrefix(query, "DELETE") {
+			time.Sleep(delay)
+		}
+		return false
+	})
+	seedItems(t, db, 2)
+	m := csql.NewSQLTableManager[Item](db)
+	ctx := context.Background()
+	rows, d, err := m.QueryTimed(ctx, selectItems)
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("QueryTimed = %v, %v", rows, err)
+	}
+	if d < delay || d > delay+time.Second {
+		t.Fatalf("QueryTimed took %v, want about %v", d, delay)
+	}
+	if d, err := m.ExecTimed(ctx, "DELETE FROM items"); err != nil || d < delay || d > delay+time.Second {
+		t.Fatalf("ExecTimed = %v, %v, want about %v", d, err, delay)
+	}
+	if d, err := m.ExecTimed(ctx, "DELETE FROM missing"); err == nil || d < delay {
+		t.Fatalf("failed ExecTimed = %v, %v, want its time and error", d, err)
+	}
+}
@@ -0,0 +1,134 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ErrTimeout is returned when the WithDefaultTimeout deadline ends an
+// operation. It wraps context.DeadlineExceeded
+var ErrTimeout = fmt.Errorf("csql: operation timed out: %w", context.DeadlineExceeded)
+
+// WithDefaultTimeout bounds every operation, including row iteration and
+// whole transactions, by d. A caller context with an earlier deadline wins
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(o *options) error {
+		if d <= 0 {
+			return fmt.Errorf("csql: default timeout must be positive, got %v", d)
+		}
+		o.timeout = d
+		return nil
+	}
+}
+
+// WithStatementTimeout has the database itself cancel statements running
+// longer than d, unlike a context deadline, which only stops the client
+// waiting. It is rounded down to whole milliseconds, at least one.
+//
+// On Postgres Exec takes a connection of its own, setting
+// statement_timeout for the session before the statement and resetting it
+// after, so statements that cannot run within a transaction, such as
+// VACUUM and CREATE INDEX CONCURRENTLY, still can; a connection failing to
+// reset is discarded rather than returned to the pool. Queries, and Exec
+// on managers from WithConn, whose session the caller owns, run within a
+// database transaction first issuing SET LOCAL statement_timeout. Those of
+// queries are rolled back, so a write returning rows, such as a DELETE ...
+// RETURNING, belongs in TransactionQuery rather than Query; the
+// transactions of Transaction and its variants, and InsertManyReturning,
+// set it once, bounding each statement, and commit. On MySQL the optimizer
+// hint MAX_EXECUTION_TIME is added to statements starting with SELECT,
+// after any comments, the only ones MySQL bounds, before any
+// WithQueryRewriter sees them; writes are not bounded. Other dialects
+// ignore it, as do managers from Bind, whose Tx the caller owns
+func WithStatementTimeout(d time.Duration) Option {
+	return func(o *options) error {
+		if d <= 0 {
+			return fmt.Errorf("csql: statement timeout must be positive, got %v", d)
+		}
+		o.statementTimeout = d
+		return nil
+	}
+}
+
+// timeoutMillis returns the WithStatementTimeout in whole milliseconds
+func (o *options) timeoutMillis() string {
+	return strconv.FormatInt(max(o.statementTimeout.Milliseconds(), 1), 10)
+}
+
+// setsStatementTimeout reports whether statements must run within a
+// database transaction setting the WithStatementTimeout
+func (o *options) setsStatementTimeout() bool {
+	return o.statementTimeout > 0 && o.dialect == Postgres
+}
+
+// setStatementTimeout applies the WithStatementTimeout to tx, when setsStatementTimeout
+func (o *options) setStatementTimeout(ctx context.Context, tx txn) error {
+	if !o.setsStatementTimeout() {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, "SET LOCAL statement_timeout = "+o.timeoutMillis())
+	return err
+}
+
+// execSession executes query on a connection of its own, setting the
+// WithStatementTimeout for the session around it
+func (m *SQLTableManager[_, _]) execSession(ctx context.Context, query string, args []any) (sql.Result, error) {
+	c, err := m.acquire(ctx)
+	if err == nil && c == nil {
+		c, err = m.pool.Conn(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	if _, err := c.ExecContext(ctx, "SET statement_timeout = "+m.opts.timeoutMillis()); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if _, resetErr := c.ExecContext(context.WithoutCancel(ctx), "RESET statement_timeout"); resetErr != nil {
+			// the session keeps the timeout, so it must not be reused
+			c.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+	return c.ExecContext(ctx, query, args...)
+}
+
+// leadingSelect matches the SELECT keyword starting a statement, after
+// any comments
+var leadingSelect = regexp.MustCompile(`(?is)^(?:\s*(?:--[^\n]*\n|/\*.*?\*/))*\s*SELECT\b`)
+
+// hintStatementTimeout adds the WithStatementTimeout hint to a MySQL SELECT
+func (o *options) hintStatementTimeout(query string) string {
+	if o.statementTimeout <= 0 || o.dialect != MySQL {
+		return query
+	}
+	loc := leadingSelect.FindStringIndex(query)
+	if loc == nil {
+		return query
+	}
+	return query[:loc[1]] + " /*+ MAX_EXECUTION_TIME(" + o.timeoutMillis() + ") */" + query[loc[1]:]
+}
+
+// deadline applies the default timeout to ctx. The returned func must be
+// deferred; it releases the context and reports timeouts as ErrTimeout
+func (o *options) deadline(ctx context.Context) (context.Context, func(*error)) {
+	if o.timeout <= 0 {
+		return ctx, func(*error) {}
+	}
+	if dl, ok := ctx.Deadline(); ok && time.Until(dl) <= o.timeout {
+		return ctx, func(*error) {}
+	}
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	return ctx, func(err *error) {
+		if *err != nil && parent.Err() == nil && ctx.Err() == context.DeadlineExceeded {
+			*err = ErrTimeout
+		}
+		cancel()
+	}
+}
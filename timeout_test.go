@@ -0,0 +1,197 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+// postgresSessions stubs the Postgres session statements sqlite lacks
+func postgresSessions(_ context.Context, query string) bool {
+	return strings.HasPrefix(query, "SET ") || strings.HasPrefix(query, "RESET ")
+}
+
+// statementsAfterSetup returns what rec saw after the table was created
+func statementsAfterSetup(rec *recorder) []Statement {
+	return rec.Stmts()[1:]
+}
+
+func TestStatementTimeoutExec(t *testing.T) {
+	db, rec := openRecorded(t, postgresSessions)
+	m := csql.NewSQLTableManager[Item](db, csql.WithDialect(csql.Postgres), csql.WithStatementTimeout(1500*time.Millisecond))
+	if err := m.Exec("DELETE FROM items"); err != nil {
+		t.Fatal(err)
+	}
+	got := statementsAfterSetup(rec)
+	want := []string{"SET statement_timeout = 1500", "DELETE FROM items", "RESET statement_timeout"}
+	if len(got) != len(want) {
+		t.Fatalf("statements %v, want %q outside a transaction", got, want)
+	}
+	for i, s := range got {
+		if s.SQL != want[i] || s.Conn != got[0].Conn {
+			t.Fatalf("statements %v, want %q on one connection", got, want)
+		}
+	}
+}
+
+func TestStatementTimeoutQuery(t *testing.T) {
+	db, rec := openRecorded(t, postgresSessions)
+	m := csql.NewSQLTableManager[Item](db, csql.WithDialect(csql.Postgres), csql.WithStatementTimeout(time.Second))
+	if _, err := m.Query(selectItems); err != nil {
+		t.Fatal(err)
+	}
+	got := statementsAfterSetup(rec)
+	var sqls []string
+	for _, s := range got {
+		sqls = append(sqls, s.SQL)
+		if s.Conn != got[0].Conn {
+			t.Fatalf("statements %v, want them on one connection", got)
+		}
+	}
+	if want := []string{"BEGIN", "SET LOCAL statement_timeout = 1000", selectItems, "ROLLBACK"}; !slices.Equal(sqls, want) {
+		t.Fatalf("statements %q, want %q", sqls, want)
+	}
+}
+
+func TestStatementTimeoutInsertReturning(t *testing.T) {
+	db, rec := openRecorded(t, postgresSessions)
+	m := csql.NewSQLTableManager[Item](db, csql.WithDialect(csql.Postgres), csql.WithStatementTimeout(time.Second))
+	ids, err := m.InsertManyReturning(context.Background(), "items", []string{"id", "name"}, items(2), "id")
+	if err != nil || !slices.Equal(ids, []int64{1, 2}) {
+		t.Fatalf("InsertManyReturning = %v, %v", ids, err)
+	}
+	if n := countItems(t, m); n != 2 {
+		t.Fatalf("%d rows after InsertManyReturning, want 2 committed", n)
+	}
+	if n := rec.Count("ROLLBACK"); n != 1 {
+		t.Fatalf("%d rollbacks, want only that of counting", n)
+	}
+}
+
+func TestStatementTimeoutHint(t *testing.T) {
+	var rewritten []string
+	tag := csql.WithQueryRewriter(func(_ context.Context, query string) string {
+		rewritten = append(rewritten, query)
+		return "/* service:x */ " + query
+	})
+	hinted := "SELECT /*+ MAX_EXECUTION_TIME(1000) */ id, name FROM items ORDER BY id"
+	tests := []struct {
+		name  string
+		query string
+		opts  []csql.Option
+		want  string
+	}{
+		{"plain", selectItems, nil, hinted},
+		{"leading comment", "-- list\n/* all */ " + selectItems, nil, "-- list\n/* all */ SELECT /*+ MAX_EXECUTION_TIME(1000) */ id, name FROM items ORDER BY id"},
+		{"rewriter", selectItems, []csql.Option{tag}, "/* service:x */ " + hinted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewritten = nil
+			db, rec := openRecorded(t, nil)
+			opts := append([]csql.Option{csql.WithDialect(csql.MySQL), csql.WithStatementTimeout(time.Second)}, tt.opts...)
+			m := csql.NewSQLTableManager[Item](db, opts...)
+			if _, err := m.Query(tt.query); err != nil {
+				t.Fatal(err)
+			}
+			if got := statementsAfterSetup(rec); len(got) != 1 || got[0].SQL != tt.want {
+				t.Fatalf("statements %v, want %q", got, tt.want)
+			}
+			if tt.opts != nil && !slices.Equal(rewritten, []string{hinted}) {
+				t.Fatalf("rewriter saw %q, want the hinted query", rewritten)
+			}
+		})
+	}
+}
+
+// hangSelects holds SELECTs until their context ends, then passes them
+// on to fail as canceled
+func hangSelects(ctx context.Context, query string) bool {
+	if strings.HasPrefix(query, "SELECT") {
+		<-ctx.Done()
+	}
+	return false
+}
+
+func TestDefaultTimeout(t *testing.T) {
+	db, _ := openRecorded(t, hangSelects)
+	m := csql.NewSQLTableManager[Item](db, csql.WithDefaultTimeout(20*time.Millisecond))
+	start := time.Now()
+	if _, err := m.Query(selectItems); !errors.Is(err, csql.ErrTimeout) {
+		t.Fatalf("Query = %v, want ErrTimeout", err)
+	} else if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Query = %v, want it to wrap context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Query returned after %v", d)
+	}
+	// an earlier caller deadline is reported as the caller's own
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := m.QueryContext(ctx, selectItems); errors.Is(err, csql.ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Query under an earlier deadline = %v, want the caller's deadline", err)
+	}
+	if err := m.Exec(insertItem, 1, "item1"); err != nil {
+		t.Fatalf("Exec within the timeout = %v", err)
+	}
+}
+
+func TestDefaultTimeoutCallerDeadline(t *testing.T) {
+	db, _ := openRecorded(t, hangSelects)
+	m := csql.NewSQLTableManager[Item](db, csql.WithDefaultTimeout(20*time.Millisecond))
+	// a later caller deadline still gets the default
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	start := time.Now()
+	if _, err := m.QueryRowContext(ctx, selectItems); !errors.Is(err, csql.ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("QueryRow under a later deadline = %v, want the default timeout", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("QueryRow returned after %v, want the default timeout", d)
+	}
+	// a canceled caller is not reported as timed out
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(5*time.Millisecond, cancel)
+	if _, err := m.QueryContext(ctx, selectItems); errors.Is(err, csql.ErrTimeout) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled Query = %v, want context.Canceled", err)
+	}
+}
+
+func TestDefaultTimeoutRowIteration(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 5)
+	fast := csql.NewSQLTableManager[SlowItem](db, csql.WithDefaultTimeout(time.Second))
+	if got, err := fast.Query(selectItems); err != nil || len(got) != 5 {
+		t.Fatalf("Query within the timeout = %d rows, %v", len(got), err)
+	}
+	// fetching is quick, scanning five rows is not
+	slow := csql.NewSQLTableManager[SlowItem](db, csql.WithDefaultTimeout(2*scanDelay))
+	if _, err := slow.Query(selectItems); !errors.Is(err, csql.ErrTimeout) {
+		t.Fatalf("Query = %v, want ErrTimeout while iterating", err)
+	}
+}
+
+func TestDefaultTimeoutTransaction(t *testing.T) {
+	db := openDB(t)
+	m := csql.NewSQLTableManager[Item](db, csql.WithDefaultTimeout(2*scanDelay))
+	slowArgs := func(i *Item) []any {
+		time.Sleep(scanDelay)
+		return []any{i.ID, i.Name}
+	}
+	if _, err := m.TransactionFunc(insertItem, items(1), slowArgs); err != nil {
+		t.Fatalf("Transaction within the timeout = %v", err)
+	}
+	mustExec(t, db, "DELETE FROM items")
+	// each row is within the timeout, the whole batch is not
+	if _, err := m.TransactionFunc(insertItem, items(5), slowArgs); !errors.Is(err, csql.ErrTimeout) {
+		t.Fatalf("Transaction = %v, want ErrTimeout for the batch", err)
+	}
+	if n := countItems(t, m); n != 0 {
+		t.Fatalf("%d rows after the timed out Transaction, want it rolled back", n)
+	}
+}
@@ -0,0 +1,235 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// ErrTxEnded is returned by managers from Bind once their WithTx callback
+// has returned
+var ErrTxEnded = errors.New("csql: transaction group has ended")
+
+// ErrTxBusy is returned by Transaction and its variants on a manager from
+// Bind while another runs within the same Tx
+var ErrTxBusy = errors.New("csql: transaction group is running another transaction")
+
+// Tx is a database transaction shared by the managers Bind returns for it,
+// so writes to several tables through different Schemas commit together.
+// Like *sql.Tx it is not safe for concurrent use: the savepoints of
+// concurrent Transactions would interleave, so a Transaction started while
+// another is open fails with ErrTxBusy
+type Tx struct {
+	db *sql.DB
+	tx *sql.Tx
+
+	mu    sync.Mutex
+	ended bool
+	// savepoints counts the savepoints taken, to name the next one
+	savepoints int
+	// open is set while a savepoint is open
+	open bool
+}
+
+// WithTx runs fn within a database transaction on db, committing it when
+// fn returns nil and rolling it back when fn fails or panics. Managers
+// bound to it with Bind fail with ErrTxEnded after fn returns
+func WithTx(ctx context.Context, db *sql.DB, fn func(tx *Tx) error) (err error) {
+	sqlTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("csql: WithTx failed: %w", err)
+	}
+	t := &Tx{db: db, tx: sqlTx}
+	defer func() {
+		t.mu.Lock()
+		t.ended = true
+		t.mu.Unlock()
+		if p := recover(); p != nil {
+			sqlTx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			err = rollback(sqlTx, err)
+			return
+		}
+		if err = sqlTx.Commit(); err != nil {
+			err = fmt.Errorf("csql: WithTx failed: %w", err)
+		}
+	}()
+	return fn(t)
+}
+
+// Bind returns a manager configured by opts running every statement
+// within t. Transaction and its variants run within a savepoint, so a
+// failing one is undone without ending t. Reads bypass the WithQueryCache
+// cache and WithSingleflight, as they may see uncommitted writes. It
+// panics if any option is invalid or the Schema is, as NewSQLTableManager does
+func Bind[T any, R Schema[T]](t *Tx, opts ...Option) *SQLTableManager[T, R] {
+	o, err := newOptions(opts)
+	if err == nil {
+		err = checkSchema[T, R]()
+	}
+	if err != nil {
+		panic(err)
+	}
+	o.redactColumns = redactedColumns[T, R]()
+	o.closeDB = false
+	return &SQLTableManager[T, R]{
+		db:     txConn{t},
+		pool:   t.db,
+		opts:   o,
+		pinned: true,
+		group:  t,
+	}
+}
+
+// beginTx starts the database transaction of a write, or a savepoint for
+// a manager from Bind
+func (m *SQLTableManager[_, _]) beginTx(ctx context.Context) (txn, error) {
+	if m.group != nil {
+		return m.group.savepoint(ctx)
+	}
+	c, err := m.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var t txn
+	if c == nil {
+		tx, err := m.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		t = tx
+	} else {
+		tx, err := c.BeginTx(ctx, nil)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		t = &connTx{Tx: tx, conn: c}
+	}
+	if err := m.opts.setStatementTimeout(ctx, t); err != nil {
+		return nil, rollback(t, err)
+	}
+	return t, nil
+}
+
+// check returns ErrTxEnded once WithTx has returned
+func (t *Tx) check() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.ended {
+		return ErrTxEnded
+	}
+	return nil
+}
+
+// savepoint starts a savepoint within t, standing in for a transaction
+func (t *Tx) savepoint(ctx context.Context) (txn, error) {
+	t.mu.Lock()
+	if t.ended {
+		t.mu.Unlock()
+		return nil, ErrTxEnded
+	}
+	if t.open {
+		t.mu.Unlock()
+		return nil, ErrTxBusy
+	}
+	t.open = true
+	t.savepoints++
+	name := "csql_" + strconv.Itoa(t.savepoints)
+	t.mu.Unlock()
+	if _, err := t.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		t.close()
+		return nil, err
+	}
+	return &savepoint{Tx: t.tx, name: name, group: t}, nil
+}
+
+// close marks the open savepoint of t ended
+func (t *Tx) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.open = false
+}
+
+// txn is the part of *sql.Tx a write runs through
+type txn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	StmtContext(ctx context.Context, stmt *sql.Stmt) *sql.Stmt
+	Commit() error
+	Rollback() error
+}
+
+// savepoint is a txn within a Tx, committed by releasing it
+type savepoint struct {
+	*sql.Tx
+	name  string
+	group *Tx
+	// ended is set once committed or rolled back
+	ended bool
+}
+
+func (s *savepoint) Commit() error {
+	if s.ended {
+		return sql.ErrTxDone
+	}
+	s.ended = true
+	defer s.group.close()
+	_, err := s.Exec("RELEASE SAVEPOINT " + s.name)
+	return err
+}
+
+func (s *savepoint) Rollback() error {
+	if s.ended {
+		return sql.ErrTxDone
+	}
+	s.ended = true
+	defer s.group.close()
+	_, err := s.Exec("ROLLBACK TO SAVEPOINT " + s.name)
+	return err
+}
+
+// txConn runs a bound manager's statements within its Tx
+type txConn struct {
+	t *Tx
+}
+
+func (c txConn) BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error) {
+	return nil, errors.New("csql: bound managers cannot begin transactions")
+}
+
+func (c txConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if err := c.t.check(); err != nil {
+		return nil, err
+	}
+	return c.t.tx.ExecContext(ctx, query, args...)
+}
+
+func (c txConn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if err := c.t.check(); err != nil {
+		return nil, err
+	}
+	return c.t.tx.QueryContext(ctx, query, args...)
+}
+
+func (c txConn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	// A *sql.Row cannot carry ErrTxEnded, so it reports sql.ErrTxDone instead
+	return c.t.tx.QueryRowContext(ctx, query, args...)
+}
+
+func (c txConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if err := c.t.check(); err != nil {
+		return nil, err
+	}
+	return c.t.tx.PrepareContext(ctx, query)
+}
+
+func (c txConn) PingContext(context.Context) error {
+	return c.t.check()
+}
@@ -0,0 +1,84 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vtereso/csql"
+)
+
+func TestBoundAfterTxEnded(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 1)
+	var m *csql.SQLTableManager[Item, *Item]
+	if err := csql.WithTx(context.Background(), db, func(tx *csql.Tx) error {
+		m = csql.Bind[Item](tx, csql.WithTable("items"))
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.QueryRow("SELECT id, name FROM items"); !errors.Is(err, csql.ErrTxEnded) {
+		t.Fatalf("QueryRow = %v, want ErrTxEnded", err)
+	}
+	if _, err := m.Count(context.Background(), ""); !errors.Is(err, csql.ErrTxEnded) {
+		t.Fatalf("Count = %v, want ErrTxEnded", err)
+	}
+}
+
+func TestBoundTransactionBusy(t *testing.T) {
+	db := openDB(t)
+	err := csql.WithTx(context.Background(), db, func(tx *csql.Tx) error {
+		m := csql.Bind[Item](tx)
+		var inner error
+		m.TransactionIter(context.Background(), insertItem+" RETURNING id, name", items(1))(func(Item, error) bool {
+			_, inner = m.Transaction(insertItem, []Item{{ID: 9, Name: "item9"}})
+			return true
+		})
+		if !errors.Is(inner, csql.ErrTxBusy) {
+			t.Errorf("Transaction within a running one = %v, want ErrTxBusy", inner)
+		}
+		_, err := m.Transaction(insertItem, []Item{{ID: 9, Name: "item9"}})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countItems(t, csql.NewSQLTableManager[Item](db, csql.WithTable("items"))); n != 2 {
+		t.Fatalf("stored %d rows, want both transactions' rows", n)
+	}
+}
+
+// TestBoundSkipsExplain runs a slow query on a manager bound to the only
+// connection of the pool, which an EXPLAIN on the pool would wait for until
+// it timed out
+func TestBoundSkipsExplain(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 1)
+	var slow []csql.SlowQuery
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- csql.WithTx(context.Background(), db, func(tx *csql.Tx) error {
+			m := csql.Bind[Item](tx, csql.WithDialect(csql.SQLite), csql.WithSlowQueryExplain(),
+				csql.WithSlowQueryThreshold(time.Nanosecond, func(q csql.SlowQuery) { slow = append(slow, q) }))
+			_, err := m.Query(selectItems)
+			return err
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow query deadlocked on its EXPLAIN")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("WithTx took %v, waiting on an EXPLAIN", elapsed)
+	}
+	if len(slow) != 1 || slow[0].Plan != nil {
+		t.Fatalf("slow queries = %+v, want one without a plan", slow)
+	}
+}
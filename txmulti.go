@@ -0,0 +1,119 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TxOp is one statement of TransactionMulti, executed once per row with the
+// row's Fields and then once per entry of Args
+type TxOp[T any] struct {
+	Statement string
+	Rows      []T
+	Args      [][]any
+}
+
+// TxOpError locates the failure of a TransactionMulti. Row indexes Rows
+// followed by Args, and is -1 when the statement failed to prepare
+type TxOpError struct {
+	Op  int
+	Row int
+	Err error
+}
+
+func (e *TxOpError) Error() string {
+	if e.Row < 0 {
+		return fmt.Sprintf("csql: op %d: prepare: %v", e.Op, e.Err)
+	}
+	return fmt.Sprintf("csql: op %d row %d: %v", e.Op, e.Row, e.Err)
+}
+
+func (e *TxOpError) Unwrap() error { return e.Err }
+
+// TransactionMulti executes every op within a single database transaction,
+// rolling back on the first failure, which is returned as a *TxOpError
+func (m *SQLTableManager[T, R]) TransactionMulti(ops []TxOp[T]) error {
+	return m.TransactionMultiContext(context.Background(), ops)
+}
+
+// TransactionMultiContext is TransactionMulti bound to ctx
+func (m *SQLTableManager[T, R]) TransactionMultiContext(ctx context.Context, ops []TxOp[T]) (err error) {
+	statements := make([]string, len(ops))
+	for i, op := range ops {
+		statements[i] = m.opts.finalize(ctx, op.Statement, nil)
+	}
+	summary := strings.Join(statements, "; ")
+	defer m.opts.annotate(&err, "TransactionMulti", summary)
+	var affected int64
+	defer func() { m.opts.audit(ctx, "TransactionMulti", affected, err) }()
+	if err := m.opts.writable(); err != nil {
+		return err
+	}
+	ctx, done := m.opts.deadline(ctx)
+	defer done(&err)
+	argsFn := convertingArgs[T, R](&m.opts, nil)
+	if m.opts.dryRun != nil {
+		for i, op := range ops {
+			for _, row := range op.Rows {
+				m.opts.dryRun(statements[i], bindArgs[T, R](argsFn, &row))
+			}
+			for _, args := range op.Args {
+				m.opts.dryRun(statements[i], append([]any(nil), m.opts.convertArgs(args)...))
+			}
+		}
+		return nil
+	}
+	rows := 0
+	for _, op := range ops {
+		rows += len(op.Rows) + len(op.Args)
+	}
+	var after func(*error)
+	if ctx, after, err = m.opts.beforeExec(ctx, "TransactionMulti", summary, nil, rows); err != nil {
+		return err
+	}
+	defer after(&err)
+	defer m.opts.invalidate()
+	var execed int
+	if m.opts.observed() {
+		var start time.Time
+		ctx, start = m.opts.begin(ctx, OpTransaction, summary, nil)
+		defer func() {
+			m.opts.observe(ctx, OpTransaction, summary, nil, start, int64(execed), err)
+		}()
+	}
+	tx, err := m.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	for i, op := range ops {
+		stmt, err := tx.PrepareContext(ctx, statements[i])
+		if err != nil {
+			return rollback(tx, &TxOpError{Op: i, Row: -1, Err: err})
+		}
+		var n int
+		opAffected, err := execRows[T, R](ctx, stmt, op.Rows, argsFn, &n, nil, nil)
+		affected = addAffected(affected, opAffected)
+		for j := 0; err == nil && j < len(op.Args); j++ {
+			if err = ctx.Err(); err == nil {
+				var res sql.Result
+				if res, err = stmt.ExecContext(ctx, m.opts.convertArgs(op.Args[j])...); err == nil {
+					n++
+					affected = addAffected(affected, rowsAffected(res, nil))
+				}
+			}
+		}
+		stmt.Close()
+		execed += n
+		if err != nil {
+			affected = 0
+			return rollback(tx, &TxOpError{Op: i, Row: n, Err: err})
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		affected = 0
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package csql_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestTransactionMulti(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 3)
+	m := csql.NewSQLTableManager[Item](db)
+	err := m.TransactionMulti([]csql.TxOp[Item]{
+		{Statement: "DELETE FROM items WHERE id = ?", Args: [][]any{{1}, {2}}},
+		{Statement: insertItem, Rows: []Item{{4, "item4"}, {5, "item5"}}},
+		{Statement: "UPDATE items SET name = ? WHERE id = ?", Args: [][]any{{"watermark", 3}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := m.Query(selectItems); err != nil || fmt.Sprint(got) != "[{3 watermark} {4 item4} {5 item5}]" {
+		t.Fatalf("Query = %v, %v", got, err)
+	}
+}
+
+func TestTransactionMultiRollsBack(t *testing.T) {
+	// sqlite only reports bad SQL once executed, so preparing is failed here
+	db, rec := openRecorded(t, nil)
+	rec.fail = failFirst("PREPARE INSERT INTO missing", 1, errors.New("no such table: missing"))
+	seedItems(t, db, 2)
+	m := csql.NewSQLTableManager[Item](db)
+	tests := []struct {
+		name   string
+		ops    []csql.TxOp[Item]
+		op     int
+		row    int
+		prefix string
+	}{
+		{"row", []csql.TxOp[Item]{
+			{Statement: "DELETE FROM items WHERE id = ?", Args: [][]any{{1}}},
+			{Statement: insertItem, Rows: []Item{{3, "item3"}}, Args: [][]any{{2, "dup"}}},
+		}, 1, 1, "csql: op 1 row 1: "},
+		{"prepare", []csql.TxOp[Item]{
+			{Statement: "DELETE FROM items WHERE id = ?", Args: [][]any{{1}}},
+			{Statement: "INSERT INTO missing VALUES (?)", Args: [][]any{{1}}},
+		}, 1, -1, "csql: op 1: prepare: "},
+	}
+	for _, tt := range tests {
+		err := m.TransactionMulti(tt.ops)
+		var opErr *csql.TxOpError
+		if !errors.As(err, &opErr) || opErr.Op != tt.op || opErr.Row != tt.row {
+			t.Fatalf("%s: TransactionMulti = %v, want op %d row %d", tt.name, err, tt.op, tt.row)
+		}
+		if msg := opErr.Error(); len(msg) < len(tt.prefix) || msg[:len(tt.prefix)] != tt.prefix {
+			t.Fatalf("%s: error %q, want it to start %q", tt.name, msg, tt.prefix)
+		}
+		if n := countItems(t, m); n != 2 {
+			t.Fatalf("%s: %d rows, want the transaction rolled back", tt.name, n)
+		}
+	}
+}
+
+func TestTransactionMultiDryRun(t *testing.T) {
+	var c capture
+	m := csql.NewSQLTableManager[Badge](openDB(t), csql.WithDryRun(c.record), lowerCodes)
+	err := m.TransactionMulti([]csql.TxOp[Badge]{
+		{Statement: "INSERT INTO badges VALUES (?, ?)", Rows: []Badge{{1, "AB"}}, Args: [][]any{{2, Code("CD")}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(c.lines); got != "[INSERT INTO badges VALUES (?, ?) [1 ab] INSERT INTO badges VALUES (?, ?) [2 cd]]" {
+		t.Fatalf("captured %s, want the args through the codec", got)
+	}
+}
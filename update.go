@@ -0,0 +1,171 @@
+package csql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrNoKey is returned by keyed helpers when the Schema does not implement Keyed
+	ErrNoKey = errors.New("csql: schema has no key column")
+	// ErrStaleRow is returned when a Versioned row was changed since it was read
+	ErrStaleRow = errors.New("csql: stale row")
+	// ErrStaleVersion is ErrStaleRow, under the name UpdateOptimistic's
+	// version column suggests
+	ErrStaleVersion = ErrStaleRow
+)
+
+// Keyed is implemented by Schemas whose table rows are identified by a key
+// column, which must be one of the Schema's columns
+type Keyed interface {
+	KeyColumn() string
+}
+
+// CompositeKeyed is implemented by Schemas whose table rows are identified
+// by several key columns together, each one of the Schema's columns. Update
+// prefers it to Keyed
+type CompositeKeyed interface {
+	KeyColumns() []string
+}
+
+// Versioned is implemented by Keyed Schemas guarded by optimistic locking.
+// The version column is incremented by every Update, which only applies
+// while it still holds the row's version
+type Versioned interface {
+	VersionColumn() string
+}
+
+// Update writes every column of row to the table row sharing its key.
+// Soft-deleted rows are skipped unless the manager came from WithTrashed.
+// For a Versioned Schema it returns ErrStaleRow when the row changed since
+// it was read, and ErrNotFound when it no longer exists; the caller's row
+// keeps its old version either way. Otherwise a missing row is only
+// reported, as ErrNotFound, under WithStrictAffected
+func (m *SQLTableManager[T, R]) Update(ctx context.Context, row T) error {
+	if m.opts.table == "" {
+		return ErrNoTable
+	}
+	schema := any(R(&row))
+	var keyColumns []string
+	switch keyed := schema.(type) {
+	case CompositeKeyed:
+		keyColumns = keyed.KeyColumns()
+	case Keyed:
+		keyColumns = []string{keyed.KeyColumn()}
+	}
+	if len(keyColumns) == 0 {
+		return ErrNoKey
+	}
+	versionColumn := ""
+	if v, ok := schema.(Versioned); ok {
+		versionColumn = v.VersionColumn()
+	}
+	return m.update(ctx, row, keyColumns, versionColumn)
+}
+
+// UpdateOptimistic is Update for a row keyed by idColumn and guarded by
+// versionColumn, for Schemas that implement neither Keyed nor Versioned.
+// It returns ErrStaleVersion, which is ErrStaleRow, when versionColumn no
+// longer holds the row's version
+func (m *SQLTableManager[T, R]) UpdateOptimistic(ctx context.Context, row T, idColumn, versionColumn string) error {
+	return m.update(ctx, row, []string{idColumn}, versionColumn)
+}
+
+// update runs Update with the key columns keyColumns and, unless empty, the
+// version column versionColumn
+func (m *SQLTableManager[T, R]) update(ctx context.Context, row T, keyColumns []string, versionColumn string) error {
+	if m.opts.table == "" {
+		return ErrNoTable
+	}
+	cols := m.columns()
+	fields := R(&row).Fields()
+	if len(cols) != len(fields) {
+		return fmt.Errorf("csql: schema names %d columns but Fields returns %d", len(cols), len(fields))
+	}
+	fields = m.opts.sealFields(cols, fields)
+	keys := make([]int, len(keyColumns))
+	for i, c := range keyColumns {
+		if keys[i] = indexFold(cols, c); keys[i] < 0 {
+			return fmt.Errorf("%w %q", ErrUnknownColumn, c)
+		}
+	}
+	version := -1
+	if versionColumn != "" {
+		if version = indexFold(cols, versionColumn); version < 0 {
+			return fmt.Errorf("%w %q", ErrUnknownColumn, versionColumn)
+		}
+	}
+	sets := make([]string, 0, len(cols))
+	args := make([]any, 0, len(cols)+1)
+	// argCols names the column each of args binds to
+	argCols := make([]string, 0, len(cols)+1)
+	for i, c := range cols {
+		switch {
+		case slices.Contains(keys, i):
+		case i == version:
+			sets = append(sets, c+" = "+c+" + 1")
+		default:
+			sets = append(sets, c+" = ?")
+			args, argCols = append(args, fields[i]), append(argCols, c)
+		}
+	}
+	keyNames := make([]string, len(keys))
+	keyArgs := make([]any, len(keys))
+	for i, k := range keys {
+		keyNames[i], keyArgs[i] = cols[k], fields[k]
+	}
+	where := keyClause(keyNames)
+	args, argCols = append(args, keyArgs...), append(argCols, keyNames...)
+	if version >= 0 {
+		where += " AND " + cols[version] + " = ?"
+		args, argCols = append(args, fields[version]), append(argCols, cols[version])
+	}
+	scope := m.scope(where, !m.withTrashed)
+	ctx = withArgColumns(ctx, argCols)
+	res, err := m.execAudited(ctx, "Update", "UPDATE "+m.opts.table+" SET "+strings.Join(sets, ", ")+scope, args)
+	if err != nil || res == nil {
+		return err
+	}
+	if version < 0 {
+		return m.opts.checkAffected(res)
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	var found int
+	err = m.queryScalar(ctx, "SELECT 1 FROM "+m.opts.table+m.scope(keyClause(keyNames), !m.withTrashed), keyArgs, &found)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case err != nil:
+		return err
+	}
+	return ErrStaleRow
+}
+
+// WithStrictAffected makes Update, Delete, and ForceDelete return
+// ErrNotFound when they affect no row, rather than succeeding silently.
+// Drivers that cannot count affected rows are never reported. MySQL counts
+// an Update leaving the row unchanged as unaffected unless the connection
+// sets clientFoundRows
+func WithStrictAffected(strict bool) Option {
+	return func(o *options) error {
+		o.strictAffected = strict
+		return nil
+	}
+}
+
+// checkAffected reports ErrNotFound under WithStrictAffected when res affected no row
+func (o *options) checkAffected(res sql.Result) error {
+	if !o.strictAffected {
+		return nil
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// Doc is a Schema with a version column
+type Doc struct {
+	ID      int64
+	Body    string
+	Version int64
+}
+
+func (d *Doc) ScanRow(s csql.RowScanner) error { return s.Scan(&d.ID, &d.Body, &d.Version) }
+
+func (d *Doc) Fields() []any { return []any{d.ID, d.Body, d.Version} }
+
+func TestUpdateOptimistic(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE doc (id INTEGER PRIMARY KEY, body TEXT, version INTEGER)")
+	mustExec(t, db, "INSERT INTO doc VALUES (1, 'draft', 1)")
+	m := csql.NewSQLTableManager[Doc](db, csql.WithTable("doc"))
+	err := m.UpdateOptimistic(ctx, Doc{ID: 1, Body: "stale", Version: 0}, "id", "version")
+	if !errors.Is(err, csql.ErrStaleVersion) || !errors.Is(err, csql.ErrStaleRow) {
+		t.Fatalf("stale UpdateOptimistic = %v, want ErrStaleVersion", err)
+	}
+	if err := m.UpdateOptimistic(ctx, Doc{ID: 1, Body: "final", Version: 1}, "id", "version"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.QueryRow("SELECT id, body, version FROM doc")
+	if err != nil || got != (Doc{ID: 1, Body: "final", Version: 2}) {
+		t.Fatalf("QueryRow = %+v, %v, want the update with its version bumped", got, err)
+	}
+}
+
+// VersionedDoc is a Doc keyed by id and guarded by its version
+type VersionedDoc struct{ Doc }
+
+func (*VersionedDoc) KeyColumn() string { return "id" }
+
+func (*VersionedDoc) VersionColumn() string { return "version" }
+
+func TestUpdateVersioned(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE doc (id INTEGER PRIMARY KEY, body TEXT, version INTEGER)")
+	mustExec(t, db, "INSERT INTO doc VALUES (1, 'draft', 1)")
+	m := csql.NewSQLTableManager[VersionedDoc](db, csql.WithTable("doc"))
+	read, err := m.QueryRow("SELECT id, body, version FROM doc WHERE id = ?", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// another writer updates the row after it was read
+	mustExec(t, db, "UPDATE doc SET body = 'theirs', version = version + 1 WHERE id = 1")
+	read.Body = "mine"
+	if err := m.Update(ctx, read); !errors.Is(err, csql.ErrStaleRow) {
+		t.Fatalf("Update after a concurrent update = %v, want ErrStaleRow", err)
+	}
+	if got, err := m.QueryRow("SELECT id, body, version FROM doc"); err != nil || got.Doc != (Doc{ID: 1, Body: "theirs", Version: 2}) {
+		t.Fatalf("QueryRow = %+v, %v, want the concurrent update kept", got, err)
+	}
+	if err := m.Update(ctx, VersionedDoc{Doc{ID: 1, Body: "mine", Version: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := m.QueryRow("SELECT id, body, version FROM doc"); err != nil || got.Doc != (Doc{ID: 1, Body: "mine", Version: 3}) {
+		t.Fatalf("QueryRow = %+v, %v, want the update with its version bumped", got, err)
+	}
+	if err := m.Update(ctx, VersionedDoc{Doc{ID: 9, Version: 1}}); !errors.Is(err, csql.ErrNotFound) {
+		t.Fatalf("Update of a missing row = %v, want ErrNotFound", err)
+	}
+	unkeyed := csql.NewSQLTableManager[Doc](db, csql.WithTable("doc"))
+	if err := unkeyed.Update(ctx, Doc{ID: 1}); !errors.Is(err, csql.ErrNoKey) {
+		t.Fatalf("Update without a key = %v, want ErrNoKey", err)
+	}
+}
+
+func TestStrictAffected(t *testing.T) {
+	ctx := context.Background()
+	for _, strict := range []bool{false, true} {
+		db := openPeople(t)
+		mustExec(t, db, "INSERT INTO people VALUES (1, 'a')")
+		m := csql.NewSQLTableManager[Person](db, csql.WithTable("people"), csql.WithStrictAffected(strict))
+		var want error
+		if strict {
+			want = csql.ErrNotFound
+		}
+		if err := m.Update(ctx, Person{ID: 9, SSN: "b"}); !errors.Is(err, want) {
+			t.Errorf("strict %t: Update of a missing row = %v, want %v", strict, err, want)
+		}
+		if err := m.Delete("id = ?", 9); !errors.Is(err, want) {
+			t.Errorf("strict %t: Delete of a missing row = %v, want %v", strict, err, want)
+		}
+		if err := m.Update(ctx, Person{ID: 1, SSN: "b"}); err != nil {
+			t.Errorf("strict %t: Update = %v", strict, err)
+		}
+		if err := m.Delete("id = ?", 1); err != nil {
+			t.Errorf("strict %t: Delete = %v", strict, err)
+		}
+	}
+}
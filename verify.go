@@ -0,0 +1,108 @@
+package csql
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// VerifySchema checks that the Schema's ScanRow and Fields list columns in
+// the same order, by marking each column ScanRow scans into and finding
+// where it shows up in Fields. Columns of Scanner or struct types cannot be
+// marked and are skipped. It suits a test or an init check
+func VerifySchema[T any, R Schema[T]]() error {
+	name := reflect.TypeOf((*T)(nil)).Elem().String()
+	zero := new(T)
+	dest, err := scanDest[T, R](zero)
+	if err != nil {
+		return fmt.Errorf("csql: %s: ScanRow: %w", name, err)
+	}
+	base := R(zero).Fields()
+	if len(dest) != len(base) {
+		return fmt.Errorf("csql: %s: ScanRow scans %d columns but Fields returns %d", name, len(dest), len(base))
+	}
+	for i := range dest {
+		box := new(T)
+		dest, _ := scanDest[T, R](box)
+		if !mark(dest[i]) {
+			continue
+		}
+		found := -1
+		for j, f := range R(box).Fields() {
+			if !reflect.DeepEqual(f, base[j]) {
+				found = j
+				break
+			}
+		}
+		switch {
+		case found < 0:
+			return fmt.Errorf("csql: %s: ScanRow column %d is missing from Fields", name, i)
+		case found != i:
+			return fmt.Errorf("csql: %s: ScanRow column %d is Fields position %d", name, i, found)
+		}
+	}
+	return nil
+}
+
+// scanDest records the destinations ScanRow scans box into
+func scanDest[T any, R Schema[T]](box *T) ([]any, error) {
+	var rec recordingScanner
+	err := R(box).ScanRow(&rec)
+	return rec.dest, err
+}
+
+// recordingScanner is a RowScanner that keeps its destinations unset
+type recordingScanner struct {
+	dest []any
+}
+
+func (r *recordingScanner) Scan(dest ...any) error {
+	r.dest = dest
+	return nil
+}
+
+// mark stores a non-zero value in the pointer dest, reporting false when
+// dest is of a type that cannot be marked
+func mark(dest any) bool {
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Pointer || dv.IsNil() || dv.Type().Implements(scannerType) {
+		return false
+	}
+	v, ok := nonZero(dv.Type().Elem())
+	if ok {
+		dv.Elem().Set(v)
+	}
+	return ok
+}
+
+// nonZero returns a non-zero value of t for the basic column types
+func nonZero(t reflect.Type) (reflect.Value, bool) {
+	v := reflect.New(t).Elem()
+	switch k := t.Kind(); {
+	case t == timeType:
+		v.Set(reflect.ValueOf(time.Unix(1, 0)))
+	case k == reflect.Bool:
+		v.SetBool(true)
+	case k >= reflect.Int && k <= reflect.Int64:
+		v.SetInt(1)
+	case k >= reflect.Uint && k <= reflect.Uintptr:
+		v.SetUint(1)
+	case k == reflect.Float32 || k == reflect.Float64:
+		v.SetFloat(1)
+	case k == reflect.String:
+		v.SetString("x")
+	case k == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		v.SetBytes([]byte{1})
+	case k == reflect.Pointer && !t.Implements(scannerType):
+		elem, ok := nonZero(t.Elem())
+		if !ok {
+			return v, false
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(elem)
+		v.Set(p)
+	default:
+		return v, false
+	}
+	return v, true
+}
@@ -0,0 +1,43 @@
+package csql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+// Swapped scans name before id but lists id first
+type Swapped struct {
+	ID   int64
+	Name string
+}
+
+func (s *Swapped) ScanRow(r csql.RowScanner) error { return r.Scan(&s.Name, &s.ID) }
+
+func (s *Swapped) Fields() []any { return []any{s.ID, s.Name} }
+
+// Short leaves name out of Fields
+type Short struct {
+	ID   int64
+	Name string
+}
+
+func (s *Short) ScanRow(r csql.RowScanner) error { return r.Scan(&s.ID, &s.Name) }
+
+func (s *Short) Fields() []any { return []any{s.ID} }
+
+func TestVerifySchema(t *testing.T) {
+	if err := csql.VerifySchema[Item](); err != nil {
+		t.Errorf("VerifySchema[Item] = %v, want nil", err)
+	}
+	if err := csql.VerifySchema[NamedItem](); err != nil {
+		t.Errorf("VerifySchema[NamedItem] = %v, want nil", err)
+	}
+	if err := csql.VerifySchema[Swapped](); err == nil || !strings.Contains(err.Error(), "ScanRow column 0 is Fields position 1") {
+		t.Errorf("VerifySchema[Swapped] = %v, want the swap reported", err)
+	}
+	if err := csql.VerifySchema[Short](); err == nil || !strings.Contains(err.Error(), "scans 2 columns but Fields returns 1") {
+		t.Errorf("VerifySchema[Short] = %v, want the count mismatch reported", err)
+	}
+}
@@ -0,0 +1,117 @@
+package csql
+
+import (
+	"context"
+	"strings"
+)
+
+// Where builds a WHERE clause from conditions, keeping values out of the SQL.
+// Conditions are ANDed together by default; the zero Where matches every row
+type Where struct {
+	clause string
+	args   []any
+	// columns collects the referenced columns so managers can check them
+	columns []string
+	// n counts the conditions, to know when a nested Where needs parentheses
+	n int
+	// or is set while the clause ends in a top level OR
+	or bool
+}
+
+// Eq adds the condition column = value
+func (w *Where) Eq(column string, value any) *Where {
+	return w.cond(column, column+" = ?", value)
+}
+
+// Gt adds the condition column > value
+func (w *Where) Gt(column string, value any) *Where {
+	return w.cond(column, column+" > ?", value)
+}
+
+// Lt adds the condition column < value
+func (w *Where) Lt(column string, value any) *Where {
+	return w.cond(column, column+" < ?", value)
+}
+
+// In adds the condition column IN (values...). No values matches no rows
+func (w *Where) In(column string, values ...any) *Where {
+	if len(values) == 0 {
+		return w.cond(column, "1 = 0")
+	}
+	return w.cond(column, column+" IN (?"+strings.Repeat(", ?", len(values)-1)+")", values...)
+}
+
+// And adds every condition of other, grouped, with AND
+func (w *Where) And(other *Where) *Where {
+	return w.join(false, other)
+}
+
+// Or adds every condition of other, grouped, with OR
+func (w *Where) Or(other *Where) *Where {
+	return w.join(true, other)
+}
+
+// Build returns the clause, without the WHERE keyword, and its arguments,
+// with placeholders in the form of d
+func (w *Where) Build(d Dialect) (string, []any) {
+	if w == nil {
+		return "", nil
+	}
+	return d.rebind(w.clause), w.args
+}
+
+func (w *Where) cond(column, clause string, args ...any) *Where {
+	w.add(false, clause, 1, args)
+	w.columns = append(w.columns, column)
+	return w
+}
+
+func (w *Where) join(or bool, other *Where) *Where {
+	if other == nil || other.n == 0 {
+		return w
+	}
+	clause := other.clause
+	if other.n > 1 {
+		clause = "(" + clause + ")"
+	}
+	w.add(or, clause, other.n, other.args)
+	w.columns = append(w.columns, other.columns...)
+	return w
+}
+
+func (w *Where) add(or bool, clause string, n int, args []any) {
+	switch {
+	case w.n == 0:
+		w.clause = clause
+		or = false
+	case or:
+		w.clause += " OR " + clause
+	default:
+		if w.or {
+			w.clause = "(" + w.clause + ")"
+		}
+		w.clause += " AND " + clause
+	}
+	w.or = or
+	w.n += n
+	w.args = append(w.args, args...)
+}
+
+// QueryWhere returns the table rows matching w, or every row when w is nil.
+// Soft-deleted rows are skipped unless the manager came from WithTrashed
+func (m *SQLTableManager[T, R]) QueryWhere(ctx context.Context, w *Where) ([]T, error) {
+	if m.opts.table == "" {
+		return nil, ErrNoTable
+	}
+	var clause string
+	var args []any
+	if w != nil {
+		for _, c := range w.columns {
+			if err := m.checkColumn(c); err != nil {
+				return nil, err
+			}
+		}
+		clause, args = w.clause, w.args
+	}
+	return m.QueryContext(ctx, "SELECT * FROM "+m.opts.table+m.scope(clause, !m.withTrashed), args...)
+}
@@ -0,0 +1,54 @@
+package csql_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/vtereso/csql"
+)
+
+func TestWhereBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		where   func() *csql.Where
+		dialect csql.Dialect
+		clause  string
+		args    string
+	}{
+		{"and in", func() *csql.Where { return new(csql.Where).Eq("a", 1).In("b", 2, 3) }, csql.Generic, "a = ? AND b IN (?, ?)", "[1 2 3]"},
+		{"postgres", func() *csql.Where { return new(csql.Where).Eq("a", 1).In("b", 2, 3) }, csql.Postgres, "a = $1 AND b IN ($2, $3)", "[1 2 3]"},
+		{"or grouped", func() *csql.Where {
+			return new(csql.Where).Gt("a", 1).And(new(csql.Where).Lt("b", 2).Or(new(csql.Where).Eq("c", 3)))
+		}, csql.Generic, "a > ? AND (b < ? OR c = ?)", "[1 2 3]"},
+		{"and after or", func() *csql.Where {
+			return new(csql.Where).Eq("a", 1).Or(new(csql.Where).Eq("b", 2)).Eq("c", 3)
+		}, csql.Generic, "(a = ? OR b = ?) AND c = ?", "[1 2 3]"},
+		{"empty in", func() *csql.Where { return new(csql.Where).In("a") }, csql.Generic, "1 = 0", "[]"},
+		{"nil", func() *csql.Where { return nil }, csql.Generic, "", "[]"},
+	}
+	for _, tt := range tests {
+		clause, args := tt.where().Build(tt.dialect)
+		if clause != tt.clause || fmt.Sprint(args) != tt.args {
+			t.Errorf("%s: Build = %q, %v, want %q, %s", tt.name, clause, args, tt.clause, tt.args)
+		}
+	}
+}
+
+func TestQueryWhere(t *testing.T) {
+	db := openDB(t)
+	seedItems(t, db, 5)
+	m := csql.NewSQLTableManager[NamedItem](db, csql.WithTable("items"))
+	ctx := context.Background()
+	got, err := m.QueryWhere(ctx, new(csql.Where).Gt("id", 1).In("name", "item2", "item4", "item9"))
+	if err != nil || fmt.Sprint(got) != "[{2 item2} {4 item4}]" {
+		t.Fatalf("QueryWhere = %v, %v", got, err)
+	}
+	if got, err := m.QueryWhere(ctx, nil); err != nil || len(got) != 5 {
+		t.Fatalf("QueryWhere(nil) = %d rows, %v, want all", len(got), err)
+	}
+	if _, err := m.QueryWhere(ctx, new(csql.Where).Eq("id = 1 OR 1", 1)); !errors.Is(err, csql.ErrUnknownColumn) {
+		t.Fatalf("QueryWhere on an unknown column = %v, want ErrUnknownColumn", err)
+	}
+}